@@ -8,7 +8,9 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
 	"github.com/Kbgjtn/notethingness-api.git/db"
+	"github.com/Kbgjtn/notethingness-api.git/storage"
 	"github.com/Kbgjtn/notethingness-api.git/types"
 	"github.com/Kbgjtn/notethingness-api.git/util"
 )
@@ -16,11 +18,32 @@ import (
 type Server struct {
 	router http.Handler
 	db     *sql.DB
+	blobs  storage.BlobStore
 	config types.Env
+	// retention is how long deleted tasks stay in the trash
+	retention time.Duration
+	// batchLimit is how many operations a task batch may hold
+	batchLimit int
+	// requireIfMatch makes task writes fail without an If-Match header
+	requireIfMatch bool
+	// idempotencyTTL is how long the response to an Idempotency-Key is kept
+	idempotencyTTL time.Duration
+	// accessTTL and refreshTTL are how long access and refresh tokens are valid
+	accessTTL  time.Duration
+	refreshTTL time.Duration
 }
 
 func NewServer() *Server {
 	config := util.GetEnv()
+	if err := util.SetCursorKey([]byte(config.CursorSecret)); err != nil {
+		panic(err)
+	}
+	if err := util.SetTokenKey([]byte(config.JWTSecret)); err != nil {
+		panic(err)
+	}
+	if config.JWTSecret == "" {
+		slog.Warn("JWT_SECRET is not set: access tokens are signed with a random key and stop working on restart")
+	}
 
 	db := db.NewDatabase()
 	slog.Info("[ ☘️ Connect to DB POSTGRES ]")
@@ -30,9 +53,51 @@ func NewServer() *Server {
 		panic(err)
 	}
 
+	blobs, err := storage.NewBlobStore(config)
+	if err != nil {
+		panic(err)
+	}
+
+	retention, err := model.ParseTrashRetention(config.TrashRetention)
+	if err != nil {
+		panic(err)
+	}
+
+	batchLimit, err := model.ParseBatchMaxSize(config.BatchMaxSize)
+	if err != nil {
+		panic(err)
+	}
+
+	requireIfMatch, err := model.ParseIfMatchPolicy(config.IfMatch)
+	if err != nil {
+		panic(err)
+	}
+
+	idempotencyTTL, err := model.ParseIdempotencyTTL(config.IdempotencyTTL)
+	if err != nil {
+		panic(err)
+	}
+
+	accessTTL, err := model.ParseAccessTokenTTL(config.AccessTokenTTL)
+	if err != nil {
+		panic(err)
+	}
+
+	refreshTTL, err := model.ParseRefreshTokenTTL(config.RefreshTokenTTL)
+	if err != nil {
+		panic(err)
+	}
+
 	server := &Server{
-		db:     store,
-		config: config,
+		db:             store,
+		blobs:          blobs,
+		config:         config,
+		retention:      retention,
+		batchLimit:     batchLimit,
+		requireIfMatch: requireIfMatch,
+		idempotencyTTL: idempotencyTTL,
+		accessTTL:      accessTTL,
+		refreshTTL:     refreshTTL,
 	}
 
 	slog.Info("[ ☘️ Run migration rollback ]")
@@ -62,6 +127,8 @@ func (s *Server) Start(ctx context.Context) error {
 	slog.Info("[ Server started on port: " + s.config.Port + " ]")
 	defer func() {}()
 
+	go s.purge(ctx)
+
 	// Using a buffered channel to avoid goroutine leaks
 	channel := make(chan error, 1)
 
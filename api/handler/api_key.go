@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/api/repository"
+	"github.com/Kbgjtn/notethingness-api.git/types"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+type APIKeyResource struct {
+	repo *repository.APIKeyRepository
+}
+
+func NewAPIKey(repo *repository.APIKeyRepository) *APIKeyResource {
+	return &APIKeyResource{repo}
+}
+
+func (rs APIKeyResource) Routes(route chi.Router) {
+	route.Use(RequireUser)
+	route.Get("/", rs.List)
+	route.Post("/", rs.Create)
+	route.Delete("/{id}", rs.Revoke)
+}
+
+// List returns the API keys of the user
+// @Summary List API keys
+// @Description Get the API keys of the user that are not revoked, in every workspace. Keys are never shown again after they are created.
+// @Tags key
+// @Produce json
+// @Success 200 {object} types.JSONResult{data=model.APIKeys}
+// @Failure 403 {object} types.JSONError "Forbidden: API keys cannot manage API keys"
+// @Security BearerAuth
+// @Router /keys [get]
+// !curl localhost:3000/api/keys -H "Authorization: Bearer $TOKEN" | jq
+func (rs APIKeyResource) List(w http.ResponseWriter, r *http.Request) {
+	principal, _ := types.PrincipalFrom(r.Context())
+
+	keys, err := rs.repo.List(r.Context(), principal.UserID)
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	data, err := json.Marshal(keys.ToJSON())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "error: failed to marshal API keys")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// Create mints an API key
+// @Summary Create an API key
+// @Description Mint an API key acting for the user within its scopes in the workspace of the request, sent as Authorization: Bearer <key>.
+// @Description The key is only shown in this response. Scopes: tasks:read, tasks:write, categories:read, categories:admin, projects:read, projects:write; write and admin scopes include reading.
+// @Tags key
+// @Accept json
+// @Produce json
+// @Param request body model.APIKeyRequestPayload true "default"
+// @Success 201 {object} types.JSONResult{data=model.CreatedAPIKey}
+// @Failure 400 {object} types.JSONError "Bad Request: name, scopes or expires_at is invalid"
+// @Failure 403 {object} types.JSONError "Forbidden: API keys cannot manage API keys"
+// @Security BearerAuth
+// @Router /keys [post]
+// !curl -v 'POST' localhost:3000/api/keys -d '{"name":"CI","scopes":["tasks:read"]}' -H "Content-Type: application/json" -H "Authorization: Bearer $TOKEN" | jq
+func (rs APIKeyResource) Create(w http.ResponseWriter, r *http.Request) {
+	var payload model.APIKeyRequestPayload
+	if err := util.ParseRequestBody(r, &payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := payload.Validate(time.Now()); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	principal, _ := types.PrincipalFrom(r.Context())
+	key, err := rs.repo.Create(r.Context(), principal.UserID, types.WorkspaceFrom(r.Context()), payload)
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	data, err := json.Marshal(key.ToJSON(201, "Created"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "error: failed to marshal API key")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(data)
+}
+
+// Revoke revokes an API key
+// @Summary Revoke an API key
+// @Description Revoke an API key of the user, it is rejected from then on
+// @Tags key
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {string} string "Success"
+// @Failure 400 {object} types.JSONError "Bad Request: id is invalid or missing"
+// @Failure 403 {object} types.JSONError "Forbidden: API keys cannot manage API keys"
+// @Failure 404 {object} types.JSONError "Not Found: API key not found"
+// @Security BearerAuth
+// @Router /keys/{id} [delete]
+func (rs APIKeyResource) Revoke(w http.ResponseWriter, r *http.Request) {
+	args, err := model.ParseParams(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	principal, _ := types.PrincipalFrom(r.Context())
+	if err := rs.repo.Revoke(r.Context(), principal.UserID, args); err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+}
+
+// writeAPIKeyError maps errors returned by the API key repository to a JSON error
+func writeAPIKeyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrAPIKeyNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	default:
+		slog.Error(err.Error())
+		writeError(w, http.StatusInternalServerError, "error: failed to process API key")
+	}
+}
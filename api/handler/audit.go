@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+// Audit puts the request ID of middleware.RequestID and the email of the user
+// authenticated by Authenticate in the request context, for the history of
+// changes. Changes made with an API key name the key too.
+func Audit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ := types.PrincipalFrom(r.Context())
+
+		actor := principal.Email
+		if principal.KeyID != 0 {
+			actor = fmt.Sprintf("%s (key %d)", principal.Email, principal.KeyID)
+		}
+
+		ctx := types.WithAudit(r.Context(), types.Audit{
+			Actor:     actor,
+			RequestID: middleware.GetReqID(r.Context()),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
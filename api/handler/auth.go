@@ -0,0 +1,373 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/api/repository"
+	"github.com/Kbgjtn/notethingness-api.git/types"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+// Authenticate rejects requests without a valid access token or API key in
+// their Authorization header and puts the user they were issued to in the
+// request context. API keys are told apart by model.APIKeyPrefix.
+func Authenticate(keys *repository.APIKeyRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+			token = strings.TrimSpace(token)
+			if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				writeError(w, http.StatusUnauthorized, "error: an access token or API key is required as a Bearer token in the Authorization header")
+				return
+			}
+
+			var principal types.Principal
+			if strings.HasPrefix(token, model.APIKeyPrefix) {
+				var err error
+				principal, err = keys.Authenticate(r.Context(), token)
+				if err != nil && !errors.Is(err, repository.ErrInvalidAPIKey) {
+					slog.Error(err.Error())
+					writeError(w, http.StatusInternalServerError, "error: failed to check the API key")
+					return
+				}
+				if err != nil {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+					writeError(w, http.StatusUnauthorized, err.Error())
+					return
+				}
+			} else {
+				claims, err := util.ParseAccessToken(token)
+				if err != nil {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+					writeError(w, http.StatusUnauthorized, err.Error())
+					return
+				}
+				principal.UserID, _ = claims.UserID()
+				principal.Email = claims.Email
+				principal.WorkspaceID = claims.WorkspaceID
+			}
+
+			next.ServeHTTP(w, r.WithContext(types.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// FeedToken lets clients that cannot send an Authorization header, such as
+// calendar apps subscribing to a feed, pass an API key in the "token" query
+// parameter instead. Access tokens are not accepted there: they are short-lived
+// and URLs end up in logs.
+func FeedToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if token == "" || r.Header.Get("Authorization") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !strings.HasPrefix(token, model.APIKeyPrefix) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+			writeError(w, http.StatusUnauthorized, "error: \"token\" must be an API key")
+			return
+		}
+
+		r = r.Clone(r.Context())
+		r.Header.Set("Authorization", "Bearer "+token)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireScope lets API keys through only when they carry the scope of the
+// request: read for GET and HEAD requests, write otherwise. Access tokens of
+// a logged in user are always let through.
+func RequireScope(read, write string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope := write
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				scope = read
+			}
+
+			principal, _ := types.PrincipalFrom(r.Context())
+			if !model.ScopeAllows(principal.Scopes, scope) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="insufficient_scope", scope="`+scope+`"`)
+				writeError(w, http.StatusForbidden, fmt.Sprintf("error: the API key needs the %q scope", scope))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireUser rejects requests authenticated with an API key, for what only
+// the user can do such as managing API keys
+func RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if principal, _ := types.PrincipalFrom(r.Context()); principal.KeyID != 0 {
+			writeError(w, http.StatusForbidden, model.ErrAPIKeyForbidden.Error())
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type AuthResource struct {
+	users      *repository.UserRepository
+	workspaces *repository.WorkspaceRepository
+	// accessTTL is how long an access token is valid
+	accessTTL time.Duration
+}
+
+func NewAuth(
+	users *repository.UserRepository, workspaces *repository.WorkspaceRepository, accessTTL time.Duration,
+) *AuthResource {
+	return &AuthResource{users, workspaces, accessTTL}
+}
+
+func (rs AuthResource) Routes(route chi.Router) {
+	route.Post("/register", rs.Register)
+	route.Post("/login", rs.Login)
+	route.Post("/refresh", rs.Refresh)
+	route.Post("/logout", rs.Logout)
+}
+
+// Register creates a user account
+// @Summary Register
+// @Description Create a user account with a personal workspace and log it in. Passwords are stored as bcrypt hashes.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.RegisterPayload true "default"
+// @Success 201 {object} types.JSONResult{data=model.TokenPair}
+// @Failure 400 {object} types.JSONError "Bad Request: email, password or name is invalid"
+// @Failure 409 {object} types.JSONError "Conflict: a user with this email already exists"
+// @Router /auth/register [post]
+// !curl -v 'POST' localhost:3000/api/auth/register -d '{"email":"jane@example.com","password":"correct horse"}' -H "Content-Type: application/json" | jq
+func (rs AuthResource) Register(w http.ResponseWriter, r *http.Request) {
+	var payload model.RegisterPayload
+	if err := util.ParseRequestBody(r, &payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := rs.users.Create(r.Context(), payload)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	refresh, err := rs.users.IssueRefreshToken(r.Context(), user.ID)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	rs.writeTokens(w, r, http.StatusCreated, user, refresh)
+}
+
+// Login exchanges an email and a password for tokens
+// @Summary Login
+// @Description Get an access token, sent as Authorization: Bearer <token> to the rest of the API, and a refresh token to get the next one.
+// @Description The access token is for the first workspace the user joined, requests can pick another one with the X-Workspace-ID header.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.LoginPayload true "default"
+// @Success 200 {object} types.JSONResult{data=model.TokenPair}
+// @Failure 400 {object} types.JSONError "Bad Request: email or password is missing"
+// @Failure 401 {object} types.JSONError "Unauthorized: email or password is incorrect"
+// @Router /auth/login [post]
+// !curl -v 'POST' localhost:3000/api/auth/login -d '{"email":"jane@example.com","password":"correct horse"}' -H "Content-Type: application/json" | jq
+func (rs AuthResource) Login(w http.ResponseWriter, r *http.Request) {
+	var payload model.LoginPayload
+	if err := util.ParseRequestBody(r, &payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := rs.users.Authenticate(r.Context(), payload)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	refresh, err := rs.users.IssueRefreshToken(r.Context(), user.ID)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	rs.writeTokens(w, r, http.StatusOK, user, refresh)
+}
+
+// Refresh exchanges a refresh token for new tokens
+// @Summary Refresh tokens
+// @Description Get a new access token and a new refresh token. A refresh token can be used once; using it again logs out every session of the login it came from.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.RefreshPayload true "default"
+// @Success 200 {object} types.JSONResult{data=model.TokenPair}
+// @Failure 400 {object} types.JSONError "Bad Request: refresh_token is missing"
+// @Failure 401 {object} types.JSONError "Unauthorized: refresh token is invalid, expired or already used"
+// @Router /auth/refresh [post]
+func (rs AuthResource) Refresh(w http.ResponseWriter, r *http.Request) {
+	var payload model.RefreshPayload
+	if err := util.ParseRequestBody(r, &payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, refresh, err := rs.users.RotateRefreshToken(r.Context(), payload.RefreshToken)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	rs.writeTokens(w, r, http.StatusOK, user, refresh)
+}
+
+// Logout revokes a refresh token
+// @Summary Logout
+// @Description Revoke a refresh token and the ones rotated from the same login. Access tokens stay valid until they expire.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.RefreshPayload true "default"
+// @Success 200 {string} string "Success"
+// @Failure 400 {object} types.JSONError "Bad Request: refresh_token is missing"
+// @Router /auth/logout [post]
+func (rs AuthResource) Logout(w http.ResponseWriter, r *http.Request) {
+	var payload model.RefreshPayload
+	if err := util.ParseRequestBody(r, &payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := rs.users.RevokeRefreshToken(r.Context(), payload.RefreshToken); err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+}
+
+// Me returns the authenticated user
+// @Summary Current user
+// @Description Get the user the access token was issued to
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} types.JSONResult{data=model.User}
+// @Failure 401 {object} types.JSONError "Unauthorized: access token is missing, invalid or expired"
+// @Router /users/me [get]
+func (rs AuthResource) Me(w http.ResponseWriter, r *http.Request) {
+	principal, _ := types.PrincipalFrom(r.Context())
+
+	user, err := rs.users.Get(r.Context(), principal.UserID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		// the account was deleted after the token was issued
+		writeError(w, http.StatusUnauthorized, util.ErrInvalidToken.Error())
+		return
+	}
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	data, err := json.Marshal(user.ToJSON(200, "Success"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "error: failed to marshal user")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// writeTokens issues an access token for user and their default workspace and
+// responds with it and the refresh token
+func (rs AuthResource) writeTokens(
+	w http.ResponseWriter, r *http.Request, code int, user model.User, refresh string,
+) {
+	workspaceID, err := rs.workspaces.Default(r.Context(), user.ID)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	access, err := util.IssueAccessToken(user.ID, user.Email, workspaceID, rs.accessTTL)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	message := "Success"
+	if code == http.StatusCreated {
+		message = "Created"
+	}
+
+	data, err := json.Marshal(model.TokenPair{
+		AccessToken:  access,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(rs.accessTTL.Seconds()),
+		RefreshToken: refresh,
+		WorkspaceID:  workspaceID,
+		User:         user,
+	}.ToJSON(code, message))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "error: failed to marshal tokens")
+		return
+	}
+
+	// tokens must not be kept by caches
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+// writeAuthError maps errors returned by the user repository to a JSON error
+func writeAuthError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, model.ErrInvalidCredentials), errors.Is(err, repository.ErrInvalidRefreshToken):
+		writeError(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, repository.ErrUserExists):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		slog.Error(err.Error())
+		writeError(w, http.StatusInternalServerError, "error: failed to process the request")
+	}
+}
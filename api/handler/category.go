@@ -2,6 +2,8 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 
@@ -9,7 +11,6 @@ import (
 
 	"github.com/Kbgjtn/notethingness-api.git/api/model"
 	"github.com/Kbgjtn/notethingness-api.git/api/repository"
-	"github.com/Kbgjtn/notethingness-api.git/types"
 	"github.com/Kbgjtn/notethingness-api.git/util"
 )
 
@@ -27,7 +28,9 @@ func (rs CategoryResource) Routes(route chi.Router) {
 	route.Route("/{id}", func(r chi.Router) {
 		r.Get("/", rs.Get)
 		r.Put("/", rs.Update)
+		r.Patch("/", rs.Patch)
 		r.Delete("/", rs.Delete)
+		r.Get("/history", rs.History)
 	})
 }
 
@@ -37,35 +40,30 @@ func (rs CategoryResource) Routes(route chi.Router) {
 // @Tags category
 // @Accept json
 // @Produce json
-// @Param id path string true "Quote ID"
-// @Success 200 {object} model.Category
-// @Failure 400 {string} string "Bad Request: id is invalid or missing"
+// @Param id path string true "Category ID"
+// @Success 200 {object} types.JSONResult{data=model.Category}
+// @Failure 400 {object} types.JSONError "Bad Request: id is invalid or missing"
+// @Failure 404 {object} types.JSONError "Not Found: category not found"
+// @Security BearerAuth
 // @Router /categories/{id} [get]
 // !curl localhost:3000/api/categories/1 | jq
 func (rs CategoryResource) Get(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	args, err := model.ParseParams(id)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	result, err := rs.repo.Get(r.Context(), args)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
-		return
-	}
-
-	if result.ID == 0 {
-		w.WriteHeader(http.StatusNotFound)
+		writeCategoryError(w, err)
 		return
 	}
 
 	json, err := json.Marshal(result.ToJSON(200, "Success"))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("error: failed to marshal category"))
+		writeError(w, http.StatusInternalServerError, "error: failed to marshal category")
 		return
 	}
 
@@ -82,27 +80,38 @@ func (rs CategoryResource) Get(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param offset query string false "string default example" default(0) example(1)
 // @Param limit query string false "string default example" default(10) example(20)
+// @Param after query string false "cursor from next_cursor of a previous page"
+// @Param before query string false "cursor from prev_cursor of a previous page"
+// @Param count query string false "how to compute paginate.total: exact, estimate or none" default(exact)
+// @Param filter query string false "filter expression over id, label and owner_id" example(label~"in")
+// @Param sort query string false "comma separated fields, prefix with - for descending" example(-label)
 // @Success 200 {object} types.JSONResult{data=model.Categories}
-// @Failure 400 {string} string "Bad Request: error message"
+// @Failure 400 {object} types.JSONError "Bad Request: invalid filter or sort"
+// @Security BearerAuth
 // @Router /categories [get]
 // !curl localhost:3000/api/categories | jq
 func (rs CategoryResource) List(w http.ResponseWriter, r *http.Request) {
-	limit := r.URL.Query().Get("limit")
-	offset := r.URL.Query().Get("offset")
-	p := types.Pageable{}.Parse(limit, offset)
+	p, err := parsePageable(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	result, err := rs.repo.List(r.Context(), &p)
+	expr, sort, err := parseListQuery(r, model.CategoryFields)
 	if err != nil {
-		slog.Error(err.Error())
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("error: failed to get categories"))
+		writeQueryError(w, err)
+		return
+	}
+
+	result, err := rs.repo.List(r.Context(), &p, model.CategoryFilter{Expr: expr, Sort: sort})
+	if err != nil {
+		writeCategoryError(w, err)
 		return
 	}
 
 	data, err := json.Marshal(result.ToJSON(p))
 	if err != nil {
-		w.WriteHeader(http.StatusBadGateway)
-		w.Write([]byte("error: failed to parsing to JSON"))
+		writeError(w, http.StatusInternalServerError, "error: failed to parsing to JSON")
 		return
 	}
 
@@ -119,35 +128,33 @@ func (rs CategoryResource) List(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param request body model.CategoryRequestPayload true "default"
 // @Success 201 {object} types.JSONResult{data=model.Category}
-// @Failure 400 {string} string "Bad Request: label is invalid or missing"
+// @Failure 400 {object} types.JSONError "Bad Request: label is invalid or missing"
+// @Failure 409 {object} types.JSONError "Conflict: label already exists"
+// @Security BearerAuth
 // @Router /categories [post]
 // !curl -v 'POST' localhost:3000/api/categories -d '{"label":"test"}' -H "Content-Type: application/json" | jq
 func (rs CategoryResource) Create(w http.ResponseWriter, r *http.Request) {
 	var payload model.CategoryRequestPayload
 	err := util.ParseRequestBody(r, &payload)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	if err = payload.Validate(); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	result, err := rs.repo.Create(r.Context(), payload.Label)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		writeCategoryError(w, err)
 		return
 	}
 
 	json, err := json.Marshal(result.ToJSON(201, "Created"))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("error: failed to marshal category"))
+		writeError(w, http.StatusInternalServerError, "error: failed to marshal category")
 		return
 	}
 
@@ -158,26 +165,27 @@ func (rs CategoryResource) Create(w http.ResponseWriter, r *http.Request) {
 
 // Delete a category
 // @Summary Delete a category
-// @Description Delete a category
+// @Description Delete a category, untagging every task tagged with it
 // @Tags category
 // @Accept json
 // @Produce json
 // @Param id path string true "Category ID"
 // @Success 200 {string} string "Success"
-// @Failure 400 {string} string "Bad Request: id is invalid or missing"
+// @Failure 400 {object} types.JSONError "Bad Request: id is invalid or missing"
+// @Failure 404 {object} types.JSONError "Not Found: category not found"
+// @Security BearerAuth
 // @Router /categories/{id} [delete]
 // !curl -v -X DELETE localhost:3000/api/categories/1 | jq
 func (rs CategoryResource) Delete(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	args, err := model.ParseParams(id)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	if err = rs.repo.Delete(r.Context(), args); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		writeCategoryError(w, err)
 		return
 	}
 
@@ -194,41 +202,95 @@ func (rs CategoryResource) Delete(w http.ResponseWriter, r *http.Request) {
 // @Param id path string true "Category ID"
 // @Param request body model.CategoryRequestPayload true "default"
 // @Success 200 {object} types.JSONResult{data=model.Category}
-// @Failure 400 {string} string "Bad Request: id is invalid or missing"
+// @Failure 400 {object} types.JSONError "Bad Request: id is invalid or missing"
+// @Failure 404 {object} types.JSONError "Not Found: category not found"
+// @Failure 409 {object} types.JSONError "Conflict: label already exists"
+// @Security BearerAuth
 // @Router /categories/{id} [put]
 // !curl -v -X PUT localhost:3000/api/categories/1 -d '{"label":"test"}' -H "Content-Type: application/json" | jq
 func (rs CategoryResource) Update(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	args, err := model.ParseParams(id)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	var payload model.CategoryRequestPayload
 	if err = util.ParseRequestBody(r, &payload); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	if err = payload.Validate(); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	result, err := rs.repo.Update(r.Context(), args, payload.Label)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		writeCategoryError(w, err)
+		return
+	}
+
+	json, err := json.Marshal(result.ToJSON(200, "Success"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "error: failed to marshal category")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(json)
+}
+
+// Patch changes some fields of a category
+// @Summary Patch a category
+// @Description Change the fields named in a JSON Merge Patch (application/merge-patch+json) or a JSON Patch (application/json-patch+json). The patched category is validated before it is saved.
+// @Tags category
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Param request body model.CategoryRequestPayload true "fields to change, or a list of JSON Patch operations"
+// @Success 200 {object} types.JSONResult{data=model.Category}
+// @Failure 400 {object} types.JSONError "Bad Request: id or patch is invalid"
+// @Failure 404 {object} types.JSONError "Not Found: category not found"
+// @Failure 409 {object} types.JSONError "Conflict: label already exists or the patch does not apply"
+// @Failure 415 {object} types.JSONError "Unsupported Media Type: not a patch document"
+// @Failure 422 {object} types.JSONError "Unprocessable Entity: patched category is invalid"
+// @Security BearerAuth
+// @Router /categories/{id} [patch]
+func (rs CategoryResource) Patch(w http.ResponseWriter, r *http.Request) {
+	args, err := model.ParseParams(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	patch, err := util.ParsePatch(r)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	result, err := rs.repo.Patch(r.Context(), args, func(current model.CategoryRequestPayload) (model.CategoryRequestPayload, error) {
+		patched, err := util.PatchValue(patch, current)
+		if err != nil {
+			return patched, err
+		}
+		if err := patched.Validate(); err != nil {
+			return patched, fmt.Errorf("%w: %s", util.ErrPatchResult, err)
+		}
+		return patched, nil
+	})
+	if err != nil {
+		writeCategoryError(w, err)
 		return
 	}
 
 	json, err := json.Marshal(result.ToJSON(200, "Success"))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("error: failed to marshal category"))
+		writeError(w, http.StatusInternalServerError, "error: failed to marshal category")
 		return
 	}
 
@@ -236,3 +298,25 @@ func (rs CategoryResource) Update(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write(json)
 }
+
+// writeCategoryError maps errors returned by the category repository to a JSON error
+func writeCategoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrCategoryNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, repository.ErrCategoryExists):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, util.ErrInvalidCursor), errors.Is(err, util.ErrInvalidPatch):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, util.ErrPatchConflict):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, util.ErrUnsupportedPatch):
+		advertisePatch(w, err)
+		writeError(w, http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, util.ErrPatchResult):
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		slog.Error(err.Error())
+		writeError(w, http.StatusInternalServerError, "error: failed to process category")
+	}
+}
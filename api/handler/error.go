@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+// writeError responds with a JSON error body
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSONError(w, types.JSONError{Code: code, Message: message})
+}
+
+func writeJSONError(w http.ResponseWriter, body types.JSONError) {
+	data, _ := json.Marshal(body)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(body.Code)
+	w.Write(data)
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+// ifMatch reads the If-Match header into args. When the header is required
+// but missing it answers 428 Precondition Required and returns false.
+func (rs TasksResource) ifMatch(w http.ResponseWriter, r *http.Request, args *model.TaskURLParams) bool {
+	args.IfMatch = util.IfMatch(r)
+	if rs.options.RequireIfMatch && !args.IfMatch.Present() {
+		http.Error(w, model.ErrPreconditionRequired.Error(), http.StatusPreconditionRequired)
+		return false
+	}
+	return true
+}
+
+// writeStale answers 412 Precondition Failed with the current task when err
+// is a StaleTaskError, and reports whether it did
+func writeStale(w http.ResponseWriter, err error) bool {
+	var stale *model.StaleTaskError
+	if !errors.As(err, &stale) {
+		return false
+	}
+
+	response := stale.Current.CreateTaskResponseDto()
+	response.Code = http.StatusPreconditionFailed
+	response.Message = err.Error()
+
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return true
+	}
+
+	w.Header().Set("ETag", stale.Current.ETag())
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusPreconditionFailed)
+	w.Write(jsonData)
+	return true
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/types"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+// parsePageable parses the offset, limit, after, before and count query parameters
+func parsePageable(r *http.Request) (types.Pageable, error) {
+	query := r.URL.Query()
+	p := types.Pageable{}.Parse(query.Get("limit"), query.Get("offset"))
+	return p.ParseCursor(query.Get("after"), query.Get("before"), query.Get("count"))
+}
+
+// parseListQuery parses the "filter" and "sort" query parameters against a field whitelist
+func parseListQuery(r *http.Request, schema util.FilterSchema) (util.FilterNode, []util.SortField, error) {
+	var expr util.FilterNode
+	var sort []util.SortField
+	var err error
+
+	if filter := r.URL.Query().Get("filter"); filter != "" {
+		if expr, err = util.ParseFilter(filter, schema); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if s := r.URL.Query().Get("sort"); s != "" {
+		if sort, err = util.ParseSort(s, schema); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return expr, sort, nil
+}
+
+// parseTaskFilter parses the status, category, project, filter and sort query parameters
+// of the task lists. It answers 400 and returns false when one is invalid.
+func parseTaskFilter(w http.ResponseWriter, r *http.Request) (model.TaskFilter, bool) {
+	statuses, err := model.ParseTaskStatuses(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return model.TaskFilter{}, false
+	}
+
+	expr, sort, err := parseListQuery(r, model.TaskFields)
+	if err != nil {
+		writeQueryError(w, err)
+		return model.TaskFilter{}, false
+	}
+
+	categoryIDs, err := model.ParseCategoryIDs(r.URL.Query().Get("category"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return model.TaskFilter{}, false
+	}
+
+	projectID, err := model.ParseProjectID(r.URL.Query().Get("project"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return model.TaskFilter{}, false
+	}
+
+	return model.TaskFilter{
+		Statuses: statuses, CategoryIDs: categoryIDs, ProjectID: projectID, Expr: expr, Sort: sort,
+	}, true
+}
+
+// writeQueryError responds 400 with a JSON error pointing at the offending token
+func writeQueryError(w http.ResponseWriter, err error) {
+	body := types.JSONError{Code: http.StatusBadRequest, Message: err.Error()}
+
+	var filterErr *util.FilterError
+	if errors.As(err, &filterErr) {
+		body.Pointer = filterErr.Token
+	}
+
+	writeJSONError(w, body)
+}
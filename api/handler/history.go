@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+)
+
+// History returns the changes of a task
+// @Summary Task history
+// @Description Get the field-level changes of a task, newest first, with who made them and in which request. The history of a deleted task stays available
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Task ID"
+// @Param offset query string false "string default example" default(0) example(1)
+// @Param limit query string false "string default example" default(10) example(20)
+// @Param after query string false "cursor from next_cursor of a previous page"
+// @Param before query string false "cursor from prev_cursor of a previous page"
+// @Param count query string false "how to compute paginate.total: exact, estimate or none" default(exact)
+// @Success 200 {object} types.JSONResult{data=model.History,paginate=types.Pageable,length=int}
+// @Failure 400 {string} string "error: id is invalid"
+// @Security BearerAuth
+// @Router /tasks/{id}/history [get]
+func (rs TasksResource) History(w http.ResponseWriter, r *http.Request) {
+	var reqDTO model.TaskURLParams
+	if err := reqDTO.Parse(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p, err := parsePageable(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := rs.repo.History(r.Context(), reqDTO, &p)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	jsonData, err := json.Marshal(data.ToJSON(p))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(jsonData)
+}
+
+// History returns the changes of a category
+// @Summary Category history
+// @Description Get the changes of a category, newest first, with who made them and in which request
+// @Tags category
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Param offset query string false "string default example" default(0) example(1)
+// @Param limit query string false "string default example" default(10) example(20)
+// @Param after query string false "cursor from next_cursor of a previous page"
+// @Param before query string false "cursor from prev_cursor of a previous page"
+// @Param count query string false "how to compute paginate.total: exact, estimate or none" default(exact)
+// @Success 200 {object} types.JSONResult{data=model.History,paginate=types.Pageable,length=int}
+// @Failure 400 {object} types.JSONError "Bad Request: id is invalid or missing"
+// @Security BearerAuth
+// @Router /categories/{id}/history [get]
+func (rs CategoryResource) History(w http.ResponseWriter, r *http.Request) {
+	args, err := model.ParseParams(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	p, err := parsePageable(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := rs.repo.History(r.Context(), args, &p)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	data, err := json.Marshal(result.ToJSON(p))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "error: failed to parsing to JSON")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/api/repository"
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+// replayedHeaders are the response headers saved with an idempotent response
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// Idempotency makes POST requests sent with an Idempotency-Key safe to retry:
+// the first response is saved and replayed for every retry with the same key
+// and body, instead of handling the request again. Retries get 409 while the
+// first request is still running, however long it takes. Server errors are
+// not saved, the key is released instead so that a retry can succeed, and so
+// it is when the handler panics. Bodies larger than
+// model.MaxIdempotentBodySize are rejected before they are buffered.
+func Idempotency(repo *repository.IdempotencyRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if err := model.ValidateIdempotencyKey(key); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			// the body is read whole to fingerprint it, so it is capped like the
+			// largest upload and given as long to arrive
+			extendReadDeadline(w)
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, model.MaxIdempotentBodySize))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeError(w, http.StatusRequestEntityTooLarge,
+					fmt.Sprintf("error: the request body must be at most %d MB", model.MaxIdempotentBodySize>>20))
+				return
+			}
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "error: failed to read the request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			// the response is saved even when the client gives up waiting for it
+			ctx := context.WithoutCancel(r.Context())
+			actor := types.AuditFrom(ctx).Actor
+
+			saved, err := repo.Begin(ctx, actor, key, model.RequestFingerprint(r.Method, r.URL.Path, r.URL.Query(), body))
+			switch {
+			case errors.Is(err, model.ErrIdempotencyMismatch):
+				writeError(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			case errors.Is(err, model.ErrIdempotencyInProgress):
+				writeError(w, http.StatusConflict, err.Error())
+				return
+			case err != nil:
+				slog.Error("failed to claim idempotency key: " + err.Error())
+				writeError(w, http.StatusInternalServerError, "error: failed to process the Idempotency-Key")
+				return
+			case saved != nil:
+				for name, value := range saved.Header {
+					w.Header().Set(name, value)
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(saved.Status)
+				w.Write(saved.Body)
+				return
+			}
+
+			// the claim is kept until the response is saved, and given up on
+			// every other way out of the handler, including panics
+			answered := false
+			defer func() {
+				if answered {
+					return
+				}
+				if err := repo.Release(ctx, actor, key); err != nil {
+					slog.Error("failed to release idempotency key: " + err.Error())
+				}
+			}()
+
+			var response bytes.Buffer
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&response)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			if status >= http.StatusInternalServerError {
+				return
+			}
+
+			header := map[string]string{}
+			for _, name := range replayedHeaders {
+				if value := ww.Header().Get(name); value != "" {
+					header[name] = value
+				}
+			}
+			err = repo.Save(ctx, actor, key, model.IdempotentResponse{
+				Status: status, Header: header, Body: response.Bytes(),
+			})
+			if err != nil {
+				slog.Error("failed to save idempotent response: " + err.Error())
+				return
+			}
+			answered = true
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+// acceptPatch lists the patch formats understood by the PATCH endpoints
+const acceptPatch = util.MergePatchType + ", " + util.JSONPatchType
+
+// advertisePatch tells a client that sent an unsupported patch format which ones are supported
+func advertisePatch(w http.ResponseWriter, err error) {
+	if errors.Is(err, util.ErrUnsupportedPatch) {
+		w.Header().Set("Accept-Patch", acceptPatch)
+	}
+}
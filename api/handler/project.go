@@ -0,0 +1,303 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/api/repository"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+type ProjectResource struct {
+	repo *repository.ProjectRepository
+}
+
+func NewProject(repo *repository.ProjectRepository) *ProjectResource {
+	return &ProjectResource{repo}
+}
+
+func (rs ProjectResource) Routes(route chi.Router) {
+	route.Get("/", rs.List)
+	route.Post("/", rs.Create)
+	route.Route("/{id}", func(r chi.Router) {
+		r.Get("/", rs.Get)
+		r.Put("/", rs.Update)
+		r.Delete("/", rs.Delete)
+		r.Post("/archive", rs.Archive)
+		r.Post("/unarchive", rs.Unarchive)
+	})
+}
+
+// List return a list of projects
+// @Summary Get list
+// @Description Get List of projects, the archived ones only with archived=true
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param offset query string false "string default example" default(0) example(1)
+// @Param limit query string false "string default example" default(10) example(20)
+// @Param after query string false "cursor from next_cursor of a previous page"
+// @Param before query string false "cursor from prev_cursor of a previous page"
+// @Param count query string false "how to compute paginate.total: exact, estimate or none" default(exact)
+// @Param archived query bool false "list the archived projects too" default(false)
+// @Param filter query string false "filter expression over id, name, owner_id and dates" example(name~"web")
+// @Param sort query string false "comma separated fields, prefix with - for descending" example(-created_at)
+// @Success 200 {object} types.JSONResult{data=model.Projects}
+// @Failure 400 {object} types.JSONError "Bad Request: invalid filter or sort"
+// @Security BearerAuth
+// @Router /projects [get]
+// !curl localhost:3000/api/projects | jq
+func (rs ProjectResource) List(w http.ResponseWriter, r *http.Request) {
+	p, err := parsePageable(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	expr, sort, err := parseListQuery(r, model.ProjectFields)
+	if err != nil {
+		writeQueryError(w, err)
+		return
+	}
+
+	var archived bool
+	if value := r.URL.Query().Get("archived"); value != "" {
+		if archived, err = strconv.ParseBool(value); err != nil {
+			writeError(w, http.StatusBadRequest, "error: \"archived\" must be true or false")
+			return
+		}
+	}
+
+	filter := model.ProjectFilter{Archived: archived, Expr: expr, Sort: sort}
+	result, err := rs.repo.List(r.Context(), &p, filter)
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	data, err := json.Marshal(result.ToJSON(p))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "error: failed to parsing to JSON")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// Get return a project
+// @Summary Get By ID
+// @Description Get a project by id, archived or not
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} types.JSONResult{data=model.Project}
+// @Failure 400 {object} types.JSONError "Bad Request: id is invalid or missing"
+// @Failure 404 {object} types.JSONError "Not Found: project not found"
+// @Security BearerAuth
+// @Router /projects/{id} [get]
+// !curl localhost:3000/api/projects/1 | jq
+func (rs ProjectResource) Get(w http.ResponseWriter, r *http.Request) {
+	args, err := model.ParseParams(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := rs.repo.Get(r.Context(), args)
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	writeProject(w, http.StatusOK, result)
+}
+
+// Create a project
+// @Summary Create a new project
+// @Description Create a new project
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param request body model.ProjectRequestPayload true "default"
+// @Success 201 {object} types.JSONResult{data=model.Project}
+// @Failure 400 {object} types.JSONError "Bad Request: name is invalid or missing"
+// @Security BearerAuth
+// @Router /projects [post]
+// !curl -v 'POST' localhost:3000/api/projects -d '{"name":"Website"}' -H "Content-Type: application/json" | jq
+func (rs ProjectResource) Create(w http.ResponseWriter, r *http.Request) {
+	var payload model.ProjectRequestPayload
+	if err := util.ParseRequestBody(r, &payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := rs.repo.Create(r.Context(), payload)
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	writeProject(w, http.StatusCreated, result)
+}
+
+// Update a project
+// @Summary Update a project
+// @Description Update the name and description of a project
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param request body model.ProjectRequestPayload true "default"
+// @Success 200 {object} types.JSONResult{data=model.Project}
+// @Failure 400 {object} types.JSONError "Bad Request: id or name is invalid or missing"
+// @Failure 404 {object} types.JSONError "Not Found: project not found"
+// @Security BearerAuth
+// @Router /projects/{id} [put]
+// !curl -v -X PUT localhost:3000/api/projects/1 -d '{"name":"Website"}' -H "Content-Type: application/json" | jq
+func (rs ProjectResource) Update(w http.ResponseWriter, r *http.Request) {
+	args, err := model.ParseParams(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var payload model.ProjectRequestPayload
+	if err = util.ParseRequestBody(r, &payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = payload.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := rs.repo.Update(r.Context(), args, payload)
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	writeProject(w, http.StatusOK, result)
+}
+
+// Delete a project
+// @Summary Delete a project
+// @Description Delete a project, its tasks are kept outside of any project
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {string} string "Success"
+// @Failure 400 {object} types.JSONError "Bad Request: id is invalid or missing"
+// @Failure 404 {object} types.JSONError "Not Found: project not found"
+// @Security BearerAuth
+// @Router /projects/{id} [delete]
+// !curl -v -X DELETE localhost:3000/api/projects/1 | jq
+func (rs ProjectResource) Delete(w http.ResponseWriter, r *http.Request) {
+	args, err := model.ParseParams(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = rs.repo.Delete(r.Context(), args); err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+}
+
+// Archive a project
+// @Summary Archive a project
+// @Description Archive a project: it is hidden from the list and takes no new tasks, its tasks are kept
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} types.JSONResult{data=model.Project}
+// @Failure 400 {object} types.JSONError "Bad Request: id is invalid or missing"
+// @Failure 404 {object} types.JSONError "Not Found: project not found"
+// @Security BearerAuth
+// @Router /projects/{id}/archive [post]
+func (rs ProjectResource) Archive(w http.ResponseWriter, r *http.Request) {
+	rs.archive(w, r, true)
+}
+
+// Unarchive a project
+// @Summary Unarchive a project
+// @Description Bring an archived project back
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} types.JSONResult{data=model.Project}
+// @Failure 400 {object} types.JSONError "Bad Request: id is invalid or missing"
+// @Failure 404 {object} types.JSONError "Not Found: project not found"
+// @Security BearerAuth
+// @Router /projects/{id}/unarchive [post]
+func (rs ProjectResource) Unarchive(w http.ResponseWriter, r *http.Request) {
+	rs.archive(w, r, false)
+}
+
+func (rs ProjectResource) archive(w http.ResponseWriter, r *http.Request, archived bool) {
+	args, err := model.ParseParams(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := rs.repo.Archive(r.Context(), args, archived)
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	writeProject(w, http.StatusOK, result)
+}
+
+func writeProject(w http.ResponseWriter, code int, project model.Project) {
+	message := "Success"
+	if code == http.StatusCreated {
+		message = "Created"
+	}
+
+	json, err := json.Marshal(project.ToJSON(code, message))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "error: failed to marshal project")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	w.Write(json)
+}
+
+// writeProjectError maps errors returned by the project repository to a JSON error
+func writeProjectError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrProjectNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, util.ErrInvalidCursor):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		slog.Error(err.Error())
+		writeError(w, http.StatusInternalServerError, "error: failed to process project")
+	}
+}
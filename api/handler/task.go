@@ -3,33 +3,66 @@ package handler
 // 2024
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/Kbgjtn/notethingness-api.git/api/model"
 	repo "github.com/Kbgjtn/notethingness-api.git/api/repository"
+	"github.com/Kbgjtn/notethingness-api.git/storage"
 	"github.com/Kbgjtn/notethingness-api.git/types"
 	"github.com/Kbgjtn/notethingness-api.git/util"
 )
 
 type TasksResource struct {
-	repo *repo.TaskRepository
+	repo        *repo.TaskRepository
+	attachments *repo.AttachmentRepository
+	options     TaskOptions
 }
 
-func NewTask(r *repo.TaskRepository) *TasksResource {
-	return &TasksResource{r}
+// TaskOptions are the configurable limits and policies of the task endpoints
+type TaskOptions struct {
+	// BatchLimit is the largest number of operations accepted by Batch
+	BatchLimit int
+	// RequireIfMatch rejects PUT, PATCH and DELETE without an If-Match header
+	RequireIfMatch bool
+}
+
+func NewTask(r *repo.TaskRepository, attachments *repo.AttachmentRepository, options TaskOptions) *TasksResource {
+	return &TasksResource{r, attachments, options}
 }
 
 func (rs TasksResource) Routes(route chi.Router) {
 	route.Get("/", rs.List)
 	route.Post("/", rs.Create)
+	route.Get("/order", rs.ExecutionOrder)
+	route.Get("/search", rs.Search)
+	route.Post("/batch", rs.Batch)
+	route.Get("/export", rs.Export)
+	// imports create the categories their tasks are tagged with
+	route.With(RequireScope(model.ScopeCategoriesAdmin, model.ScopeCategoriesAdmin)).Post("/import", rs.Import)
 	route.Route("/{id}",
 		func(r chi.Router) {
 			r.Get("/", rs.Get)
 			r.Delete("/", rs.Delete)
 			r.Put("/", rs.Update)
+			r.Patch("/", rs.Patch)
+			r.Post("/transitions", rs.Transition)
+			r.Get("/children", rs.Children)
+			r.Get("/occurrences", rs.Occurrences)
+			r.Get("/history", rs.History)
+			r.Post("/restore", rs.Restore)
+			r.Route("/comments", rs.commentRoutes)
+			r.Route("/attachments", rs.attachmentRoutes)
+			r.Route("/dependencies", func(r chi.Router) {
+				r.Get("/", rs.Dependencies)
+				r.Post("/", rs.AddDependency)
+				r.Delete("/{dependsOnId}", rs.RemoveDependency)
+			})
 		})
 }
 
@@ -40,9 +73,12 @@ func (rs TasksResource) Routes(route chi.Router) {
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Task ID"
+// @Param If-None-Match header string false "ETag of a previous read, answered with 304 while the task is unchanged"
 // @Success 200 {object} types.JSONResult{data=model.Task}
+// @Success 304 {string} string "not modified"
 // @Failure 400 {string} string "error: id is invalid"
 // @Failure 404 {string} string "error: quote not found"
+// @Security BearerAuth
 // @Router /quotes/{id} [get]
 func (rs TasksResource) Get(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
@@ -56,7 +92,13 @@ func (rs TasksResource) Get(w http.ResponseWriter, r *http.Request) {
 
 	data, err := rs.repo.Get(r.Context(), reqDTO)
 	if err != nil {
-		w.WriteHeader(http.StatusOK)
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("ETag", data.ETag())
+	if util.IfNoneMatch(r).MatchWeak(data.ETag()) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
@@ -80,17 +122,39 @@ func (rs TasksResource) Get(w http.ResponseWriter, r *http.Request) {
 // @Produce  json
 // @Param offset query string false "string default example" default(0) example(1)
 // @Param limit query string false "string default example" default(10) example(20)
+// @Param after query string false "cursor from next_cursor of a previous page"
+// @Param before query string false "cursor from prev_cursor of a previous page"
+// @Param count query string false "how to compute paginate.total: exact, estimate or none" default(exact)
+// @Param status query string false "comma separated statuses, or open for unfinished tasks" example(open)
+// @Param category query string false "comma separated category ids, keeps tasks tagged with any of them" example(1,2)
+// @Param project query string false "project id, keeps the tasks of the project" example(1)
+// @Param tree query bool false "return root tasks with their subtasks nested under children"
+// @Param filter query string false "filter expression, e.g. priority>=2 and date<2024-04-01; operators = != > >= < <= ~, combined with and, or, not and parentheses" example(priority>=2 and date<2024-04-01)
+// @Param sort query string false "comma separated fields, prefix with - for descending" example(-priority,date)
 // @Success 200 {object} types.JSONResult{data=model.Tasks,paginate=types.Pageable,length=int}
-// @Failure 400 {string} string "error: offset or limit is invalid"
+// @Failure 400 {object} types.JSONError "error: invalid filter or sort"
+// @Security BearerAuth
 // @Router /quotes [get]
 func (rs TasksResource) List(w http.ResponseWriter, r *http.Request) {
-	offset := r.URL.Query().Get("offset")
-	limit := r.URL.Query().Get("limit")
-	p := types.Pageable{}.Parse(limit, offset)
+	p, err := parsePageable(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter, ok := parseTaskFilter(w, r)
+	if !ok {
+		return
+	}
 
-	data, err := rs.repo.List(r.Context(), &p)
+	var data model.Tasks
+	if r.URL.Query().Get("tree") == "true" {
+		data, err = rs.repo.Tree(r.Context(), &p, filter)
+	} else {
+		data, err = rs.repo.List(r.Context(), &p, filter)
+	}
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(taskErrorStatus(err))
 		w.Write([]byte(err.Error()))
 		return
 	}
@@ -107,14 +171,21 @@ func (rs TasksResource) List(w http.ResponseWriter, r *http.Request) {
 
 // Delete deletes a quote by id
 // @Summary Delete a quote
-// @Description Delete a quote by id
+// @Description Move a task to the trash, or delete it for good with permanent=true
 // @Tags quote
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Task ID"
+// @Param children query string false "what to do with subtasks: reject, cascade or reparent" default(reject)
+// @Param permanent query bool false "delete for good instead of moving to the trash, also for tasks in the trash"
 // @Success 200 {string} string "ok"
 // @Failure 400 {string} string "error: id is invalid"
 // @Failure 404 {string} string "error: quote not found"
+// @Param If-Match header string false "ETag of the task, the delete fails with 412 when it is stale"
+// @Failure 409 {string} string "error: task has subtasks"
+// @Failure 412 {object} types.JSONResult{data=model.Task} "the task has changed, data is its current version"
+// @Failure 428 {string} string "error: the If-Match header is required"
+// @Security BearerAuth
 // @Router /quotes/{id} [delete]
 func (rs TasksResource) Delete(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
@@ -124,7 +195,27 @@ func (rs TasksResource) Delete(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	err = rs.repo.Delete(r.Context(), reqDTO)
+
+	policy, err := model.ParseCascadePolicy(r.URL.Query().Get("children"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !rs.ifMatch(w, r, &reqDTO) {
+		return
+	}
+
+	permanent := r.URL.Query().Get("permanent") == "true"
+
+	err = rs.repo.Delete(r.Context(), reqDTO, policy, permanent)
+	if writeStale(w, err) {
+		return
+	}
+	if errors.Is(err, repo.ErrTaskNotFound) || errors.Is(err, model.ErrTaskHasChildren) {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write(
@@ -134,6 +225,8 @@ func (rs TasksResource) Delete(w http.ResponseWriter, r *http.Request) {
 		)
 		return
 	}
+
+	rs.collectGarbage(r)
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -144,8 +237,10 @@ func (rs TasksResource) Delete(w http.ResponseWriter, r *http.Request) {
 // @Accept  json
 // @Produce  json
 // @Param request body model.TaskRequestPayload true "default"
+// @Param Idempotency-Key header string false "unique key of the request, a retry with the same key replays the first response"
 // @Success 201 {object} types.JSONResult{data=model.Task}
 // @Failure 400 {string} string "Bad Request: Invalid payload"
+// @Security BearerAuth
 // @Router /quotes [post]
 func (rs TasksResource) Create(w http.ResponseWriter, r *http.Request) {
 	var payload model.TaskRequestPayload
@@ -157,9 +252,14 @@ func (rs TasksResource) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data, err := rs.repo.Create(r.Context(), payload.Title, payload.Priority, payload.Date)
+	if err := payload.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := rs.repo.Create(r.Context(), payload)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(taskErrorStatus(err))
 		w.Write([]byte(err.Error()))
 		return
 	}
@@ -170,6 +270,7 @@ func (rs TasksResource) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", data.ETag())
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	w.WriteHeader(http.StatusCreated)
 	w.Write(jsonData)
@@ -182,8 +283,12 @@ func (rs TasksResource) Create(w http.ResponseWriter, r *http.Request) {
 // @Accept  json
 // @Produce  json
 // @Param request body model.TaskRequestPayload true "default"
+// @Param If-Match header string false "ETag of the task, the update fails with 412 when it is stale"
 // @Success 200 {object} types.JSONResult{data=model.Task}
 // @Failure 400 {string} string "Bad Request: Invalid payload"
+// @Failure 412 {object} types.JSONResult{data=model.Task} "the task has changed, data is its current version"
+// @Failure 428 {string} string "error: the If-Match header is required"
+// @Security BearerAuth
 // @Router /quotes/{id} [put]
 func (rs TasksResource) Update(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
@@ -193,15 +298,32 @@ func (rs TasksResource) Update(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	var payload model.Task
+	if !rs.ifMatch(w, r, &reqDTO) {
+		return
+	}
+
+	var payload model.TaskRequestPayload
 	if err = util.ParseRequestBody(r, &payload); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write([]byte("error: payload is invalid or missing"))
 		return
 	}
 
+	if err := payload.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	data, err := rs.repo.Update(r.Context(), reqDTO, payload)
 	if err != nil {
+		if writeStale(w, err) {
+			return
+		}
+		if errors.Is(err, repo.ErrTaskNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(err.Error()))
+			return
+		}
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write([]byte(err.Error()))
 		return
@@ -214,7 +336,259 @@ func (rs TasksResource) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", data.ETag())
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	w.WriteHeader(http.StatusOK)
 	w.Write(jsonData)
 }
+
+// Patch changes some fields of a task
+// @Summary Patch a task
+// @Description Change only the fields named in the patch: title, priority, date, parent_id, recurrence or category_ids. Send a JSON Merge Patch (application/merge-patch+json) or a JSON Patch (application/json-patch+json); the patched task is validated before it is saved. Use the transitions endpoint to change the status.
+// @Tags task
+// @Accept  application/merge-patch+json,application/json-patch+json
+// @Produce  json
+// @Param id path string true "Task ID"
+// @Param request body model.TaskRequestPayload true "fields to change, or a list of JSON Patch operations"
+// @Param If-Match header string false "ETag of the task, the patch fails with 412 when it is stale"
+// @Success 200 {object} types.JSONResult{data=model.Task}
+// @Failure 400 {string} string "error: patch document is invalid"
+// @Failure 404 {string} string "error: task not found"
+// @Failure 409 {string} string "error: patch cannot be applied to the current resource"
+// @Failure 415 {string} string "error: Content-Type must be application/merge-patch+json or application/json-patch+json"
+// @Failure 412 {object} types.JSONResult{data=model.Task} "the task has changed, data is its current version"
+// @Failure 422 {string} string "error: patched resource is invalid"
+// @Failure 428 {string} string "error: the If-Match header is required"
+// @Security BearerAuth
+// @Router /tasks/{id} [patch]
+func (rs TasksResource) Patch(w http.ResponseWriter, r *http.Request) {
+	var reqDTO model.TaskURLParams
+	if err := reqDTO.Parse(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !rs.ifMatch(w, r, &reqDTO) {
+		return
+	}
+
+	patch, err := util.ParsePatch(r)
+	if err != nil {
+		advertisePatch(w, err)
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	data, err := rs.repo.Patch(r.Context(), reqDTO, func(current model.TaskRequestPayload) (model.TaskRequestPayload, error) {
+		patched, err := util.PatchValue(patch, current)
+		if err != nil {
+			return patched, err
+		}
+		if err := patched.Validate(); err != nil {
+			return patched, fmt.Errorf("%w: %s", util.ErrPatchResult, err)
+		}
+		return patched, nil
+	})
+	if writeStale(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	jsonData, err := json.Marshal(data.CreateTaskResponseDto())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", data.ETag())
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonData)
+}
+
+// Transition moves a task to another status
+// @Summary Transition a task
+// @Description Move a task through its lifecycle (todo, in_progress, blocked, done, cancelled)
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Task ID"
+// @Param request body model.TaskTransitionPayload true "default"
+// @Param children query string false "what to do with open subtasks when finishing: reject, cascade or reparent" default(reject)
+// @Success 200 {object} types.JSONResult{data=model.Task}
+// @Failure 400 {string} string "error: payload is invalid or missing"
+// @Failure 404 {string} string "error: task not found"
+// @Failure 409 {string} string "error: invalid status transition"
+// @Security BearerAuth
+// @Router /tasks/{id}/transitions [post]
+func (rs TasksResource) Transition(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	var reqDTO model.TaskURLParams
+	if err := reqDTO.Parse(id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var payload model.TaskTransitionPayload
+	if err := util.ParseRequestBody(r, &payload); err != nil {
+		http.Error(w, "error: payload is invalid or missing", http.StatusBadRequest)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	policy, err := model.ParseCascadePolicy(r.URL.Query().Get("children"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := rs.repo.Transition(r.Context(), reqDTO, payload.Status, policy)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	jsonData, err := json.Marshal(data.CreateTaskResponseDto())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonData)
+}
+
+// Children returns the direct subtasks of a task
+// @Summary List subtasks
+// @Description Get the direct subtasks of a task
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Task ID"
+// @Param offset query string false "string default example" default(0) example(1)
+// @Param limit query string false "string default example" default(10) example(20)
+// @Param after query string false "cursor from next_cursor of a previous page"
+// @Param before query string false "cursor from prev_cursor of a previous page"
+// @Param count query string false "how to compute paginate.total: exact, estimate or none" default(exact)
+// @Success 200 {object} types.JSONResult{data=model.Tasks,paginate=types.Pageable,length=int}
+// @Failure 400 {string} string "error: id is invalid"
+// @Security BearerAuth
+// @Router /tasks/{id}/children [get]
+func (rs TasksResource) Children(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	var reqDTO model.TaskURLParams
+	if err := reqDTO.Parse(id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p, err := parsePageable(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := rs.repo.Children(r.Context(), reqDTO, &p)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	jsonData, err := json.Marshal(data.CreateTaskResponseDto(&p))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(jsonData)
+}
+
+// Occurrences expands the recurrence rule of a task
+// @Summary List occurrences
+// @Description Expand the RRULE of a recurring task between from and to without creating tasks
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Task ID"
+// @Param from query string false "start of the range (date or RFC 3339), defaults to now" example(2024-03-01)
+// @Param to query string false "end of the range (date or RFC 3339), defaults to one year after from" example(2024-06-01)
+// @Success 200 {object} types.JSONResult{data=[]string}
+// @Failure 400 {string} string "error: task is not recurring"
+// @Failure 404 {string} string "error: task not found"
+// @Failure 422 {string} string "error: the series has too many occurrences before the requested range"
+// @Security BearerAuth
+// @Router /tasks/{id}/occurrences [get]
+func (rs TasksResource) Occurrences(w http.ResponseWriter, r *http.Request) {
+	var reqDTO model.TaskURLParams
+	if err := reqDTO.Parse(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := model.ParseTimeRange(
+		r.URL.Query().Get("from"), r.URL.Query().Get("to"), time.Now().UTC(),
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := rs.repo.Occurrences(r.Context(), reqDTO, from, to)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	jsonData, err := json.Marshal(types.JSONResult{Code: 200, Message: "success", Data: data})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(jsonData)
+}
+
+// taskErrorStatus maps errors returned by the task repository to a status code
+func taskErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, util.ErrUnsupportedPatch):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, util.ErrPatchResult):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, model.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, util.ErrPatchConflict):
+		return http.StatusConflict
+	case errors.Is(err, util.ErrInvalidPatch):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrRecurrenceTooLong), errors.Is(err, model.ErrExecutionOrderTooLong):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, model.ErrCommentForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, repo.ErrTaskNotFound), errors.Is(err, repo.ErrDependencyNotFound),
+		errors.Is(err, repo.ErrCategoryNotFound), errors.Is(err, repo.ErrCommentNotFound),
+		errors.Is(err, repo.ErrAttachmentNotFound), errors.Is(err, storage.ErrBlobNotFound),
+		errors.Is(err, repo.ErrProjectNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrInvalidTransition), errors.Is(err, model.ErrTaskHasChildren),
+		errors.Is(err, model.ErrDependencyCycle), errors.Is(err, model.ErrParentTrashed),
+		errors.Is(err, model.ErrProjectArchived):
+		return http.StatusConflict
+	case errors.Is(err, model.ErrInvalidParent), errors.Is(err, model.ErrNotRecurring),
+		errors.Is(err, util.ErrInvalidCursor), errors.Is(err, repo.ErrUnknownCategory),
+		errors.Is(err, repo.ErrUnknownProject):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+// attachmentRoutes mounts the attachment endpoints of a task
+func (rs TasksResource) attachmentRoutes(r chi.Router) {
+	r.Get("/", rs.Attachments)
+	r.Post("/", rs.UploadAttachment)
+	r.Route("/{attachmentId}", func(r chi.Router) {
+		r.Get("/", rs.Attachment)
+		r.Get("/content", rs.DownloadAttachment)
+		r.Delete("/", rs.DeleteAttachment)
+	})
+}
+
+// parseAttachmentParams parses the task and attachment ids of an attachment route
+func parseAttachmentParams(r *http.Request) (model.TaskURLParams, int, error) {
+	var task model.TaskURLParams
+	if err := task.Parse(chi.URLParam(r, "id")); err != nil {
+		return task, 0, err
+	}
+
+	attachment, err := model.ParseParams(chi.URLParam(r, "attachmentId"))
+	return task, attachment.ID, err
+}
+
+// Attachments returns the attachments of a task
+// @Summary List attachments
+// @Description Get the metadata of the files attached to a task, oldest first
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Task ID"
+// @Param offset query string false "string default example" default(0) example(1)
+// @Param limit query string false "string default example" default(10) example(20)
+// @Param after query string false "cursor from next_cursor of a previous page"
+// @Param before query string false "cursor from prev_cursor of a previous page"
+// @Param count query string false "how to compute paginate.total: exact, estimate or none" default(exact)
+// @Success 200 {object} types.JSONResult{data=model.TaskAttachments,paginate=types.Pageable,length=int}
+// @Failure 400 {string} string "error: id is invalid"
+// @Failure 404 {string} string "error: task not found"
+// @Security BearerAuth
+// @Router /tasks/{id}/attachments [get]
+func (rs TasksResource) Attachments(w http.ResponseWriter, r *http.Request) {
+	var reqDTO model.TaskURLParams
+	if err := reqDTO.Parse(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p, err := parsePageable(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := rs.attachments.List(r.Context(), reqDTO, &p)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	jsonData, err := json.Marshal(data.ToJSON(p))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(jsonData)
+}
+
+// Attachment returns the metadata of an attachment
+// @Summary Get an attachment
+// @Description Get the metadata of a file attached to a task
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Task ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {object} types.JSONResult{data=model.TaskAttachment}
+// @Failure 400 {string} string "error: id is invalid"
+// @Failure 404 {string} string "error: attachment not found"
+// @Security BearerAuth
+// @Router /tasks/{id}/attachments/{attachmentId} [get]
+func (rs TasksResource) Attachment(w http.ResponseWriter, r *http.Request) {
+	reqDTO, attachmentID, err := parseAttachmentParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := rs.attachments.Get(r.Context(), reqDTO, attachmentID)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	writeAttachment(w, data, http.StatusOK, "success")
+}
+
+// UploadAttachment attaches a file to a task
+// @Summary Upload an attachment
+// @Description Attach a file (at most 25 MB) to a task, sent as multipart/form-data in the "file" field
+// @Tags task
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param id path string true "Task ID"
+// @Param file formData file true "the file to attach"
+// @Success 201 {object} types.JSONResult{data=model.TaskAttachment}
+// @Failure 400 {string} string "error: payload is invalid or missing"
+// @Failure 404 {string} string "error: task not found"
+// @Security BearerAuth
+// @Router /tasks/{id}/attachments [post]
+func (rs TasksResource) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	var reqDTO model.TaskURLParams
+	if err := reqDTO.Parse(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// leave room for the multipart framing around the file
+	r.Body = http.MaxBytesReader(w, r.Body, model.MaxAttachmentSize+1<<20)
+
+	var payload model.AttachmentUpload
+	err := util.ParseRequestBody(r, &payload)
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := rs.attachments.Create(r.Context(), reqDTO, payload)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	writeAttachment(w, data, http.StatusCreated, "created")
+}
+
+// DownloadAttachment returns the content of an attachment
+// @Summary Download an attachment
+// @Description Get the content of a file attached to a task. Supports Range requests and If-None-Match with the sha256 ETag
+// @Tags task
+// @Produce  octet-stream
+// @Param id path string true "Task ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Param Range header string false "byte range, e.g. bytes=0-1023"
+// @Success 200 {file} file "the file"
+// @Success 206 {file} file "the requested range"
+// @Failure 400 {string} string "error: id is invalid"
+// @Failure 404 {string} string "error: attachment not found"
+// @Security BearerAuth
+// @Router /tasks/{id}/attachments/{attachmentId}/content [get]
+func (rs TasksResource) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	reqDTO, attachmentID, err := parseAttachmentParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	attachment, content, err := rs.attachments.Open(r.Context(), reqDTO, attachmentID)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": attachment.Filename,
+	}))
+	w.Header().Set("ETag", `"`+attachment.SHA256+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	// ServeContent answers Range, If-Range and If-None-Match requests
+	http.ServeContent(w, r, attachment.Filename, attachment.CreatedAt, content)
+}
+
+// DeleteAttachment removes an attachment and its content
+// @Summary Delete an attachment
+// @Description Remove a file attached to a task
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Task ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {string} string "ok"
+// @Failure 400 {string} string "error: id is invalid"
+// @Failure 404 {string} string "error: attachment not found"
+// @Security BearerAuth
+// @Router /tasks/{id}/attachments/{attachmentId} [delete]
+func (rs TasksResource) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	reqDTO, attachmentID, err := parseAttachmentParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := rs.attachments.Delete(r.Context(), reqDTO, attachmentID); err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// collectGarbage removes the blobs of attachments deleted along with tasks
+func (rs TasksResource) collectGarbage(r *http.Request) {
+	if err := rs.attachments.CollectGarbage(r.Context()); err != nil {
+		slog.Error("failed to collect attachment blobs: " + err.Error())
+	}
+}
+
+func writeAttachment(w http.ResponseWriter, attachment model.TaskAttachment, code int, message string) {
+	jsonData, err := json.Marshal(attachment.ToJSON(code, message))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(code)
+	w.Write(jsonData)
+}
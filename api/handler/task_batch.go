@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+// Batch runs a list of task operations in one transaction
+// @Summary Create, update and delete tasks in bulk
+// @Description Run create, update and delete operations in order in a single transaction. By default the batch is all-or-nothing: the first failing operation rolls back the others, which report 424. Updates and deletes send the ETag of their task as if_match, which is checked like an If-Match header and required when the server requires If-Match. With atomic=false every operation stands on its own and the response is 207 when some of them failed. Each result carries the status code the operation would have had as a request of its own.
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param request body model.BatchPayload true "default"
+// @Param Idempotency-Key header string false "unique key of the request, a retry with the same key replays the first response"
+// @Param atomic query bool false "roll back the whole batch when an operation fails" default(true)
+// @Success 200 {object} types.JSONResult{data=model.BatchResults}
+// @Success 207 {object} types.JSONResult{data=model.BatchResults}
+// @Failure 400 {string} string "error: payload is invalid or missing"
+// @Failure 413 {string} string "error: too many operations in the batch"
+// @Security BearerAuth
+// @Router /tasks/batch [post]
+func (rs TasksResource) Batch(w http.ResponseWriter, r *http.Request) {
+	var payload model.BatchPayload
+	if err := util.ParseRequestBody(r, &payload); err != nil {
+		http.Error(w, "error: payload is invalid or missing", http.StatusBadRequest)
+		return
+	}
+
+	if err := payload.Validate(rs.options.BatchLimit); err != nil {
+		code := http.StatusBadRequest
+		if errors.Is(err, model.ErrBatchLimit) {
+			code = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	atomic := r.URL.Query().Get("atomic") != "false"
+
+	results := make(model.BatchResults, len(payload.Operations))
+	var ops []model.BatchOperation
+	var indexes []int
+	for i := range payload.Operations {
+		op := &payload.Operations[i]
+		results[i] = model.BatchResult{Index: i, Op: op.Op}
+		if err := op.Validate(rs.options.RequireIfMatch); err != nil {
+			results[i].Status = http.StatusBadRequest
+			if errors.Is(err, model.ErrBatchIfMatchRequired) {
+				results[i].Status = http.StatusPreconditionRequired
+			}
+			results[i].Error = err.Error()
+			continue
+		}
+		ops = append(ops, *op)
+		indexes = append(indexes, i)
+	}
+
+	if atomic && len(ops) < len(results) {
+		skipBatch(results, "error: not run because another operation is invalid")
+		writeBatch(w, results, http.StatusBadRequest, "error: the batch has invalid operations")
+		return
+	}
+
+	outcomes, err := rs.repo.Batch(r.Context(), ops, atomic)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	failed, deleted := -1, false
+	for k, outcome := range outcomes {
+		result := &results[indexes[k]]
+		switch {
+		case outcome.Err != nil:
+			result.Status = taskErrorStatus(outcome.Err)
+			result.Error = outcome.Err.Error()
+			if failed < 0 {
+				failed = indexes[k]
+			}
+		case atomic && failed >= 0:
+			// not run after the failure
+		case result.Op == model.BatchCreate:
+			result.Status = http.StatusCreated
+			result.Data = outcome.Task
+		default:
+			result.Status = http.StatusOK
+			result.Data = outcome.Task
+			deleted = deleted || result.Op == model.BatchDelete
+		}
+	}
+
+	if atomic && failed >= 0 {
+		code := results[failed].Status
+		skipBatch(results, fmt.Sprintf("error: rolled back because operation %d failed", failed))
+		writeBatch(w, results, code, results[failed].Error)
+		return
+	}
+
+	if deleted {
+		rs.collectGarbage(r)
+	}
+
+	if failed >= 0 {
+		writeBatch(w, results, http.StatusMultiStatus, "some operations failed")
+		return
+	}
+	writeBatch(w, results, http.StatusOK, "success")
+}
+
+// skipBatch marks every operation that did not fail as not applied
+func skipBatch(results model.BatchResults, message string) {
+	for i := range results {
+		if results[i].Error == "" {
+			results[i].Status = http.StatusFailedDependency
+			results[i].Data = nil
+			results[i].Error = message
+		}
+	}
+}
+
+func writeBatch(w http.ResponseWriter, results model.BatchResults, code int, message string) {
+	jsonData, err := json.Marshal(results.ToJSON(code, message))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(code)
+	w.Write(jsonData)
+}
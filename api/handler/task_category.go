@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+)
+
+// ListByCategory returns the tasks tagged with a category
+// @Summary List tasks of a category
+// @Description Get the tasks tagged with a category
+// @Tags category
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Category ID"
+// @Param offset query string false "string default example" default(0) example(1)
+// @Param limit query string false "string default example" default(10) example(20)
+// @Param after query string false "cursor from next_cursor of a previous page"
+// @Param before query string false "cursor from prev_cursor of a previous page"
+// @Param count query string false "how to compute paginate.total: exact, estimate or none" default(exact)
+// @Param status query string false "comma separated statuses, or open for unfinished tasks" example(open)
+// @Param filter query string false "filter expression, e.g. priority>=2" example(priority>=2)
+// @Param sort query string false "comma separated fields, prefix with - for descending" example(-priority,date)
+// @Success 200 {object} types.JSONResult{data=model.Tasks,paginate=types.Pageable,length=int}
+// @Failure 400 {string} string "error: id is invalid"
+// @Failure 404 {string} string "error: category not found"
+// @Security BearerAuth
+// @Router /categories/{id}/tasks [get]
+func (rs TasksResource) ListByCategory(w http.ResponseWriter, r *http.Request) {
+	params, err := model.ParseParams(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p, err := parsePageable(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	statuses, err := model.ParseTaskStatuses(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	expr, sort, err := parseListQuery(r, model.TaskFields)
+	if err != nil {
+		writeQueryError(w, err)
+		return
+	}
+
+	filter := model.TaskFilter{Statuses: statuses, Expr: expr, Sort: sort}
+	data, err := rs.repo.ListByCategory(r.Context(), params, &p, filter)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	jsonData, err := json.Marshal(data.CreateTaskResponseDto(&p))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(jsonData)
+}
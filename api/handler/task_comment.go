@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+// commentRoutes mounts the comment endpoints of a task
+func (rs TasksResource) commentRoutes(r chi.Router) {
+	r.Get("/", rs.Comments)
+	r.Post("/", rs.AddComment)
+	r.Route("/{commentId}", func(r chi.Router) {
+		r.Get("/", rs.Comment)
+		r.Put("/", rs.EditComment)
+		r.Delete("/", rs.DeleteComment)
+	})
+}
+
+// parseCommentParams parses the task and comment ids of a comment route
+func parseCommentParams(r *http.Request) (model.TaskURLParams, int, error) {
+	var task model.TaskURLParams
+	if err := task.Parse(chi.URLParam(r, "id")); err != nil {
+		return task, 0, err
+	}
+
+	comment, err := model.ParseParams(chi.URLParam(r, "commentId"))
+	return task, comment.ID, err
+}
+
+// Comments returns the comments of a task
+// @Summary List comments
+// @Description Get the comments of a task, oldest first. Deleted comments keep their place with the body "[deleted]"
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Task ID"
+// @Param offset query string false "string default example" default(0) example(1)
+// @Param limit query string false "string default example" default(10) example(20)
+// @Param after query string false "cursor from next_cursor of a previous page"
+// @Param before query string false "cursor from prev_cursor of a previous page"
+// @Param count query string false "how to compute paginate.total: exact, estimate or none" default(exact)
+// @Success 200 {object} types.JSONResult{data=model.TaskComments,paginate=types.Pageable,length=int}
+// @Failure 400 {string} string "error: id is invalid"
+// @Failure 404 {string} string "error: task not found"
+// @Security BearerAuth
+// @Router /tasks/{id}/comments [get]
+func (rs TasksResource) Comments(w http.ResponseWriter, r *http.Request) {
+	var reqDTO model.TaskURLParams
+	if err := reqDTO.Parse(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p, err := parsePageable(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := rs.repo.Comments(r.Context(), reqDTO, &p)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	jsonData, err := json.Marshal(data.ToJSON(p))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(jsonData)
+}
+
+// Comment returns a comment of a task
+// @Summary Get a comment
+// @Description Get a comment of a task
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Task ID"
+// @Param commentId path string true "Comment ID"
+// @Success 200 {object} types.JSONResult{data=model.TaskComment}
+// @Failure 400 {string} string "error: id is invalid"
+// @Failure 404 {string} string "error: comment not found"
+// @Security BearerAuth
+// @Router /tasks/{id}/comments/{commentId} [get]
+func (rs TasksResource) Comment(w http.ResponseWriter, r *http.Request) {
+	reqDTO, commentID, err := parseCommentParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := rs.repo.Comment(r.Context(), reqDTO, commentID)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	writeComment(w, data, http.StatusOK, "success")
+}
+
+// AddComment posts a comment on a task
+// @Summary Add a comment
+// @Description Post a comment on a task as the authenticated user
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Task ID"
+// @Param request body model.TaskCommentPayload true "default"
+// @Success 201 {object} types.JSONResult{data=model.TaskComment}
+// @Failure 400 {string} string "error: payload is invalid or missing"
+// @Failure 404 {string} string "error: task not found"
+// @Security BearerAuth
+// @Router /tasks/{id}/comments [post]
+func (rs TasksResource) AddComment(w http.ResponseWriter, r *http.Request) {
+	var reqDTO model.TaskURLParams
+	if err := reqDTO.Parse(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var payload model.TaskCommentPayload
+	if err := util.ParseRequestBody(r, &payload); err != nil {
+		http.Error(w, "error: payload is invalid or missing", http.StatusBadRequest)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := rs.repo.AddComment(r.Context(), reqDTO, payload)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	writeComment(w, data, http.StatusCreated, "created")
+}
+
+// EditComment replaces the body of a comment
+// @Summary Edit a comment
+// @Description Replace the body of a comment, recording when it was edited. Only its author or an owner of the workspace can edit it. Deleted comments cannot be edited
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Task ID"
+// @Param commentId path string true "Comment ID"
+// @Param request body model.TaskCommentUpdatePayload true "default"
+// @Success 200 {object} types.JSONResult{data=model.TaskComment}
+// @Failure 400 {string} string "error: payload is invalid or missing"
+// @Failure 403 {string} string "error: only the author of a comment or an owner of the workspace can change it"
+// @Failure 404 {string} string "error: comment not found"
+// @Security BearerAuth
+// @Router /tasks/{id}/comments/{commentId} [put]
+func (rs TasksResource) EditComment(w http.ResponseWriter, r *http.Request) {
+	reqDTO, commentID, err := parseCommentParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var payload model.TaskCommentUpdatePayload
+	if err := util.ParseRequestBody(r, &payload); err != nil {
+		http.Error(w, "error: payload is invalid or missing", http.StatusBadRequest)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := rs.repo.EditComment(r.Context(), reqDTO, commentID, payload.Body)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	writeComment(w, data, http.StatusOK, "success")
+}
+
+// DeleteComment soft-deletes a comment
+// @Summary Delete a comment
+// @Description Delete a comment; it stays in the thread with the body "[deleted]". Only its author or an owner of the workspace can delete it
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Task ID"
+// @Param commentId path string true "Comment ID"
+// @Success 200 {string} string "ok"
+// @Failure 400 {string} string "error: id is invalid"
+// @Failure 403 {string} string "error: only the author of a comment or an owner of the workspace can change it"
+// @Failure 404 {string} string "error: comment not found"
+// @Security BearerAuth
+// @Router /tasks/{id}/comments/{commentId} [delete]
+func (rs TasksResource) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	reqDTO, commentID, err := parseCommentParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := rs.repo.DeleteComment(r.Context(), reqDTO, commentID); err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func writeComment(w http.ResponseWriter, comment model.TaskComment, code int, message string) {
+	jsonData, err := json.Marshal(comment.ToJSON(code, message))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(code)
+	w.Write(jsonData)
+}
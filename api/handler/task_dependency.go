@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+// Dependencies returns the tasks blocking a task
+// @Summary List dependencies
+// @Description Get the tasks a task is blocked by
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Task ID"
+// @Success 200 {object} types.JSONResult{data=model.Tasks}
+// @Failure 400 {string} string "error: id is invalid"
+// @Security BearerAuth
+// @Router /tasks/{id}/dependencies [get]
+func (rs TasksResource) Dependencies(w http.ResponseWriter, r *http.Request) {
+	var reqDTO model.TaskURLParams
+	if err := reqDTO.Parse(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := rs.repo.Dependencies(r.Context(), reqDTO)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	jsonData, err := json.Marshal(data.CreateTaskResponseDto(nil))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(jsonData)
+}
+
+// AddDependency marks a task as blocked by another task
+// @Summary Add a dependency
+// @Description Mark a task as blocked by another task, rejecting edges that would create a cycle
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Task ID"
+// @Param request body model.TaskDependencyPayload true "default"
+// @Success 201 {object} types.JSONResult{data=model.TaskDependency}
+// @Failure 400 {string} string "error: payload is invalid or missing"
+// @Failure 404 {string} string "error: task not found"
+// @Failure 409 {string} string "error: dependency would create a cycle"
+// @Security BearerAuth
+// @Router /tasks/{id}/dependencies [post]
+func (rs TasksResource) AddDependency(w http.ResponseWriter, r *http.Request) {
+	var reqDTO model.TaskURLParams
+	if err := reqDTO.Parse(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var payload model.TaskDependencyPayload
+	if err := util.ParseRequestBody(r, &payload); err != nil {
+		http.Error(w, "error: payload is invalid or missing", http.StatusBadRequest)
+		return
+	}
+
+	if err := payload.Validate(reqDTO.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := rs.repo.AddDependency(r.Context(), reqDTO, payload.DependsOnID)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	jsonData, err := json.Marshal(data.CreateTaskDependencyResponseDto(201, "Created"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(jsonData)
+}
+
+// RemoveDependency removes a dependency between two tasks
+// @Summary Remove a dependency
+// @Description Stop a task from being blocked by another task
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Task ID"
+// @Param dependsOnId path string true "ID of the blocking task"
+// @Success 200 {string} string "ok"
+// @Failure 400 {string} string "error: id is invalid"
+// @Failure 404 {string} string "error: dependency not found"
+// @Security BearerAuth
+// @Router /tasks/{id}/dependencies/{dependsOnId} [delete]
+func (rs TasksResource) RemoveDependency(w http.ResponseWriter, r *http.Request) {
+	var reqDTO, dependsOn model.TaskURLParams
+	if err := reqDTO.Parse(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := dependsOn.Parse(chi.URLParam(r, "dependsOnId")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := rs.repo.RemoveDependency(r.Context(), reqDTO, dependsOn.ID); err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ExecutionOrder returns tasks sorted so that blockers come first
+// @Summary Tasks in executable order
+// @Description Get tasks in topological order: every task comes after the tasks it depends on. The open tasks blocking the listed ones are listed too, even when they do not match status. At most 1000 tasks are ordered.
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param status query string false "comma separated statuses, or open for unfinished tasks" example(open)
+// @Success 200 {object} types.JSONResult{data=model.Tasks}
+// @Failure 400 {string} string "error: unknown status"
+// @Failure 409 {string} string "error: dependency would create a cycle"
+// @Failure 422 {string} string "error: too many tasks to order"
+// @Security BearerAuth
+// @Router /tasks/order [get]
+func (rs TasksResource) ExecutionOrder(w http.ResponseWriter, r *http.Request) {
+	statuses, err := model.ParseTaskStatuses(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := rs.repo.ExecutionOrder(r.Context(), model.TaskFilter{Statuses: statuses})
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	jsonData, err := json.Marshal(data.CreateTaskResponseDto(nil))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(jsonData)
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+)
+
+// Export streams the tasks as CSV or JSON Lines
+// @Summary Export tasks as CSV or JSON Lines
+// @Description Stream the tasks matching the filters of the task list, without pagination. CSV has a header row and lists the labels of the categories separated by commas; JSON Lines has one task object per line. Both can be imported back with POST /tasks/import.
+// @Tags task
+// @Produce  text/csv
+// @Produce  application/jsonl
+// @Param format query string false "csv or jsonl" default(csv)
+// @Param status query string false "comma separated statuses, or open for unfinished tasks" example(open)
+// @Param category query string false "comma separated category ids, keeps tasks tagged with any of them" example(1,2)
+// @Param project query string false "project id, keeps the tasks of the project" example(1)
+// @Param filter query string false "filter expression, e.g. priority>=2 and date<2024-04-01" example(priority>=2 and date<2024-04-01)
+// @Param sort query string false "comma separated fields, prefix with - for descending" example(date)
+// @Success 200 {string} string "the file"
+// @Failure 400 {object} types.JSONError "error: invalid format, filter or sort"
+// @Security BearerAuth
+// @Router /tasks/export [get]
+func (rs TasksResource) Export(w http.ResponseWriter, r *http.Request) {
+	extendWriteDeadline(w)
+
+	format, err := model.ParseTaskFileFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter, ok := parseTaskFilter(w, r)
+	if !ok {
+		return
+	}
+
+	var write func(model.Task) error
+	header := func() error { return nil }
+	flush := func() error { return nil }
+	if format == model.FormatJSONL {
+		encoder := json.NewEncoder(w)
+		write = func(task model.Task) error { return encoder.Encode(task) }
+	} else {
+		writer := csv.NewWriter(w)
+		write = func(task model.Task) error { return writer.Write(task.CSVRecord()) }
+		header = func() error { return writer.Write(model.TaskCSVHeader) }
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	}
+
+	// nothing is written before the first task, so that a failing query can still be answered with an error
+	started := false
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", `attachment; filename="tasks.`+string(format)+`"`)
+		w.WriteHeader(http.StatusOK)
+		return header()
+	}
+
+	err = rs.repo.Stream(r.Context(), filter, func(task model.Task) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return write(task)
+	})
+	if err != nil && !started {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		slog.Error("failed to write the export: " + err.Error())
+	}
+}
+
+// Import upserts the tasks of a CSV or JSON Lines file
+// @Summary Import tasks from CSV or JSON Lines
+// @Description Upsert the rows of a CSV file with a header row, or the objects of a JSON Lines file, sent as the "file" field of a multipart/form-data body or as the whole body. Rows are read by column name: id (updates that task), external_id (matches the task imported with it, creates it otherwise), title, priority, date, status, completed_at, parent_id, parent_external_id, project_id (keeps the current project when empty), recurrence and categories (labels separated by commas, created when no category has them); other columns are ignored. title and date are required; dates are RFC 3339 timestamps or plain dates. Rows that do not validate are skipped and reported with their line and reason. New tasks are created in bulk.
+// @Tags task
+// @Accept  multipart/form-data
+// @Accept  text/csv
+// @Accept  application/jsonl
+// @Produce  json
+// @Param file formData file false "the file"
+// @Param format query string false "csv or jsonl, by default that of the Content-Type, else csv" example(csv)
+// @Param mapping query string false "comma separated field=column pairs, for files whose columns are named differently" example(title=Task name,date=Due date)
+// @Param dry_run query bool false "report what the import would do without saving anything"
+// @Success 200 {object} types.JSONResult{data=model.ImportReport}
+// @Failure 400 {string} string "error: invalid file or mapping"
+// @Failure 413 {string} string "error: file is too large"
+// @Failure 415 {string} string "error: Unsupported Content-Type"
+// @Security BearerAuth
+// @Router /tasks/import [post]
+func (rs TasksResource) Import(w http.ResponseWriter, r *http.Request) {
+	extendReadDeadline(w)
+	extendWriteDeadline(w)
+
+	query := r.URL.Query()
+	format := model.TaskFileFormat(query.Get("format"))
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		format = model.TaskFileFormatOf(mediaType)
+	}
+	format, err := model.ParseTaskFileFormat(string(format))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mapping, err := model.ParseColumnMapping(query.Get("mapping"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	file, err := openImport(w, r, format.MediaTypes()...)
+	if err != nil {
+		http.Error(w, err.Error(), importErrorStatus(err))
+		return
+	}
+	defer file.Close()
+
+	var items []model.ImportedTask
+	if format == model.FormatJSONL {
+		items, err = model.TasksFromJSONL(file, mapping)
+	} else {
+		items, err = model.TasksFromCSV(file, mapping)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), importErrorStatus(err))
+		return
+	}
+
+	rs.writeImport(w, r, items)
+}
+
+// fileTimeout is how long a file may take to be uploaded, or an export or
+// import to be answered, where the server timeouts are meant for JSON requests
+const fileTimeout = 5 * time.Minute
+
+// extendReadDeadline gives the rest of the request body fileTimeout to arrive
+func extendReadDeadline(w http.ResponseWriter) {
+	if err := http.NewResponseController(w).SetReadDeadline(time.Now().Add(fileTimeout)); err != nil {
+		slog.Error("failed to extend the read deadline: " + err.Error())
+	}
+}
+
+// extendWriteDeadline gives the response fileTimeout to be written
+func extendWriteDeadline(w http.ResponseWriter) {
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(fileTimeout)); err != nil {
+		slog.Error("failed to extend the write deadline: " + err.Error())
+	}
+}
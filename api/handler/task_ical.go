@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Kbgjtn/notethingness-api.git/ical"
+)
+
+// ICalendar returns the tasks as an iCalendar feed
+// @Summary Export tasks as iCalendar
+// @Description Render the tasks as an RFC 5545 calendar of VTODO components, to subscribe to from calendar apps. Takes the filters of the task list, without pagination. PRIORITY is 10 - priority, clamped to 1 (highest) to 9, and 0 for priorities below 1. Calendar apps that cannot send an Authorization header can subscribe with an API key in the token query parameter; give that key only the tasks:read scope, as URLs end up in logs.
+// @Tags task
+// @Produce  text/calendar
+// @Param status query string false "comma separated statuses, or open for unfinished tasks" example(open)
+// @Param category query string false "comma separated category ids, keeps tasks tagged with any of them" example(1,2)
+// @Param project query string false "project id, keeps the tasks of the project" example(1)
+// @Param filter query string false "filter expression, e.g. priority>=2 and date<2024-04-01" example(priority>=2 and date<2024-04-01)
+// @Param sort query string false "comma separated fields, prefix with - for descending" example(date)
+// @Param token query string false "API key with the tasks:read scope, instead of the Authorization header"
+// @Success 200 {string} string "the calendar"
+// @Failure 400 {object} types.JSONError "error: invalid filter or sort"
+// @Failure 401 {object} types.JSONError "error: \"token\" must be an API key"
+// @Security BearerAuth
+// @Router /tasks.ics [get]
+func (rs TasksResource) ICalendar(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseTaskFilter(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := rs.repo.Export(r.Context(), filter)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="tasks.ics"`)
+	if err := ical.Encode(w, data.ICalendar("Tasks")); err != nil {
+		slog.Error("failed to write the calendar: " + err.Error())
+	}
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/importer"
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+)
+
+func (rs TasksResource) ImportRoutes(route chi.Router) {
+	route.Post("/{format}", rs.ImportFrom)
+}
+
+// ImportFrom upserts the tasks of a file exported by another tool
+// @Summary Import tasks from another tool
+// @Description Read the export of another tool, sent as the "file" field of a multipart/form-data body or as the whole body, and upsert its tasks. Formats: ics (iCalendar VTODO and VEVENT), todoist (JSON backup), trello (board JSON export) and todotxt (todo.txt). Tasks are matched to the tasks they were imported as before and created when new; ics tasks exported by /tasks.ics update their task. Labels, projects and lists become categories, created when no category has that label. Items that cannot be mapped are skipped and listed with the reason.
+// @Tags task
+// @Accept  multipart/form-data
+// @Accept  text/calendar
+// @Accept  application/json
+// @Accept  text/plain
+// @Produce  json
+// @Param format path string true "ics, todoist, trello or todotxt"
+// @Param file formData file false "the file"
+// @Param dry_run query bool false "report what the import would do without saving anything"
+// @Success 200 {object} types.JSONResult{data=model.ImportReport}
+// @Failure 400 {string} string "error: invalid file"
+// @Failure 404 {string} string "error: unknown format"
+// @Failure 413 {string} string "error: file is too large"
+// @Failure 415 {string} string "error: Unsupported Content-Type"
+// @Security BearerAuth
+// @Router /import/{format} [post]
+func (rs TasksResource) ImportFrom(w http.ResponseWriter, r *http.Request) {
+	format := chi.URLParam(r, "format")
+	adapter, ok := importer.Lookup(format)
+	if !ok {
+		message := fmt.Sprintf("error: unknown format %q, expected one of %s", format, strings.Join(importer.Formats(), ", "))
+		http.Error(w, message, http.StatusNotFound)
+		return
+	}
+
+	file, err := openImport(w, r, adapter.MediaTypes()...)
+	if err != nil {
+		http.Error(w, err.Error(), importErrorStatus(err))
+		return
+	}
+	defer file.Close()
+
+	items, err := adapter.Read(file)
+	if err != nil {
+		http.Error(w, err.Error(), importErrorStatus(err))
+		return
+	}
+
+	rs.writeImport(w, r, items)
+}
+
+// writeImport upserts imported tasks, or only reports what would happen with
+// the dry_run query parameter, and responds with the report
+func (rs TasksResource) writeImport(w http.ResponseWriter, r *http.Request, items []model.ImportedTask) {
+	if len(items) == 0 {
+		http.Error(w, "error: the file has no tasks", http.StatusBadRequest)
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	report, err := rs.repo.Import(r.Context(), items, dryRun)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	jsonData, err := json.Marshal(report.ToJSON())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonData)
+}
+
+var errUnsupportedImport = errors.New("error: Unsupported Content-Type")
+
+// openImport returns the file of an import, either the "file" field of a
+// multipart/form-data body or the whole body when it has one of mediaTypes.
+// Both are limited to model.MaxImportSize.
+func openImport(w http.ResponseWriter, r *http.Request, mediaTypes ...string) (io.ReadCloser, error) {
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errUnsupportedImport, contentType)
+	}
+
+	if mediaType != "multipart/form-data" {
+		for _, accepted := range mediaTypes {
+			if mediaType == accepted {
+				r.Body = http.MaxBytesReader(w, r.Body, model.MaxImportSize)
+				return r.Body, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", errUnsupportedImport, contentType)
+	}
+
+	// leave room for the multipart framing around the file
+	r.Body = http.MaxBytesReader(w, r.Body, model.MaxImportSize+1<<20)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("error: invalid multipart body: %w", err)
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("error: \"file\" is required")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error: invalid multipart body: %w", err)
+		}
+		if part.FormName() == "file" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
+
+func importErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, errUnsupportedImport):
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusBadRequest
+	}
+}
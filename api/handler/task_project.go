@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+// ListByProject returns the tasks of a project
+// @Summary List tasks of a project
+// @Description Get the tasks of a project, archived or not
+// @Tags project
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Project ID"
+// @Param offset query string false "string default example" default(0) example(1)
+// @Param limit query string false "string default example" default(10) example(20)
+// @Param after query string false "cursor from next_cursor of a previous page"
+// @Param before query string false "cursor from prev_cursor of a previous page"
+// @Param count query string false "how to compute paginate.total: exact, estimate or none" default(exact)
+// @Param status query string false "comma separated statuses, or open for unfinished tasks" example(open)
+// @Param category query string false "comma separated category ids, keeps tasks tagged with any of them" example(1,2)
+// @Param filter query string false "filter expression, e.g. priority>=2" example(priority>=2)
+// @Param sort query string false "comma separated fields, prefix with - for descending" example(-priority,date)
+// @Success 200 {object} types.JSONResult{data=model.Tasks,paginate=types.Pageable,length=int}
+// @Failure 400 {string} string "error: id is invalid"
+// @Failure 404 {string} string "error: project not found"
+// @Security BearerAuth
+// @Router /projects/{id}/tasks [get]
+func (rs TasksResource) ListByProject(w http.ResponseWriter, r *http.Request) {
+	params, err := model.ParseParams(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p, err := parsePageable(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter, ok := parseTaskFilter(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := rs.repo.ListByProject(r.Context(), params, &p, filter)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	jsonData, err := json.Marshal(data.CreateTaskResponseDto(&p))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(jsonData)
+}
+
+// MoveToProject moves tasks into a project
+// @Summary Move tasks into a project
+// @Description Move tasks into a project along with their subtasks, in one transaction.
+// @Description A moved subtask whose parent stays in another project is detached from it.
+// @Tags project
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Project ID"
+// @Param request body model.MoveTasksPayload true "tasks to move"
+// @Success 200 {object} types.JSONResult{data=model.Tasks}
+// @Failure 400 {string} string "error: id, task_ids or project is invalid"
+// @Failure 404 {string} string "error: task not found"
+// @Failure 409 {string} string "error: project is archived"
+// @Security BearerAuth
+// @Router /projects/{id}/tasks [post]
+func (rs TasksResource) MoveToProject(w http.ResponseWriter, r *http.Request) {
+	params, err := model.ParseParams(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var payload model.MoveTasksPayload
+	if err := util.ParseRequestBody(r, &payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := rs.repo.MoveToProject(r.Context(), params, payload.TaskIDs)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	jsonData, err := json.Marshal(data.CreateTaskResponseDto(nil))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(jsonData)
+}
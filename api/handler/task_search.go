@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+)
+
+// Search runs a full-text search over tasks
+// @Summary Search tasks
+// @Description Full-text search over task titles, ranked by relevance with highlighted snippets.
+// @Description Words are combined with AND; use "quotes" for phrases, word* for prefixes, "or" between words and -word to exclude.
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param q query string true "search query" example("call john" email*)
+// @Param status query string false "comma separated statuses, or open for unfinished tasks" example(open)
+// @Param project query string false "project id, keeps the tasks of the project" example(1)
+// @Param offset query string false "string default example" default(0) example(1)
+// @Param limit query string false "string default example" default(10) example(20)
+// @Param after query string false "cursor from next_cursor of a previous page"
+// @Param before query string false "cursor from prev_cursor of a previous page"
+// @Param count query string false "how to compute paginate.total: exact, estimate or none" default(exact)
+// @Success 200 {object} types.JSONResultWithPaginate{data=model.TaskSearchResults}
+// @Failure 400 {string} string "error: search query \"q\" is required"
+// @Security BearerAuth
+// @Router /tasks/search [get]
+func (rs TasksResource) Search(w http.ResponseWriter, r *http.Request) {
+	tsquery, err := model.ParseSearchQuery(r.URL.Query().Get("q"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	statuses, err := model.ParseTaskStatuses(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	projectID, err := model.ParseProjectID(r.URL.Query().Get("project"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p, err := parsePageable(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := rs.repo.Search(r.Context(), tsquery, &p, model.TaskFilter{Statuses: statuses, ProjectID: projectID})
+	if err != nil {
+		w.WriteHeader(taskErrorStatus(err))
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	jsonData, err := json.Marshal(data.ToJSON(p))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(jsonData)
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+// Trash returns the tasks in the trash
+// @Summary List the trash
+// @Description Get the tasks in the trash, most recently deleted first. They are purged once the retention period has passed
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param offset query string false "string default example" default(0) example(1)
+// @Param limit query string false "string default example" default(10) example(20)
+// @Param after query string false "cursor from next_cursor of a previous page"
+// @Param before query string false "cursor from prev_cursor of a previous page"
+// @Param count query string false "how to compute paginate.total: exact, estimate or none" default(exact)
+// @Param filter query string false "filter expression, e.g. deleted_at>=2024-03-01" example(deleted_at>=2024-03-01)
+// @Param sort query string false "comma separated fields, prefix with - for descending" default(-deleted_at)
+// @Success 200 {object} types.JSONResult{data=model.Tasks,paginate=types.Pageable,length=int}
+// @Failure 400 {object} types.JSONError "error: invalid filter or sort"
+// @Security BearerAuth
+// @Router /trash [get]
+func (rs TasksResource) Trash(w http.ResponseWriter, r *http.Request) {
+	p, err := parsePageable(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	expr, sort, err := parseListQuery(r, model.TaskFields)
+	if err != nil {
+		writeQueryError(w, err)
+		return
+	}
+
+	if sort == nil {
+		sort = []util.SortField{{Field: "deleted_at", Desc: true}}
+	}
+
+	data, err := rs.repo.List(r.Context(), &p, model.TaskFilter{Trashed: true, Expr: expr, Sort: sort})
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	jsonData, err := json.Marshal(data.CreateTaskResponseDto(&p))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(jsonData)
+}
+
+// Restore takes a task out of the trash
+// @Summary Restore a task
+// @Description Take a task out of the trash, with the subtasks that were deleted along with it
+// @Tags task
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Task ID"
+// @Success 200 {object} types.JSONResult{data=model.Task}
+// @Failure 400 {string} string "error: id is invalid"
+// @Failure 404 {string} string "error: task not found"
+// @Failure 409 {string} string "error: the parent task is in the trash, restore it first"
+// @Security BearerAuth
+// @Router /tasks/{id}/restore [post]
+func (rs TasksResource) Restore(w http.ResponseWriter, r *http.Request) {
+	var reqDTO model.TaskURLParams
+	if err := reqDTO.Parse(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := rs.repo.Restore(r.Context(), reqDTO)
+	if err != nil {
+		http.Error(w, err.Error(), taskErrorStatus(err))
+		return
+	}
+
+	jsonData, err := json.Marshal(data.CreateTaskResponseDto())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonData)
+}
@@ -0,0 +1,312 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/api/repository"
+	"github.com/Kbgjtn/notethingness-api.git/types"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+// Workspace scopes requests to a workspace: the one of the X-Workspace-ID
+// header, or else the one the access token was issued for or the API key is
+// bound to. The user must be a member of it, and an API key only works in
+// its own workspace. Must come after Authenticate.
+func Workspace(workspaces *repository.WorkspaceRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, _ := types.PrincipalFrom(r.Context())
+
+			workspaceID, err := model.ParseWorkspaceID(r.Header.Get(model.WorkspaceHeader))
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			switch {
+			case workspaceID == 0:
+				workspaceID = principal.WorkspaceID
+			case principal.KeyID != 0 && workspaceID != principal.WorkspaceID:
+				writeError(w, http.StatusForbidden, model.ErrAPIKeyWorkspace.Error())
+				return
+			}
+
+			if workspaceID == 0 {
+				writeError(w, http.StatusBadRequest, model.ErrWorkspaceRequired.Error())
+				return
+			}
+
+			// membership is checked on every request, removed members lose
+			// access right away rather than when their token expires
+			role, err := workspaces.Role(r.Context(), workspaceID, principal.UserID)
+			if errors.Is(err, repository.ErrWorkspaceNotFound) {
+				writeError(w, http.StatusForbidden, fmt.Sprintf("error: not a member of workspace %d", workspaceID))
+				return
+			}
+			if err != nil {
+				slog.Error(err.Error())
+				writeError(w, http.StatusInternalServerError, "error: failed to check the workspace")
+				return
+			}
+
+			ctx := types.WithWorkspaceRole(types.WithWorkspace(r.Context(), workspaceID), role)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+type WorkspaceResource struct {
+	repo *repository.WorkspaceRepository
+}
+
+func NewWorkspace(repo *repository.WorkspaceRepository) *WorkspaceResource {
+	return &WorkspaceResource{repo}
+}
+
+func (rs WorkspaceResource) Routes(route chi.Router) {
+	route.Use(RequireUser)
+	route.Get("/", rs.List)
+	route.Post("/", rs.Create)
+	route.Get("/{id}/members", rs.Members)
+	route.Post("/{id}/members", rs.AddMember)
+	route.Delete("/{id}/members/{user_id}", rs.RemoveMember)
+}
+
+// List returns the workspaces of the user
+// @Summary List workspaces
+// @Description Get the workspaces the user is a member of, with their role in each. Requests pick one with the X-Workspace-ID header.
+// @Tags workspace
+// @Produce json
+// @Success 200 {object} types.JSONResult{data=model.Workspaces}
+// @Failure 403 {object} types.JSONError "Forbidden: API keys cannot manage workspaces"
+// @Security BearerAuth
+// @Router /workspaces [get]
+// !curl localhost:3000/api/workspaces -H "Authorization: Bearer $TOKEN" | jq
+func (rs WorkspaceResource) List(w http.ResponseWriter, r *http.Request) {
+	principal, _ := types.PrincipalFrom(r.Context())
+
+	workspaces, err := rs.repo.List(r.Context(), principal.UserID)
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	data, err := json.Marshal(workspaces.ToJSON())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "error: failed to marshal workspaces")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// Create creates a workspace
+// @Summary Create a workspace
+// @Description Create a workspace owned by the user. Its tasks, categories and projects are only seen by its members.
+// @Tags workspace
+// @Accept json
+// @Produce json
+// @Param request body model.WorkspaceRequestPayload true "default"
+// @Success 201 {object} types.JSONResult{data=model.Workspace}
+// @Failure 400 {object} types.JSONError "Bad Request: name is invalid"
+// @Failure 403 {object} types.JSONError "Forbidden: API keys cannot manage workspaces"
+// @Security BearerAuth
+// @Router /workspaces [post]
+// !curl -v 'POST' localhost:3000/api/workspaces -d '{"name":"Acme"}' -H "Content-Type: application/json" -H "Authorization: Bearer $TOKEN" | jq
+func (rs WorkspaceResource) Create(w http.ResponseWriter, r *http.Request) {
+	var payload model.WorkspaceRequestPayload
+	if err := util.ParseRequestBody(r, &payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	principal, _ := types.PrincipalFrom(r.Context())
+	workspace, err := rs.repo.Create(r.Context(), principal.UserID, payload)
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	data, err := json.Marshal(workspace.ToJSON(201, "Created"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "error: failed to marshal workspace")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(data)
+}
+
+// Members returns the members of a workspace
+// @Summary List members
+// @Description Get the members of a workspace the user is a member of
+// @Tags workspace
+// @Produce json
+// @Param id path string true "Workspace ID"
+// @Success 200 {object} types.JSONResult{data=model.Members}
+// @Failure 400 {object} types.JSONError "Bad Request: id is invalid or missing"
+// @Failure 404 {object} types.JSONError "Not Found: workspace not found"
+// @Security BearerAuth
+// @Router /workspaces/{id}/members [get]
+func (rs WorkspaceResource) Members(w http.ResponseWriter, r *http.Request) {
+	args, ok := rs.member(w, r, false)
+	if !ok {
+		return
+	}
+
+	members, err := rs.repo.Members(r.Context(), args.ID)
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	data, err := json.Marshal(members.ToJSON())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "error: failed to marshal members")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// AddMember adds a user to a workspace
+// @Summary Add a member
+// @Description Add a registered user to a workspace, as a member unless role is owner. Only owners can add members.
+// @Tags workspace
+// @Accept json
+// @Produce json
+// @Param id path string true "Workspace ID"
+// @Param request body model.MemberPayload true "default"
+// @Success 201 {object} types.JSONResult{data=model.Member}
+// @Failure 400 {object} types.JSONError "Bad Request: id, email or role is invalid"
+// @Failure 403 {object} types.JSONError "Forbidden: only owners can manage members"
+// @Failure 404 {object} types.JSONError "Not Found: workspace or user not found"
+// @Failure 409 {object} types.JSONError "Conflict: the user is already a member"
+// @Security BearerAuth
+// @Router /workspaces/{id}/members [post]
+// !curl -v 'POST' localhost:3000/api/workspaces/2/members -d '{"email":"john@example.com"}' -H "Content-Type: application/json" -H "Authorization: Bearer $TOKEN" | jq
+func (rs WorkspaceResource) AddMember(w http.ResponseWriter, r *http.Request) {
+	args, ok := rs.member(w, r, true)
+	if !ok {
+		return
+	}
+
+	var payload model.MemberPayload
+	if err := util.ParseRequestBody(r, &payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	member, err := rs.repo.AddMember(r.Context(), args.ID, payload)
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	data, err := json.Marshal(member.ToJSON(201, "Created"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "error: failed to marshal member")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(data)
+}
+
+// RemoveMember takes a user out of a workspace
+// @Summary Remove a member
+// @Description Remove a member from a workspace and revoke their API keys for it. Only owners can remove members, owners cannot be removed.
+// @Tags workspace
+// @Produce json
+// @Param id path string true "Workspace ID"
+// @Param user_id path string true "User ID"
+// @Success 200 {string} string "Success"
+// @Failure 400 {object} types.JSONError "Bad Request: id or user_id is invalid"
+// @Failure 403 {object} types.JSONError "Forbidden: only owners can manage members"
+// @Failure 404 {object} types.JSONError "Not Found: workspace or member not found"
+// @Failure 409 {object} types.JSONError "Conflict: owners cannot be removed"
+// @Security BearerAuth
+// @Router /workspaces/{id}/members/{user_id} [delete]
+func (rs WorkspaceResource) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	args, ok := rs.member(w, r, true)
+	if !ok {
+		return
+	}
+
+	user, err := model.ParseParams(chi.URLParam(r, "user_id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := rs.repo.RemoveMember(r.Context(), args.ID, user.ID); err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+}
+
+// member parses the workspace of the URL and checks that the user is a
+// member of it, and an owner when owner is set. It writes the error and
+// returns false otherwise.
+func (rs WorkspaceResource) member(w http.ResponseWriter, r *http.Request, owner bool) (model.RequestURLParam, bool) {
+	args, err := model.ParseParams(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return args, false
+	}
+
+	principal, _ := types.PrincipalFrom(r.Context())
+	role, err := rs.repo.Role(r.Context(), args.ID, principal.UserID)
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return args, false
+	}
+
+	if owner && role != model.RoleOwner {
+		writeError(w, http.StatusForbidden, model.ErrWorkspaceForbidden.Error())
+		return args, false
+	}
+
+	return args, true
+}
+
+// writeWorkspaceError maps errors returned by the workspace repository to a JSON error
+func writeWorkspaceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrWorkspaceNotFound),
+		errors.Is(err, repository.ErrMemberNotFound),
+		errors.Is(err, repository.ErrUserNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, repository.ErrMemberExists), errors.Is(err, model.ErrOwnerRemoval):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		slog.Error(err.Error())
+		writeError(w, http.StatusInternalServerError, "error: failed to process workspace")
+	}
+}
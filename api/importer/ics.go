@@ -0,0 +1,23 @@
+package importer
+
+import (
+	"io"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/ical"
+)
+
+// ICal reads the VTODO and VEVENT components of iCalendar files, see model.TaskFromICal
+type ICal struct{}
+
+func (ICal) MediaTypes() []string {
+	return []string{"text/calendar"}
+}
+
+func (ICal) Read(r io.Reader) ([]model.ImportedTask, error) {
+	calendars, err := ical.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return model.TasksFromICal(calendars), nil
+}
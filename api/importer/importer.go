@@ -0,0 +1,58 @@
+// Package importer reads the exports of other task tools into tasks to import.
+// Every format has an Adapter; TaskRepository.Import saves what they read.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+)
+
+var ErrInvalidFile = errors.New("error: invalid file")
+
+// Adapter maps the export of another tool onto tasks. Items that cannot be
+// mapped are kept with a Skip reason so that they are reported.
+type Adapter interface {
+	// MediaTypes are the media types the file is accepted as, besides multipart/form-data
+	MediaTypes() []string
+	Read(r io.Reader) ([]model.ImportedTask, error)
+}
+
+var adapters = map[string]Adapter{
+	"ics":     ICal{},
+	"todoist": Todoist{},
+	"trello":  Trello{},
+	"todotxt": TodoTxt{},
+}
+
+// Lookup returns the adapter of a format
+func Lookup(format string) (Adapter, bool) {
+	adapter, ok := adapters[strings.ToLower(format)]
+	return adapter, ok
+}
+
+// Formats lists the formats that have an adapter
+func Formats() []string {
+	formats := make([]string, 0, len(adapters))
+	for format := range adapters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// parseTime reads the dates of the exports: RFC 3339 timestamps, timestamps
+// without a zone, which are taken as UTC, and plain dates
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("error: %q is not a date", value)
+}
@@ -0,0 +1,17 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	adapter, ok := Lookup("Trello")
+	assert.True(t, ok)
+	assert.Equal(t, Trello{}, adapter)
+
+	_, ok = Lookup("asana")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"ics", "todoist", "todotxt", "trello"}, Formats())
+}
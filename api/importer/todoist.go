@@ -0,0 +1,143 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+)
+
+// Todoist reads the JSON backup of a Todoist account, as returned by its sync
+// API. Items become tasks in the category of their project, tagged with their
+// labels; subtasks keep their parent. Todoist priorities go from 1 (normal)
+// to 4 (urgent) like ours. Recurring due dates are written in natural
+// language and are imported as their next date only.
+type Todoist struct{}
+
+type todoistBackup struct {
+	Projects []todoistProject `json:"projects"`
+	Items    []todoistItem    `json:"items"`
+}
+
+type todoistProject struct {
+	ID   flexibleID `json:"id"`
+	Name string     `json:"name"`
+}
+
+type todoistItem struct {
+	ID          flexibleID  `json:"id"`
+	Content     string      `json:"content"`
+	Priority    int         `json:"priority"`
+	Due         *todoistDue `json:"due"`
+	Labels      []string    `json:"labels"`
+	ProjectID   flexibleID  `json:"project_id"`
+	ParentID    flexibleID  `json:"parent_id"`
+	Checked     bool        `json:"checked"`
+	IsDeleted   bool        `json:"is_deleted"`
+	AddedAt     string      `json:"added_at"`
+	CompletedAt string      `json:"completed_at"`
+}
+
+type todoistDue struct {
+	Date string `json:"date"`
+}
+
+// flexibleID is an id sent as a string or a number, as Todoist did before and
+// after moving to string ids
+type flexibleID string
+
+func (id *flexibleID) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*id = ""
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*id = flexibleID(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("id must be a string or a number")
+	}
+	*id = flexibleID(n.String())
+	return nil
+}
+
+func (Todoist) MediaTypes() []string {
+	return []string{"application/json"}
+}
+
+func (Todoist) Read(r io.Reader) ([]model.ImportedTask, error) {
+	var backup todoistBackup
+	if err := json.NewDecoder(r).Decode(&backup); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+	}
+
+	projects := map[flexibleID]string{}
+	for _, project := range backup.Projects {
+		projects[project.ID] = project.Name
+	}
+
+	tasks := make([]model.ImportedTask, len(backup.Items))
+	for i, item := range backup.Items {
+		tasks[i] = todoistTask(item, projects)
+	}
+	return tasks, nil
+}
+
+func todoistTask(item todoistItem, projects map[flexibleID]string) model.ImportedTask {
+	task := model.ImportedTask{ExternalID: "todoist:" + string(item.ID)}
+	task.Payload.Title = strings.TrimSpace(item.Content)
+	task.Payload.Priority = item.Priority
+	if item.ParentID != "" {
+		task.ParentExternalID = "todoist:" + string(item.ParentID)
+	}
+
+	task.Categories = []string{}
+	if project, ok := projects[item.ProjectID]; ok {
+		task.Categories = append(task.Categories, project)
+	}
+	task.Categories = append(task.Categories, item.Labels...)
+
+	task.Status = model.StatusTodo
+	if item.Checked {
+		task.Status = model.StatusDone
+		if completed, err := parseTime(item.CompletedAt); err == nil {
+			task.CompletedAt = &completed
+		}
+	}
+
+	switch {
+	case item.ID == "":
+		task.Skip = "error: item has no id"
+		return task
+	case item.IsDeleted:
+		task.Skip = "error: item was deleted in Todoist"
+		return task
+	case task.Payload.Title == "":
+		task.Skip = "error: item has no content"
+		return task
+	}
+
+	// tasks need a date, those without a due date are dated when they were added
+	date, err := parseTime(item.AddedAt)
+	if item.Due != nil && item.Due.Date != "" {
+		date, err = parseTime(item.Due.Date)
+	} else if item.AddedAt == "" {
+		date, err = time.Now().UTC().Truncate(time.Second), nil
+	}
+	if err != nil {
+		task.Skip = err.Error()
+		return task
+	}
+	task.Payload.Date = date
+
+	return task
+}
@@ -0,0 +1,50 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+)
+
+func TestTodoistRead(t *testing.T) {
+	backup := `{
+		"projects": [{"id": "2203306141", "name": "Work"}],
+		"items": [
+			{"id": "1", "content": "Call John", "priority": 4, "project_id": "2203306141",
+				"labels": ["phone"], "due": {"date": "2024-03-01T09:00:00", "is_recurring": false}},
+			{"id": 2, "content": "Prepare notes", "parent_id": "1", "project_id": "2203306141",
+				"checked": true, "completed_at": "2024-03-01T08:00:00Z", "added_at": "2024-02-20T10:00:00Z"},
+			{"id": "3", "content": "Gone", "is_deleted": true, "added_at": "2024-02-20T10:00:00Z"},
+			{"id": "4", "content": "Sometime", "due": {"date": "soon"}}
+		]
+	}`
+
+	tasks, err := Todoist{}.Read(strings.NewReader(backup))
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 4)
+
+	assert.Equal(t, "todoist:1", tasks[0].ExternalID)
+	assert.Equal(t, "Call John", tasks[0].Payload.Title)
+	assert.Equal(t, 4, tasks[0].Payload.Priority)
+	assert.Equal(t, time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), tasks[0].Payload.Date)
+	assert.Equal(t, []string{"Work", "phone"}, tasks[0].Categories)
+	assert.Equal(t, model.StatusTodo, tasks[0].Status)
+	assert.Empty(t, tasks[0].Skip)
+
+	completed := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, "todoist:2", tasks[1].ExternalID)
+	assert.Equal(t, "todoist:1", tasks[1].ParentExternalID)
+	assert.Equal(t, model.StatusDone, tasks[1].Status)
+	assert.Equal(t, &completed, tasks[1].CompletedAt)
+	assert.Equal(t, time.Date(2024, 2, 20, 10, 0, 0, 0, time.UTC), tasks[1].Payload.Date)
+
+	assert.Contains(t, tasks[2].Skip, "deleted")
+	assert.Contains(t, tasks[3].Skip, "not a date")
+
+	_, err = Todoist{}.Read(strings.NewReader("not json"))
+	assert.ErrorIs(t, err, ErrInvalidFile)
+}
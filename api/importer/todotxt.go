@@ -0,0 +1,146 @@
+package importer
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+)
+
+// TodoTxt reads todo.txt files, one task per line:
+//
+//	x 2024-03-02 2024-03-01 (A) Call John +work @phone due:2024-03-05
+//
+// Projects (+work) and contexts (@phone) become categories and are taken
+// out of the title along with the due: tag. Priorities A to I map to 9 down
+// to 1, later letters to 1. A task is due at due:, else on its creation date,
+// else on the day it was imported. todo.txt has no ids: a task is matched by
+// its id: tag when it has one, and by its text otherwise, so that importing
+// the same file again updates the tasks instead of duplicating them.
+type TodoTxt struct{}
+
+func (TodoTxt) MediaTypes() []string {
+	return []string{"text/plain"}
+}
+
+func (TodoTxt) Read(r io.Reader) ([]model.ImportedTask, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), model.MaxImportSize)
+
+	var tasks []model.ImportedTask
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		task := todoTxtTask(text)
+		task.Line = line
+		tasks = append(tasks, task)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+	}
+	return tasks, nil
+}
+
+func todoTxtTask(line string) model.ImportedTask {
+	var task model.ImportedTask
+	fields := strings.Fields(line)
+
+	task.Status = model.StatusTodo
+	if len(fields) > 0 && fields[0] == "x" {
+		task.Status = model.StatusDone
+		fields = fields[1:]
+		if completed, ok := todoTxtDate(fields); ok {
+			task.CompletedAt = &completed
+			fields = fields[1:]
+		}
+	}
+
+	if len(fields) > 0 && isTodoTxtPriority(fields[0]) {
+		task.Payload.Priority = todoTxtPriority(fields[0][1])
+		fields = fields[1:]
+	}
+
+	var date time.Time
+	if created, ok := todoTxtDate(fields); ok {
+		date = created
+		fields = fields[1:]
+	}
+
+	// text is the description without the completion, priority and dates,
+	// which is what identifies the task
+	text := strings.Join(fields, " ")
+
+	var title []string
+	task.Categories = []string{}
+	for _, field := range fields {
+		switch {
+		case len(field) > 1 && (field[0] == '+' || field[0] == '@'):
+			task.Categories = append(task.Categories, field[1:])
+		case strings.HasPrefix(field, "due:"):
+			due, err := parseTime(strings.TrimPrefix(field, "due:"))
+			if err != nil {
+				task.Skip = err.Error()
+			}
+			date = due
+		case strings.HasPrefix(field, "id:") && len(field) > 3:
+			task.ExternalID = "todotxt:" + strings.TrimPrefix(field, "id:")
+		case strings.HasPrefix(field, "pri:") && len(field) == 5 && task.Payload.Priority == 0:
+			// completed tasks keep their priority in a pri: tag
+			task.Payload.Priority = todoTxtPriority(field[4])
+		default:
+			title = append(title, field)
+		}
+	}
+
+	task.Payload.Title = strings.Join(title, " ")
+	if task.Payload.Title == "" {
+		task.Payload.Title = text
+	}
+	if task.ExternalID == "" {
+		sum := sha1.Sum([]byte(text))
+		task.ExternalID = "todotxt:" + hex.EncodeToString(sum[:])
+	}
+
+	if date.IsZero() {
+		date = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+	task.Payload.Date = date
+
+	if task.Payload.Title == "" && task.Skip == "" {
+		task.Skip = "error: line has no description"
+	}
+
+	return task
+}
+
+// todoTxtDate reads a date at the start of fields
+func todoTxtDate(fields []string) (time.Time, bool) {
+	if len(fields) == 0 {
+		return time.Time{}, false
+	}
+	date, err := time.Parse(time.DateOnly, fields[0])
+	return date, err == nil
+}
+
+func isTodoTxtPriority(field string) bool {
+	return len(field) == 3 && field[0] == '(' && field[2] == ')' && field[1] >= 'A' && field[1] <= 'Z'
+}
+
+func todoTxtPriority(letter byte) int {
+	if letter < 'A' || letter > 'Z' {
+		return 0
+	}
+	if letter > 'I' {
+		return 1
+	}
+	return 9 - int(letter-'A')
+}
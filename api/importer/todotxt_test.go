@@ -0,0 +1,48 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+)
+
+func TestTodoTxtRead(t *testing.T) {
+	file := "(A) 2024-03-01 Call John +work @phone due:2024-03-05\n" +
+		"\n" +
+		"x 2024-03-02 2024-03-01 Pay rent pri:C id:rent\n" +
+		"(Z) Water plants due:tomorrow\n"
+
+	tasks, err := TodoTxt{}.Read(strings.NewReader(file))
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 3)
+
+	call := tasks[0]
+	assert.Equal(t, 1, call.Line)
+	assert.Equal(t, "Call John", call.Payload.Title)
+	assert.Equal(t, 9, call.Payload.Priority)
+	assert.Equal(t, time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), call.Payload.Date)
+	assert.Equal(t, []string{"work", "phone"}, call.Categories)
+	assert.Equal(t, model.StatusTodo, call.Status)
+	assert.True(t, strings.HasPrefix(call.ExternalID, "todotxt:"))
+	assert.Empty(t, call.Skip)
+
+	again, _ := TodoTxt{}.Read(strings.NewReader("x 2024-03-06 (A) 2024-03-01 Call John +work @phone due:2024-03-05\n"))
+	assert.Equal(t, call.ExternalID, again[0].ExternalID, "completing a task keeps its identity")
+
+	rent := tasks[1]
+	completed := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 3, rent.Line)
+	assert.Equal(t, "Pay rent", rent.Payload.Title)
+	assert.Equal(t, 7, rent.Payload.Priority)
+	assert.Equal(t, model.StatusDone, rent.Status)
+	assert.Equal(t, &completed, rent.CompletedAt)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), rent.Payload.Date)
+	assert.Equal(t, "todotxt:rent", rent.ExternalID)
+
+	assert.Equal(t, 1, tasks[2].Payload.Priority)
+	assert.Contains(t, tasks[2].Skip, "not a date")
+}
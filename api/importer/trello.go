@@ -0,0 +1,186 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+)
+
+// Trello reads the JSON export of a Trello board. Cards become tasks in the
+// category of their list, tagged with their labels, and the items of their
+// checklists become subtasks. A card is done when its due date is marked
+// complete. Trello has no priorities, so cards keep priority 0.
+// Archived cards and lists are skipped.
+type Trello struct{}
+
+type trelloBoard struct {
+	Lists      []trelloList      `json:"lists"`
+	Labels     []trelloLabel     `json:"labels"`
+	Cards      []trelloCard      `json:"cards"`
+	Checklists []trelloChecklist `json:"checklists"`
+}
+
+type trelloList struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Closed bool   `json:"closed"`
+}
+
+type trelloLabel struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type trelloCard struct {
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	Closed           bool     `json:"closed"`
+	IDList           string   `json:"idList"`
+	IDLabels         []string `json:"idLabels"`
+	Due              string   `json:"due"`
+	DueComplete      bool     `json:"dueComplete"`
+	DateLastActivity string   `json:"dateLastActivity"`
+}
+
+type trelloChecklist struct {
+	IDCard     string            `json:"idCard"`
+	CheckItems []trelloCheckItem `json:"checkItems"`
+}
+
+type trelloCheckItem struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+	Due   string `json:"due"`
+}
+
+func (Trello) MediaTypes() []string {
+	return []string{"application/json"}
+}
+
+func (Trello) Read(r io.Reader) ([]model.ImportedTask, error) {
+	var board trelloBoard
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+	}
+
+	lists := map[string]trelloList{}
+	for _, list := range board.Lists {
+		lists[list.ID] = list
+	}
+
+	// labels without a name are known by their color
+	labels := map[string]string{}
+	for _, label := range board.Labels {
+		labels[label.ID] = label.Name
+		if label.Name == "" {
+			labels[label.ID] = label.Color
+		}
+	}
+
+	checklists := map[string][]trelloCheckItem{}
+	for _, checklist := range board.Checklists {
+		checklists[checklist.IDCard] = append(checklists[checklist.IDCard], checklist.CheckItems...)
+	}
+
+	var tasks []model.ImportedTask
+	for _, card := range board.Cards {
+		task := trelloTask(card, lists, labels)
+		tasks = append(tasks, task)
+
+		for _, item := range checklists[card.ID] {
+			tasks = append(tasks, trelloSubtask(item, task))
+		}
+	}
+	return tasks, nil
+}
+
+func trelloTask(card trelloCard, lists map[string]trelloList, labels map[string]string) model.ImportedTask {
+	task := model.ImportedTask{ExternalID: "trello:" + card.ID}
+	task.Payload.Title = strings.TrimSpace(card.Name)
+
+	list := lists[card.IDList]
+	task.Categories = []string{}
+	if list.Name != "" {
+		task.Categories = append(task.Categories, list.Name)
+	}
+	for _, id := range card.IDLabels {
+		if label := labels[id]; label != "" {
+			task.Categories = append(task.Categories, label)
+		}
+	}
+
+	task.Status = model.StatusTodo
+	if card.DueComplete {
+		task.Status = model.StatusDone
+	}
+
+	switch {
+	case card.ID == "":
+		task.Skip = "error: card has no id"
+		return task
+	case card.Closed || list.Closed:
+		task.Skip = "error: card is archived in Trello"
+		return task
+	case task.Payload.Title == "":
+		task.Skip = "error: card has no name"
+		return task
+	}
+
+	// cards without a due date are dated by their last activity
+	due := card.Due
+	if due == "" {
+		due = card.DateLastActivity
+	}
+	date, err := parseTime(due)
+	if err != nil {
+		task.Skip = err.Error()
+		return task
+	}
+	task.Payload.Date = date
+
+	return task
+}
+
+// trelloSubtask maps a checklist item onto a subtask of its card, due with
+// the card unless it has a due date of its own
+func trelloSubtask(item trelloCheckItem, card model.ImportedTask) model.ImportedTask {
+	task := model.ImportedTask{
+		ExternalID:       "trello:" + item.ID,
+		ParentExternalID: card.ExternalID,
+	}
+	task.Payload.Title = strings.TrimSpace(item.Name)
+	task.Payload.Date = card.Payload.Date
+
+	task.Status = model.StatusTodo
+	if item.State == "complete" {
+		task.Status = model.StatusDone
+	}
+
+	switch {
+	case card.Skip != "":
+		task.Skip = "error: the card of the checklist is skipped"
+		return task
+	case item.ID == "":
+		task.Skip = "error: checklist item has no id"
+		return task
+	case task.Payload.Title == "":
+		task.Skip = "error: checklist item has no name"
+		return task
+	}
+
+	if item.Due != "" {
+		date, err := parseTime(item.Due)
+		if err != nil {
+			task.Skip = err.Error()
+			return task
+		}
+		task.Payload.Date = date
+	}
+
+	return task
+}
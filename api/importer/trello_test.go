@@ -0,0 +1,54 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+)
+
+func TestTrelloRead(t *testing.T) {
+	board := `{
+		"lists": [{"id": "l1", "name": "Doing"}, {"id": "l2", "name": "Old", "closed": true}],
+		"labels": [{"id": "b1", "name": "Urgent", "color": "red"}, {"id": "b2", "name": "", "color": "green"}],
+		"cards": [
+			{"id": "c1", "name": "Ship release", "idList": "l1", "idLabels": ["b1", "b2"],
+				"due": "2024-03-01T09:00:00.000Z", "dueComplete": true},
+			{"id": "c2", "name": "Forgotten", "idList": "l2", "dateLastActivity": "2024-01-01T00:00:00.000Z"}
+		],
+		"checklists": [
+			{"idCard": "c1", "checkItems": [
+				{"id": "i1", "name": "Tag the build", "state": "complete"},
+				{"id": "i2", "name": "Write notes", "state": "incomplete", "due": "2024-03-02T00:00:00.000Z"}
+			]},
+			{"idCard": "c2", "checkItems": [{"id": "i3", "name": "Anything", "state": "incomplete"}]}
+		]
+	}`
+
+	tasks, err := Trello{}.Read(strings.NewReader(board))
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 5)
+
+	due := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	card := tasks[0]
+	assert.Equal(t, "trello:c1", card.ExternalID)
+	assert.Equal(t, "Ship release", card.Payload.Title)
+	assert.Equal(t, due, card.Payload.Date)
+	assert.Equal(t, []string{"Doing", "Urgent", "green"}, card.Categories)
+	assert.Equal(t, model.StatusDone, card.Status)
+	assert.Empty(t, card.Skip)
+
+	assert.Equal(t, "trello:i1", tasks[1].ExternalID)
+	assert.Equal(t, "trello:c1", tasks[1].ParentExternalID)
+	assert.Equal(t, due, tasks[1].Payload.Date)
+	assert.Equal(t, model.StatusDone, tasks[1].Status)
+
+	assert.Equal(t, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), tasks[2].Payload.Date)
+	assert.Equal(t, model.StatusTodo, tasks[2].Status)
+
+	assert.Contains(t, tasks[3].Skip, "archived")
+	assert.NotEmpty(t, tasks[4].Skip)
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+// APIKeyPrefix starts every API key, telling them apart from access tokens
+const APIKeyPrefix = "nt_"
+
+// Scopes of API keys. A write or admin scope also grants the read scope of
+// the same resource.
+const (
+	ScopeTasksRead       = "tasks:read"
+	ScopeTasksWrite      = "tasks:write"
+	ScopeCategoriesRead  = "categories:read"
+	ScopeCategoriesAdmin = "categories:admin"
+	ScopeProjectsRead    = "projects:read"
+	ScopeProjectsWrite   = "projects:write"
+)
+
+// Scopes lists the scopes an API key can carry
+var Scopes = []string{
+	ScopeTasksRead, ScopeTasksWrite,
+	ScopeCategoriesRead, ScopeCategoriesAdmin,
+	ScopeProjectsRead, ScopeProjectsWrite,
+}
+
+var ErrAPIKeyForbidden = errors.New("error: API keys cannot manage API keys, log in instead")
+
+type APIKey struct {
+	ID   int    `json:"id" example:"1"`
+	Name string `json:"name" example:"CI"`
+	// Prefix is the start of the key, to tell keys apart
+	Prefix string   `json:"prefix" example:"nt_Xk3vQ9"`
+	Scopes []string `json:"scopes" example:"tasks:read"`
+	// WorkspaceID is the workspace the key acts in, the one it was created in
+	WorkspaceID int        `json:"workspace_id" example:"1"`
+	ExpiresAt   *time.Time `json:"expires_at" example:"2025-03-01T00:00:00Z"`
+	LastUsedAt  *time.Time `json:"last_used_at" example:"2024-03-01T00:00:00Z"`
+	CreatedAt   time.Time  `json:"created_at" example:"2024-03-01T00:00:00Z"`
+}
+
+type APIKeys []APIKey
+
+// CreatedAPIKey is the response to minting a key, the only time the key is shown
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key" example:"nt_Xk3vQ9..."`
+}
+
+type APIKeyRequestPayload struct {
+	Name      string     `json:"name" example:"CI"`
+	Scopes    []string   `json:"scopes" example:"tasks:read,tasks:write"`
+	ExpiresAt *time.Time `json:"expires_at" example:"2025-03-01T00:00:00Z"`
+}
+
+// Validate checks the payload and removes repeated scopes
+func (p *APIKeyRequestPayload) Validate(now time.Time) error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("error: name is required")
+	}
+
+	if len(p.Name) > 255 {
+		return errors.New("error: name must be at most 255 characters")
+	}
+
+	if len(p.Scopes) == 0 {
+		return fmt.Errorf("error: scopes is required, among %s", strings.Join(Scopes, ", "))
+	}
+
+	seen := map[string]bool{}
+	scopes := []string{}
+	for _, scope := range p.Scopes {
+		if !validScope(scope) {
+			return fmt.Errorf("error: unknown scope %q, scopes are %s", scope, strings.Join(Scopes, ", "))
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	p.Scopes = scopes
+
+	if p.ExpiresAt != nil && !p.ExpiresAt.After(now) {
+		return errors.New("error: expires_at must be in the future")
+	}
+
+	return nil
+}
+
+func validScope(scope string) bool {
+	for _, known := range Scopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeAllows reports whether granted scopes allow scope. A nil list is the
+// unrestricted access of a logged in user.
+func ScopeAllows(granted []string, scope string) bool {
+	if granted == nil {
+		return true
+	}
+
+	resource, action, _ := strings.Cut(scope, ":")
+	for _, g := range granted {
+		if g == scope {
+			return true
+		}
+		gResource, gAction, _ := strings.Cut(g, ":")
+		if action == "read" && gResource == resource && (gAction == "write" || gAction == "admin") {
+			return true
+		}
+	}
+	return false
+}
+
+func (k CreatedAPIKey) ToJSON(code int, message string) types.JSONResult {
+	return types.JSONResult{
+		Data:    k,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (k APIKeys) ToJSON() types.JSONResultWithPaginate {
+	return types.JSONResultWithPaginate{
+		Code:    200,
+		Message: "success",
+		Data:    k,
+		Length:  len(k),
+	}
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyRequestPayloadValidate(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+
+	payload := APIKeyRequestPayload{
+		Name:      "CI",
+		Scopes:    []string{ScopeTasksRead, ScopeTasksWrite, ScopeTasksRead},
+		ExpiresAt: &later,
+	}
+	assert.NoError(t, payload.Validate(now))
+	assert.Equal(t, []string{ScopeTasksRead, ScopeTasksWrite}, payload.Scopes, "repeated scopes are removed")
+
+	earlier := now.Add(-time.Hour)
+	for _, bad := range []APIKeyRequestPayload{
+		{Name: "", Scopes: []string{ScopeTasksRead}},
+		{Name: "CI"},
+		{Name: "CI", Scopes: []string{"tasks:delete"}},
+		{Name: "CI", Scopes: []string{ScopeTasksRead}, ExpiresAt: &earlier},
+	} {
+		assert.Error(t, bad.Validate(now), bad)
+	}
+}
+
+func TestScopeAllows(t *testing.T) {
+	assert.True(t, ScopeAllows(nil, ScopeCategoriesAdmin), "access tokens are unrestricted")
+	assert.False(t, ScopeAllows([]string{}, ScopeTasksRead), "a key without scopes can do nothing")
+
+	granted := []string{ScopeTasksWrite, ScopeCategoriesAdmin}
+	assert.True(t, ScopeAllows(granted, ScopeTasksWrite))
+	assert.True(t, ScopeAllows(granted, ScopeTasksRead), "write includes read")
+	assert.True(t, ScopeAllows(granted, ScopeCategoriesRead), "admin includes read")
+	assert.False(t, ScopeAllows(granted, ScopeProjectsRead))
+
+	assert.False(t, ScopeAllows([]string{ScopeTasksRead}, ScopeTasksWrite), "read does not include write")
+}
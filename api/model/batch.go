@@ -0,0 +1,126 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Kbgjtn/notethingness-api.git/types"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+// BatchOp is the kind of change made by one operation of a batch
+type BatchOp string
+
+const (
+	BatchCreate BatchOp = "create"
+	BatchUpdate BatchOp = "update"
+	BatchDelete BatchOp = "delete"
+)
+
+// DefaultBatchMaxSize is how many operations a batch may hold unless BATCH_MAX_SIZE says otherwise
+const DefaultBatchMaxSize = 1000
+
+var (
+	ErrBatchEmpty = errors.New("error: \"operations\" must not be empty")
+	ErrBatchLimit = errors.New("error: too many operations in the batch")
+	// ErrBatchIfMatchRequired is ErrPreconditionRequired for an operation of a batch
+	ErrBatchIfMatchRequired = errors.New("error: \"if_match\" is required, send the ETag of the task")
+)
+
+// BatchOperation creates, updates or deletes one task. Task holds the new
+// fields for create and update; IfMatch stands for the If-Match header of an
+// update or delete, Children and Permanent mirror the query parameters of
+// DELETE /tasks/{id}.
+type BatchOperation struct {
+	Op        BatchOp             `json:"op" example:"create"`
+	ID        int                 `json:"id,omitempty" example:"1"`
+	IfMatch   string              `json:"if_match,omitempty" example:"\"3\""`
+	Task      *TaskRequestPayload `json:"task,omitempty"`
+	Children  CascadePolicy       `json:"children,omitempty" example:"reject"`
+	Permanent bool                `json:"permanent,omitempty" example:"false"`
+}
+
+// Params returns the task the operation updates or deletes, with its precondition
+func (o BatchOperation) Params() TaskURLParams {
+	return TaskURLParams{ID: o.ID, IfMatch: util.ParseETags(o.IfMatch)}
+}
+
+// Validate checks that the operation is complete and fills in the default
+// children policy. requireIfMatch rejects an update or delete without IfMatch.
+func (o *BatchOperation) Validate(requireIfMatch bool) error {
+	if requireIfMatch && (o.Op == BatchUpdate || o.Op == BatchDelete) && o.IfMatch == "" {
+		return ErrBatchIfMatchRequired
+	}
+
+	switch o.Op {
+	case BatchCreate:
+		if o.Task == nil {
+			return fmt.Errorf("error: \"task\" is required to create a task")
+		}
+		return o.Task.Validate()
+	case BatchUpdate:
+		if o.ID <= 0 {
+			return fmt.Errorf("error: \"id\" is required to update a task")
+		}
+		if o.Task == nil {
+			return fmt.Errorf("error: \"task\" is required to update a task")
+		}
+		return o.Task.Validate()
+	case BatchDelete:
+		if o.ID <= 0 {
+			return fmt.Errorf("error: \"id\" is required to delete a task")
+		}
+		policy, err := ParseCascadePolicy(string(o.Children))
+		o.Children = policy
+		return err
+	default:
+		return fmt.Errorf("error: \"op\" must be one of create, update or delete, got %q", o.Op)
+	}
+}
+
+type BatchPayload struct {
+	Operations []BatchOperation `json:"operations"`
+}
+
+// Validate rejects an empty batch or one with more than max operations.
+// The operations themselves are checked one by one with BatchOperation.Validate.
+func (p BatchPayload) Validate(max int) error {
+	if len(p.Operations) == 0 {
+		return ErrBatchEmpty
+	}
+	if len(p.Operations) > max {
+		return fmt.Errorf("%w: got %d, at most %d are allowed", ErrBatchLimit, len(p.Operations), max)
+	}
+	return nil
+}
+
+// BatchResult is the outcome of one operation, Status is the HTTP status the
+// operation would have had as a request of its own
+type BatchResult struct {
+	Index  int     `json:"index" example:"0"`
+	Op     BatchOp `json:"op" example:"create"`
+	Status int     `json:"status" example:"201"`
+	Data   *Task   `json:"data,omitempty"`
+	Error  string  `json:"error,omitempty"`
+}
+
+type BatchResults []BatchResult
+
+func (r BatchResults) ToJSON(code int, message string) types.JSONResult {
+	return types.JSONResult{Code: code, Message: message, Data: r}
+}
+
+// ParseBatchMaxSize parses the BATCH_MAX_SIZE setting, defaulting to DefaultBatchMaxSize when empty
+func ParseBatchMaxSize(value string) (int, error) {
+	if value == "" {
+		return DefaultBatchMaxSize, nil
+	}
+
+	size, err := strconv.Atoi(value)
+	if err != nil || size <= 0 {
+		return 0, fmt.Errorf("error: BATCH_MAX_SIZE must be a positive number, got %q", value)
+	}
+
+	return size, nil
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchOperationValidate(t *testing.T) {
+	task := &TaskRequestPayload{Title: "Call John", Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+
+	ok := []BatchOperation{
+		{Op: BatchCreate, Task: task},
+		{Op: BatchUpdate, ID: 1, Task: task},
+		{Op: BatchDelete, ID: 1, Children: CascadeAll},
+	}
+	for _, op := range ok {
+		assert.NoError(t, op.Validate(false), op.Op)
+	}
+
+	bad := []BatchOperation{
+		{Op: "upsert", Task: task},
+		{Op: BatchCreate},
+		{Op: BatchCreate, Task: &TaskRequestPayload{CategoryIDs: []int{0}}},
+		{Op: BatchUpdate, Task: task},
+		{Op: BatchUpdate, ID: 1},
+		{Op: BatchDelete},
+		{Op: BatchDelete, ID: 1, Children: "orphan"},
+	}
+	for _, op := range bad {
+		assert.Error(t, op.Validate(false), op)
+	}
+
+	op := BatchOperation{Op: BatchDelete, ID: 1}
+	assert.NoError(t, op.Validate(false))
+	assert.Equal(t, CascadeReject, op.Children)
+
+	assert.ErrorIs(t, op.Validate(true), ErrBatchIfMatchRequired)
+	assert.NoError(t, (&BatchOperation{Op: BatchCreate, Task: task}).Validate(true))
+
+	op = BatchOperation{Op: BatchUpdate, ID: 1, IfMatch: `"3"`, Task: task}
+	assert.NoError(t, op.Validate(true))
+	assert.True(t, op.Params().IfMatch.Match(`"3"`))
+	assert.False(t, op.Params().IfMatch.Match(`"4"`))
+}
+
+func TestBatchPayloadValidate(t *testing.T) {
+	assert.ErrorIs(t, BatchPayload{}.Validate(2), ErrBatchEmpty)
+
+	ops := make([]BatchOperation, 3)
+	assert.ErrorIs(t, BatchPayload{Operations: ops}.Validate(2), ErrBatchLimit)
+	assert.NoError(t, BatchPayload{Operations: ops}.Validate(3))
+}
+
+func TestParseBatchMaxSize(t *testing.T) {
+	size, err := ParseBatchMaxSize("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultBatchMaxSize, size)
+
+	size, err = ParseBatchMaxSize("50")
+	assert.NoError(t, err)
+	assert.Equal(t, 50, size)
+
+	_, err = ParseBatchMaxSize("0")
+	assert.Error(t, err)
+	_, err = ParseBatchMaxSize("many")
+	assert.Error(t, err)
+}
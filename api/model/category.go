@@ -3,6 +3,9 @@ package model
 import (
 	"errors"
 	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/Kbgjtn/notethingness-api.git/types"
 	"github.com/Kbgjtn/notethingness-api.git/util"
@@ -12,6 +15,8 @@ import (
 type Category struct {
 	ID    int    `json:"id"    example:"1"`
 	Label string `json:"label" example:"My Category"`
+	// OwnerID is only listed with the categories themselves, not with their tasks
+	OwnerID *int `json:"owner_id,omitempty" example:"1"`
 }
 
 type CategoryRequestPayload struct {
@@ -40,6 +45,29 @@ func (c CategoryRequestPayload) Validate() error {
 	return nil
 }
 
+// CategoryLabel turns the name of a label, list or project of another tool
+// into a label that CategoryRequestPayload accepts: separators become spaces
+// and whatever else is not a letter is dropped. It is "" when nothing is left.
+func CategoryLabel(name string) string {
+	label := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r):
+			return r
+		case unicode.IsSpace(r) || strings.ContainsRune("-_./:&+", r):
+			return ' '
+		default:
+			return -1
+		}
+	}, name)
+	label = strings.Join(strings.Fields(label), " ")
+
+	for len(label) > 255 {
+		_, size := utf8.DecodeLastRuneInString(label)
+		label = strings.TrimSpace(label[:len(label)-size])
+	}
+	return label
+}
+
 func (c *Category) ToJSON(code int, message string) types.JSONResult {
 	return types.JSONResult{
 		Data:    c,
@@ -88,13 +116,19 @@ func (c *Category) toJSON() types.JSONResult {
 
 type Categories []Category
 
+// CategoryFilter narrows down and orders the categories returned by a list query
+type CategoryFilter struct {
+	Expr util.FilterNode
+	Sort []util.SortField
+}
+
+// CategoryFields whitelists the category fields of the "filter" and "sort" query parameters
+var CategoryFields = util.FilterSchema{
+	"id":       util.FieldInt,
+	"label":    util.FieldString,
+	"owner_id": util.FieldInt,
+}
+
 func (c Categories) ToJSON(pag types.Pageable) types.JSONResultWithPaginate {
-	pag.Calc()
-	return types.JSONResultWithPaginate{
-		Data:     c,
-		Code:     200,
-		Message:  "success",
-		Paginate: &pag,
-		Length:   len(c),
-	}
+	return pag.Result(c, len(c))
 }
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoryLabel(t *testing.T) {
+	assert.Equal(t, "Work", CategoryLabel("Work"))
+	assert.Equal(t, "to do", CategoryLabel("to-do"))
+	assert.Equal(t, "errands", CategoryLabel("@errands"))
+	assert.Equal(t, "Q planning", CategoryLabel("Q3 planning 🚀"))
+	assert.Equal(t, "", CategoryLabel("2024"))
+
+	long := CategoryLabel(strings.Repeat("é", 200))
+	assert.LessOrEqual(t, len(long), 255)
+	assert.NoError(t, CategoryRequestPayload{Label: long}.Validate())
+}
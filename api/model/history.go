@@ -0,0 +1,135 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Kbgjtn/notethingness-api.git/types"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+type HistoryAction string
+
+const (
+	HistoryCreate HistoryAction = "create"
+	HistoryUpdate HistoryAction = "update"
+	HistoryDelete HistoryAction = "delete"
+	// HistoryTrash and HistoryRestore move a task to the trash and back,
+	// HistoryDelete is final
+	HistoryTrash   HistoryAction = "trash"
+	HistoryRestore HistoryAction = "restore"
+)
+
+// Audited entities, stored in the "entity" column of "task_history"
+const (
+	EntityTask     = "task"
+	EntityCategory = "category"
+)
+
+// FieldChange is the value of a field before and after a change, null when absent
+type FieldChange struct {
+	From json.RawMessage `json:"from" swaggertype:"object"`
+	To   json.RawMessage `json:"to" swaggertype:"object"`
+}
+
+// Changes maps field names to their change
+type Changes map[string]FieldChange
+
+// HistoryEntry records one change of a task or category
+type HistoryEntry struct {
+	ID        int           `json:"id" example:"1"`
+	Entity    string        `json:"entity" example:"task"`
+	EntityID  int           `json:"entity_id" example:"1"`
+	Action    HistoryAction `json:"action" example:"update"`
+	Changes   Changes       `json:"changes"`
+	Actor     *string       `json:"actor" example:"jane@example.com"`
+	RequestID *string       `json:"request_id" example:"host/abcdef-000001"`
+	CreatedAt time.Time     `json:"created_at" example:"2024-03-01T00:00:00Z"`
+}
+
+type History []HistoryEntry
+
+func (h History) ToJSON(pag types.Pageable) types.JSONResultWithPaginate {
+	return pag.Result(h, len(h))
+}
+
+// HistoryFields are the keys history is paginated by, newest first
+var HistoryFields = util.FilterSchema{
+	"id": util.FieldInt,
+}
+
+// historyIgnored are the fields that change as a side effect or are derived,
+// and are left out of diffs
+var historyIgnored = map[string]bool{
+	"created_at":    true,
+	"updated_at":    true,
+	"version":       true,
+	"children":      true,
+	"blocked":       true,
+	"comment_count": true,
+	"category_ids":  true,
+}
+
+// Diff compares the JSON fields of two values, either of which may be nil,
+// and returns the fields that differ
+func Diff(before, after interface{}) (Changes, error) {
+	from, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := Changes{}
+	for field, value := range from {
+		if next := nullIfMissing(to[field]); !historyIgnored[field] && string(value) != string(next) {
+			changes[field] = FieldChange{From: value, To: next}
+		}
+	}
+	for field, value := range to {
+		if _, ok := from[field]; !ok && !historyIgnored[field] && string(value) != "null" {
+			changes[field] = FieldChange{From: json.RawMessage("null"), To: value}
+		}
+	}
+
+	return changes, nil
+}
+
+// Change records a single field moving from one value to another
+func Change(from, to interface{}) (FieldChange, error) {
+	fromJSON, err := json.Marshal(from)
+	if err != nil {
+		return FieldChange{}, err
+	}
+
+	toJSON, err := json.Marshal(to)
+	if err != nil {
+		return FieldChange{}, err
+	}
+
+	return FieldChange{From: fromJSON, To: toJSON}, nil
+}
+
+func jsonFields(v interface{}) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if v == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return fields, json.Unmarshal(data, &fields)
+}
+
+func nullIfMissing(value json.RawMessage) json.RawMessage {
+	if value == nil {
+		return json.RawMessage("null")
+	}
+	return value
+}
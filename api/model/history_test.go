@@ -0,0 +1,38 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	before := Task{ID: 1, Title: "Call John", Priority: 1, Date: date, Status: StatusTodo}
+	after := before
+	after.Priority = 3
+	after.UpdatedAt = date.Add(time.Hour)
+
+	changes, err := Diff(before, after)
+	assert.NoError(t, err)
+	assert.Equal(t, Changes{
+		"priority": {From: json.RawMessage("1"), To: json.RawMessage("3")},
+	}, changes, "updated_at is ignored")
+
+	changes, err = Diff(nil, Category{ID: 2, Label: "work"})
+	assert.NoError(t, err)
+	assert.Equal(t, json.RawMessage("null"), changes["label"].From)
+	assert.Equal(t, json.RawMessage(`"work"`), changes["label"].To)
+
+	changes, err = Diff(Category{ID: 2, Label: "work"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, json.RawMessage("null"), changes["id"].To)
+}
+
+func TestChange(t *testing.T) {
+	change, err := Change(StatusTodo, StatusDone)
+	assert.NoError(t, err)
+	assert.Equal(t, FieldChange{From: json.RawMessage(`"todo"`), To: json.RawMessage(`"done"`)}, change)
+}
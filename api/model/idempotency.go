@@ -0,0 +1,64 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// DefaultIdempotencyTTL is how long a saved response can be replayed unless IDEMPOTENCY_TTL says otherwise
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// MaxIdempotencyKeyLength bounds the Idempotency-Key header
+const MaxIdempotencyKeyLength = 255
+
+// MaxIdempotentBodySize is the largest body read to fingerprint a request sent
+// with an Idempotency-Key, that of an attachment upload with its multipart framing
+const MaxIdempotentBodySize = MaxAttachmentSize + 1<<20
+
+var (
+	ErrIdempotencyMismatch   = errors.New("error: the Idempotency-Key was already used with a different request")
+	ErrIdempotencyInProgress = errors.New("error: a request with this Idempotency-Key is still being handled")
+)
+
+// IdempotentResponse is the saved response of a request sent with an Idempotency-Key
+type IdempotentResponse struct {
+	Status int
+	Header map[string]string
+	Body   []byte
+}
+
+// ValidateIdempotencyKey rejects an empty or oversized Idempotency-Key
+func ValidateIdempotencyKey(key string) error {
+	if key == "" || len(key) > MaxIdempotencyKeyLength {
+		return fmt.Errorf("error: Idempotency-Key must be between 1 and %d characters", MaxIdempotencyKeyLength)
+	}
+	return nil
+}
+
+// RequestFingerprint hashes what makes two requests the same: the method, the
+// path, the query and the body. Options such as dry_run are query parameters.
+func RequestFingerprint(method, path string, query url.Values, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "?" + query.Encode() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// ParseIdempotencyTTL parses the IDEMPOTENCY_TTL setting, a duration such as
+// "24h", defaulting to DefaultIdempotencyTTL when empty
+func ParseIdempotencyTTL(value string) (time.Duration, error) {
+	if value == "" {
+		return DefaultIdempotencyTTL, nil
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("error: IDEMPOTENCY_TTL must be a positive duration such as 24h, got %q", value)
+	}
+
+	return ttl, nil
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestFingerprint(t *testing.T) {
+	body := []byte(`{"title":"Call John"}`)
+	query := url.Values{"dry_run": {"true"}, "format": {"csv"}}
+	fingerprint := RequestFingerprint("POST", "/api/tasks", query, body)
+
+	assert.Len(t, fingerprint, 64)
+	assert.Equal(t, fingerprint, RequestFingerprint("POST", "/api/tasks", url.Values{"format": {"csv"}, "dry_run": {"true"}}, body))
+	assert.NotEqual(t, fingerprint, RequestFingerprint("POST", "/api/tasks", query, []byte(`{"title":"Call Jane"}`)))
+	assert.NotEqual(t, fingerprint, RequestFingerprint("POST", "/api/categories", query, body))
+	assert.NotEqual(t, fingerprint, RequestFingerprint("POST", "/api/tasks", url.Values{"format": {"csv"}}, body))
+}
+
+func TestValidateIdempotencyKey(t *testing.T) {
+	assert.NoError(t, ValidateIdempotencyKey("8e03978e-40d5-43e8-bc93-6894a57f9324"))
+	assert.Error(t, ValidateIdempotencyKey(""))
+	assert.Error(t, ValidateIdempotencyKey(strings.Repeat("k", MaxIdempotencyKeyLength+1)))
+}
+
+func TestParseIdempotencyTTL(t *testing.T) {
+	ttl, err := ParseIdempotencyTTL("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultIdempotencyTTL, ttl)
+
+	ttl, err = ParseIdempotencyTTL("1h30m")
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, ttl)
+
+	_, err = ParseIdempotencyTTL("0s")
+	assert.Error(t, err)
+}
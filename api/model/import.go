@@ -0,0 +1,94 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+// MaxImportSize is the largest file accepted by the import endpoints
+const MaxImportSize = 10 << 20
+
+// ImportAction is what an import did with one item
+type ImportAction string
+
+const (
+	ImportCreated ImportAction = "created"
+	ImportUpdated ImportAction = "updated"
+	ImportSkipped ImportAction = "skipped"
+)
+
+// ImportedTask is a task read from a file of another tool, ready to be
+// upserted. It matches an existing task by ID, a task of ours, or else by
+// ExternalID, its identifier in the other tool; without either it is created.
+type ImportedTask struct {
+	// Line is where the item starts in its file, when the format has lines
+	Line       int
+	ID         int
+	ExternalID string
+	// Payload holds the fields of the task, its CategoryIDs are ignored.
+	// A nil ProjectID keeps the project of an updated task.
+	Payload TaskRequestPayload
+	// Status is applied as is, bypassing the transitions, "" keeps the current one
+	Status      TaskStatus
+	CompletedAt *time.Time
+	// Categories are names of categories, turned into labels with CategoryLabel.
+	// Those that do not exist yet are created. nil keeps the categories of an
+	// updated task.
+	Categories []string
+	// ParentID or ParentExternalID points at the parent, which may be
+	// another item of the same import
+	ParentID         int
+	ParentExternalID string
+	// Skip is why the item cannot be imported, it is reported without touching the database
+	Skip string
+}
+
+// HasParent reports whether the item names a parent task
+func (t ImportedTask) HasParent() bool {
+	return t.ParentID > 0 || t.ParentExternalID != ""
+}
+
+// ImportItem reports what happened to one item of an import. Reason explains
+// a skipped item, or what could not be applied to an imported one.
+type ImportItem struct {
+	Index      int          `json:"index" example:"0"`
+	Line       int          `json:"line,omitempty" example:"2"`
+	ExternalID string       `json:"external_id,omitempty" example:"0b6c4f2e@example.com"`
+	Title      string       `json:"title,omitempty" example:"Call John"`
+	Action     ImportAction `json:"action" example:"created"`
+	TaskID     int          `json:"task_id,omitempty" example:"1"`
+	Reason     string       `json:"reason,omitempty" example:"error: SUMMARY is missing"`
+}
+
+type ImportReport struct {
+	// DryRun reports what would have happened, nothing was saved
+	DryRun  bool `json:"dry_run" example:"false"`
+	Created int  `json:"created" example:"1"`
+	Updated int  `json:"updated" example:"0"`
+	Skipped int  `json:"skipped" example:"0"`
+	// Categories are the categories created for labels that did not exist yet
+	Categories []Category   `json:"categories"`
+	Items      []ImportItem `json:"items"`
+}
+
+// Add appends an item and counts its action
+func (r *ImportReport) Add(item ImportItem) {
+	switch item.Action {
+	case ImportCreated:
+		r.Created++
+	case ImportUpdated:
+		r.Updated++
+	default:
+		r.Skipped++
+	}
+	r.Items = append(r.Items, item)
+}
+
+func (r ImportReport) ToJSON() types.JSONResult {
+	return types.JSONResult{
+		Data:    r,
+		Code:    200,
+		Message: "success",
+	}
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var (
+	ErrPreconditionFailed   = errors.New("error: the task has changed since it was read")
+	ErrPreconditionRequired = errors.New("error: the If-Match header is required, send the ETag of the task")
+)
+
+// StaleTaskError is returned when If-Match does not match the version of the task
+type StaleTaskError struct {
+	Current Task
+}
+
+func (e *StaleTaskError) Error() string {
+	return fmt.Sprintf("%s, its ETag is now %s", ErrPreconditionFailed, e.Current.ETag())
+}
+
+func (e *StaleTaskError) Unwrap() error {
+	return ErrPreconditionFailed
+}
+
+// ETag identifies the version of the task, every update changes it
+func (q Task) ETag() string {
+	return `"` + strconv.Itoa(q.Version) + `"`
+}
+
+// CheckVersion fails with a StaleTaskError when the request carried an
+// If-Match header that does not match current
+func (req TaskURLParams) CheckVersion(current Task) error {
+	if req.IfMatch.Present() && !req.IfMatch.Match(current.ETag()) {
+		return &StaleTaskError{Current: current}
+	}
+	return nil
+}
+
+// ParseIfMatchPolicy parses the IF_MATCH setting: "required" makes every
+// PUT, PATCH and DELETE of a task send If-Match, "optional" (the default) checks it when sent
+func ParseIfMatchPolicy(value string) (required bool, err error) {
+	switch value {
+	case "", "optional":
+		return false, nil
+	case "required":
+		return true, nil
+	default:
+		return false, fmt.Errorf("error: IF_MATCH must be required or optional, got %q", value)
+	}
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+func TestCheckVersion(t *testing.T) {
+	task := Task{ID: 1, Version: 3}
+	assert.Equal(t, `"3"`, task.ETag())
+
+	assert.NoError(t, TaskURLParams{ID: 1}.CheckVersion(task))
+	assert.NoError(t, TaskURLParams{ID: 1, IfMatch: util.ParseETags(`"3"`)}.CheckVersion(task))
+	assert.NoError(t, TaskURLParams{ID: 1, IfMatch: util.ParseETags("*")}.CheckVersion(task))
+
+	err := TaskURLParams{ID: 1, IfMatch: util.ParseETags(`"2"`)}.CheckVersion(task)
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+
+	var stale *StaleTaskError
+	assert.ErrorAs(t, err, &stale)
+	assert.Equal(t, task, stale.Current)
+}
+
+func TestParseIfMatchPolicy(t *testing.T) {
+	required, err := ParseIfMatchPolicy("")
+	assert.NoError(t, err)
+	assert.False(t, required)
+
+	required, err = ParseIfMatchPolicy("required")
+	assert.NoError(t, err)
+	assert.True(t, required)
+
+	_, err = ParseIfMatchPolicy("always")
+	assert.Error(t, err)
+}
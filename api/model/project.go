@@ -0,0 +1,108 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kbgjtn/notethingness-api.git/types"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+var ErrProjectArchived = errors.New("error: project is archived, unarchive it to add tasks")
+
+// Project groups tasks. A task is in at most one project, together with its
+// subtasks; tasks outside of any project have no project_id.
+type Project struct {
+	ID          int        `json:"id" example:"1"`
+	Name        string     `json:"name" example:"Website relaunch"`
+	Description string     `json:"description" example:"Everything for the new website"`
+	ArchivedAt  *time.Time `json:"archived_at" example:"2024-03-01T00:00:00Z"`
+	OwnerID     *int       `json:"owner_id" example:"1"`
+	CreatedAt   time.Time  `json:"created_at" example:"2024-03-01T00:00:00Z"`
+	UpdatedAt   time.Time  `json:"updated_at" example:"2024-03-01T00:00:00Z"`
+}
+
+type Projects []Project
+
+type ProjectRequestPayload struct {
+	Name        string `json:"name" example:"Website relaunch"`
+	Description string `json:"description" example:"Everything for the new website"`
+}
+
+func (p ProjectRequestPayload) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("error: name is required")
+	}
+
+	if len(p.Name) > 255 {
+		return errors.New("error: name must be at most 255 characters")
+	}
+
+	return nil
+}
+
+// MoveTasksPayload lists the tasks to move into a project
+type MoveTasksPayload struct {
+	TaskIDs []int `json:"task_ids" example:"1,2"`
+}
+
+func (p MoveTasksPayload) Validate() error {
+	if len(p.TaskIDs) == 0 {
+		return errors.New("error: \"task_ids\" is required")
+	}
+
+	for _, id := range p.TaskIDs {
+		if id <= 0 {
+			return fmt.Errorf("error: \"task_ids\" must be ids greater than 0, got %d", id)
+		}
+	}
+
+	return nil
+}
+
+// ProjectFilter narrows down and orders the projects returned by a list query
+type ProjectFilter struct {
+	// Archived lists the archived projects along with the others
+	Archived bool
+	Expr     util.FilterNode
+	Sort     []util.SortField
+}
+
+// ProjectFields whitelists the project fields of the "filter" and "sort" query parameters
+var ProjectFields = util.FilterSchema{
+	"id":          util.FieldInt,
+	"name":        util.FieldString,
+	"archived_at": util.FieldTime,
+	"owner_id":    util.FieldInt,
+	"created_at":  util.FieldTime,
+	"updated_at":  util.FieldTime,
+}
+
+// ParseProjectID parses the "project" query parameter, an empty value means no project filter
+func ParseProjectID(value string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return nil, fmt.Errorf("error: \"project\" must be a project id, got %q", value)
+	}
+
+	return &id, nil
+}
+
+func (p Project) ToJSON(code int, message string) types.JSONResult {
+	return types.JSONResult{
+		Data:    p,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (p Projects) ToJSON(pag types.Pageable) types.JSONResultWithPaginate {
+	return pag.Result(p, len(p))
+}
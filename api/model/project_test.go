@@ -0,0 +1,35 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectRequestPayloadValidate(t *testing.T) {
+	assert.NoError(t, ProjectRequestPayload{Name: "Website"}.Validate())
+	assert.Error(t, ProjectRequestPayload{Name: "  "}.Validate())
+	assert.Error(t, ProjectRequestPayload{Name: strings.Repeat("a", 256)}.Validate())
+}
+
+func TestMoveTasksPayloadValidate(t *testing.T) {
+	assert.NoError(t, MoveTasksPayload{TaskIDs: []int{1, 2}}.Validate())
+	assert.Error(t, MoveTasksPayload{}.Validate())
+	assert.Error(t, MoveTasksPayload{TaskIDs: []int{1, 0}}.Validate())
+}
+
+func TestParseProjectID(t *testing.T) {
+	id, err := ParseProjectID("")
+	assert.NoError(t, err)
+	assert.Nil(t, id)
+
+	id, err = ParseProjectID("3")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, *id)
+
+	_, err = ParseProjectID("0")
+	assert.Error(t, err)
+	_, err = ParseProjectID("web")
+	assert.Error(t, err)
+}
@@ -0,0 +1,180 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+// MaxOccurrences caps how many occurrences are expanded in a single request
+const MaxOccurrences = 500
+
+// MaxRecurrenceSteps caps how many occurrences of a series are walked through
+// in a single request, those before the requested range included
+const MaxRecurrenceSteps = 100000
+
+var (
+	ErrNotRecurring      = errors.New("error: task is not recurring")
+	ErrRecurrenceTooLong = errors.New("error: the series has too many occurrences before the requested range, move the date of the task forward")
+)
+
+// ParseRecurrence parses an RFC 5545 RRULE value such as "FREQ=WEEKLY;BYDAY=MO",
+// optionally prefixed with "RRULE:". The series starts at start, so the rule
+// itself must not carry a DTSTART.
+func ParseRecurrence(rule string, start time.Time) (*rrule.RRule, error) {
+	rule = strings.TrimSpace(rule)
+	if strings.Contains(rule, "\n") || strings.Contains(rule, "DTSTART") {
+		return nil, errors.New("error: recurrence must be a single RRULE without DTSTART")
+	}
+
+	option, err := rrule.StrToROption(rule)
+	if err != nil {
+		return nil, fmt.Errorf("error: recurrence is not a valid RRULE: %s", err)
+	}
+
+	// a task is due on a day: finer series are too long to walk through
+	switch option.Freq {
+	case rrule.HOURLY, rrule.MINUTELY, rrule.SECONDLY:
+		return nil, errors.New("error: recurrence FREQ must be DAILY or longer, HOURLY, MINUTELY and SECONDLY are not supported")
+	}
+
+	option.Dtstart = start
+	r, err := rrule.NewRRule(*option)
+	if err != nil {
+		return nil, fmt.Errorf("error: recurrence is not a valid RRULE: %s", err)
+	}
+
+	return r, nil
+}
+
+// ValidateRecurrence checks the optional recurrence of a task starting at date
+func ValidateRecurrence(rule *string, date time.Time) error {
+	if rule == nil || *rule == "" {
+		return nil
+	}
+
+	if date.IsZero() {
+		return errors.New("error: date is required for a recurring task")
+	}
+
+	_, err := ParseRecurrence(*rule, date)
+	return err
+}
+
+// walkSeries calls visit with the occurrences of a series in order until it
+// returns false or the series is over. It fails with ErrRecurrenceTooLong
+// after MaxRecurrenceSteps occurrences.
+func walkSeries(r *rrule.RRule, visit func(time.Time) bool) error {
+	next := r.Iterator()
+	for steps := 0; ; steps++ {
+		if steps == MaxRecurrenceSteps {
+			return ErrRecurrenceTooLong
+		}
+		occurrence, ok := next()
+		if !ok || !visit(occurrence) {
+			return nil
+		}
+	}
+}
+
+// NextOccurrence returns the first occurrence of the task's series after its date,
+// or false when the task does not recur or the series is over
+func (t Task) NextOccurrence() (time.Time, bool, error) {
+	if t.Recurrence == nil || *t.Recurrence == "" {
+		return time.Time{}, false, nil
+	}
+
+	start := t.Date
+	if t.RecurrenceStart != nil {
+		start = *t.RecurrenceStart
+	}
+
+	r, err := ParseRecurrence(*t.Recurrence, start)
+	if err != nil {
+		return time.Time{}, false, nil
+	}
+
+	var next time.Time
+	err = walkSeries(r, func(occurrence time.Time) bool {
+		if occurrence.After(t.Date) {
+			next = occurrence
+			return false
+		}
+		return true
+	})
+	return next, !next.IsZero(), err
+}
+
+// Occurrences expands the task's series between from and to (inclusive),
+// returning at most MaxOccurrences dates. A series that reaches from only
+// after MaxRecurrenceSteps occurrences fails with ErrRecurrenceTooLong.
+func (t Task) Occurrences(from, to time.Time) ([]time.Time, error) {
+	if t.Recurrence == nil || *t.Recurrence == "" {
+		return nil, ErrNotRecurring
+	}
+
+	start := t.Date
+	if t.RecurrenceStart != nil {
+		start = *t.RecurrenceStart
+	}
+
+	r, err := ParseRecurrence(*t.Recurrence, start)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences := []time.Time{}
+	err = walkSeries(r, func(occurrence time.Time) bool {
+		if occurrence.After(to) {
+			return false
+		}
+		if !occurrence.Before(from) {
+			occurrences = append(occurrences, occurrence)
+		}
+		return len(occurrences) < MaxOccurrences
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return occurrences, nil
+}
+
+// ParseTimeRange parses the from and to query parameters as RFC 3339 timestamps
+// or plain dates; from defaults to fallback and to to one year after from
+func ParseTimeRange(from, to string, fallback time.Time) (time.Time, time.Time, error) {
+	start, end := fallback, time.Time{}
+
+	if from != "" {
+		t, err := parseTime(from)
+		if err != nil {
+			return start, end, fmt.Errorf("error: from must be a date or RFC 3339 timestamp")
+		}
+		start = t
+	}
+
+	end = start.AddDate(1, 0, 0)
+	if to != "" {
+		t, err := parseTime(to)
+		if err != nil {
+			return start, end, fmt.Errorf("error: to must be a date or RFC 3339 timestamp")
+		}
+		end = t
+	}
+
+	if end.Before(start) {
+		return start, end, errors.New("error: to must not be before from")
+	}
+
+	return start, end, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRecurrence(t *testing.T) {
+	date := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	rule := func(s string) *string { return &s }
+
+	assert.NoError(t, ValidateRecurrence(nil, time.Time{}))
+	assert.NoError(t, ValidateRecurrence(rule("FREQ=WEEKLY;BYDAY=MO"), date))
+	assert.NoError(t, ValidateRecurrence(rule("RRULE:FREQ=MONTHLY;BYMONTHDAY=1;COUNT=3"), date))
+
+	assert.Error(t, ValidateRecurrence(rule("FREQ=WEEKLY"), time.Time{}), "date is required")
+	assert.Error(t, ValidateRecurrence(rule("FREQ=SOMETIMES"), date))
+	assert.Error(t, ValidateRecurrence(rule("BYDAY=MO"), date), "FREQ is required")
+	assert.Error(t, ValidateRecurrence(rule("FREQ=SECONDLY"), date), "finer than daily")
+	assert.Error(t, ValidateRecurrence(rule("FREQ=HOURLY;INTERVAL=6"), date), "finer than daily")
+	assert.Error(t, ValidateRecurrence(rule("DTSTART:20240101T000000Z\nRRULE:FREQ=DAILY"), date))
+}
+
+func TestTaskNextOccurrence(t *testing.T) {
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	rule := "FREQ=WEEKLY;COUNT=3"
+	task := Task{Date: start, Recurrence: &rule, RecurrenceStart: &start}
+
+	next, ok, err := task.NextOccurrence()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, start.AddDate(0, 0, 7), next)
+
+	task.Date = start.AddDate(0, 0, 14)
+	_, ok, err = task.NextOccurrence()
+	assert.NoError(t, err)
+	assert.False(t, ok, "the series ends after COUNT occurrences")
+
+	_, ok, err = Task{Date: start}.NextOccurrence()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	daily := "FREQ=DAILY"
+	old := time.Date(1700, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, _, err = Task{Date: start, Recurrence: &daily, RecurrenceStart: &old}.NextOccurrence()
+	assert.ErrorIs(t, err, ErrRecurrenceTooLong)
+}
+
+func TestTaskOccurrences(t *testing.T) {
+	start := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	rule := "FREQ=MONTHLY;BYMONTHDAY=-1"
+	task := Task{Date: start, Recurrence: &rule}
+
+	occurrences, err := task.Occurrences(
+		time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+	}, occurrences)
+
+	_, err = Task{Date: start}.Occurrences(start, start)
+	assert.ErrorIs(t, err, ErrNotRecurring)
+
+	daily := "FREQ=DAILY"
+	_, err = Task{Date: time.Date(1700, 1, 1, 0, 0, 0, 0, time.UTC), Recurrence: &daily}.Occurrences(start, start)
+	assert.ErrorIs(t, err, ErrRecurrenceTooLong)
+}
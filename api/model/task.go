@@ -3,18 +3,35 @@ package model
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Kbgjtn/notethingness-api.git/types"
+	"github.com/Kbgjtn/notethingness-api.git/util"
 )
 
 type Task struct {
-	ID        int       `json:"id" example:"1"`
-	Title     string    `json:"title" example:"Call John"`
-	Priority  int       `json:"priority" example:"1"`
-	Date      time.Time `json:"date" example:"2024-03-01T00:00:00Z"`
-	CreatedAt time.Time `json:"created_at" example:"2024-03-01T00:00:00Z"`
-	UpdatedAt time.Time `json:"updated_at" example:"2024-03-01T00:00:00Z"`
+	ID              int        `json:"id" example:"1"`
+	Title           string     `json:"title" example:"Call John"`
+	Priority        int        `json:"priority" example:"1"`
+	Date            time.Time  `json:"date" example:"2024-03-01T00:00:00Z"`
+	Status          TaskStatus `json:"status" example:"todo"`
+	CompletedAt     *time.Time `json:"completed_at" example:"2024-03-01T00:00:00Z"`
+	ParentID        *int       `json:"parent_id" example:"1"`
+	ProjectID       *int       `json:"project_id" example:"1"`
+	OwnerID         *int       `json:"owner_id" example:"1"`
+	Blocked         *bool      `json:"blocked,omitempty" example:"false"`
+	CommentCount    *int       `json:"comment_count,omitempty" example:"2"`
+	Recurrence      *string    `json:"recurrence" example:"FREQ=WEEKLY;BYDAY=MO"`
+	RecurrenceStart *time.Time `json:"-"` // RRULE DTSTART shared by every occurrence
+	CreatedAt       time.Time  `json:"created_at" example:"2024-03-01T00:00:00Z"`
+	UpdatedAt       time.Time  `json:"updated_at" example:"2024-03-01T00:00:00Z"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty" example:"2024-03-01T00:00:00Z"`
+	Version         int        `json:"version" example:"1"`
+	// ExternalID identifies an imported task in the tool it came from
+	ExternalID *string    `json:"external_id,omitempty" example:"0b6c4f2e@example.com"`
+	Categories Categories `json:"categories"`
+	Children   Tasks      `json:"children,omitempty"`
 }
 
 func (c *Task) toJSON() types.JSONResult {
@@ -32,14 +49,7 @@ func (q Tasks) Len() int {
 }
 
 func (c Tasks) ToJSON(pag types.Pageable) types.JSONResultWithPaginate {
-	pag.Calc()
-	return types.JSONResultWithPaginate{
-		Data:     c,
-		Code:     200,
-		Message:  "success",
-		Paginate: &pag,
-		Length:   len(c),
-	}
+	return pag.Result(c, len(c))
 }
 
 func (req *TaskURLParams) Parse(value string) error {
@@ -62,27 +72,95 @@ func (q Task) CreateTaskResponseDto() types.JSONResult {
 
 type TaskURLParams struct {
 	ID int `json:"id" example:"1" validate:"required"`
+	// IfMatch is the If-Match header of a write, checked with CheckVersion
+	IfMatch util.ETags `json:"-" swaggerignore:"true"`
 }
 
+// TaskRequestPayload holds the editable fields of a task. On update,
+// CategoryIDs replaces the categories when set, an empty list clears them.
 type TaskRequestPayload struct {
 	// ID       string    `json:"content"     example:"I am a quote"`
-	Title    string    `json:"title"   example:"Call John"`
-	Priority int       `json:"priority"   example:"1"`
-	Date     time.Time `json:"date" example:"2024-03-01T00:00:00Z"`
+	Title       string    `json:"title"   example:"Call John"`
+	Priority    int       `json:"priority"   example:"1"`
+	Date        time.Time `json:"date" example:"2024-03-01T00:00:00Z"`
+	ParentID    *int      `json:"parent_id" example:"1"`
+	ProjectID   *int      `json:"project_id" example:"1"`
+	Recurrence  *string   `json:"recurrence" example:"FREQ=WEEKLY;BYDAY=MO"`
+	CategoryIDs []int     `json:"category_ids" example:"1"`
+}
+
+// Payload returns the editable fields of the task, as patched by PATCH /tasks/{id}
+func (q Task) Payload() TaskRequestPayload {
+	categoryIDs := make([]int, len(q.Categories))
+	for i, category := range q.Categories {
+		categoryIDs[i] = category.ID
+	}
+
+	return TaskRequestPayload{
+		Title:       q.Title,
+		Priority:    q.Priority,
+		Date:        q.Date,
+		ParentID:    q.ParentID,
+		ProjectID:   q.ProjectID,
+		Recurrence:  q.Recurrence,
+		CategoryIDs: categoryIDs,
+	}
+}
+
+// Overwrite returns the update that sets every editable field to the
+// payload, including an empty list of categories
+func (p TaskRequestPayload) Overwrite() TaskRequestPayload {
+	if p.CategoryIDs == nil {
+		p.CategoryIDs = []int{}
+	}
+	return p
+}
+
+func (p TaskRequestPayload) Validate() error {
+	if err := ValidateCategoryIDs(p.CategoryIDs); err != nil {
+		return err
+	}
+	if p.ProjectID != nil && *p.ProjectID <= 0 {
+		return fmt.Errorf("error: \"project_id\" must be a number greater than 0, got %d", *p.ProjectID)
+	}
+	return ValidateRecurrence(p.Recurrence, p.Date)
+}
+
+// ParseCategoryIDs parses the comma separated "category" query parameter,
+// an empty value means no category filter
+func ParseCategoryIDs(value string) ([]int, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var ids []int
+	for _, part := range strings.Split(value, ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("error: \"category\" must be a comma separated list of ids, got %q", part)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// ValidateCategoryIDs rejects category ids that cannot exist
+func ValidateCategoryIDs(ids []int) error {
+	for _, id := range ids {
+		if id <= 0 {
+			return fmt.Errorf("error: \"category_ids\" must only contain numbers greater than 0, got %d", id)
+		}
+	}
+	return nil
 }
 
 // Len is the number of elements in the collection.
 
 // Less reports whether the element with
 func (q Tasks) CreateTaskResponseDto(pag *types.Pageable) types.JSONResultWithPaginate {
-	if pag.Total > 0 {
-		return types.JSONResultWithPaginate{
-			Message:  "success",
-			Code:     200,
-			Data:     q,
-			Length:   len(q),
-			Paginate: pag,
-		}
+	if pag != nil && (pag.Total > 0 || pag.NextCursor != "" || pag.PrevCursor != "") {
+		return pag.Result(q, len(q))
 	}
 
 	return types.JSONResultWithPaginate{
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+// MaxAttachmentSize is the largest file that can be attached to a task
+const MaxAttachmentSize = 25 << 20
+
+// TaskAttachment is the metadata of a file attached to a task,
+// its content lives in a storage.BlobStore under BlobKey
+type TaskAttachment struct {
+	ID          int       `json:"id" example:"1"`
+	TaskID      int       `json:"task_id" example:"1"`
+	Filename    string    `json:"filename" example:"screenshot.png"`
+	ContentType string    `json:"content_type" example:"image/png"`
+	Size        int64     `json:"size" example:"52311"`
+	SHA256      string    `json:"sha256" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	BlobKey     string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at" example:"2024-03-01T00:00:00Z"`
+}
+
+func (a TaskAttachment) ToJSON(code int, message string) types.JSONResult {
+	return types.JSONResult{
+		Data:    a,
+		Code:    code,
+		Message: message,
+	}
+}
+
+type TaskAttachments []TaskAttachment
+
+func (a TaskAttachments) ToJSON(pag types.Pageable) types.JSONResultWithPaginate {
+	return pag.Result(a, len(a))
+}
+
+// TaskAttachmentFields are the keys attachments are paginated by, oldest first
+var TaskAttachmentFields = TaskCommentFields
+
+// AttachmentUpload is the multipart/form-data payload of an upload, with the file in the "file" field
+type AttachmentUpload struct {
+	File *multipart.FileHeader
+}
+
+func (u *AttachmentUpload) DecodeMultipart(form *multipart.Form) error {
+	files := form.File["file"]
+	if len(files) != 1 {
+		return errors.New("error: exactly one \"file\" is required")
+	}
+
+	u.File = files[0]
+	return nil
+}
+
+func (u AttachmentUpload) Validate() error {
+	if u.File == nil {
+		return errors.New("error: \"file\" is required")
+	}
+
+	if u.File.Size == 0 {
+		return errors.New("error: \"file\" is empty")
+	}
+
+	if u.File.Size > MaxAttachmentSize {
+		return fmt.Errorf("error: \"file\" must be at most %d MB", MaxAttachmentSize>>20)
+	}
+
+	if u.Filename() == "" {
+		return errors.New("error: \"file\" needs a file name")
+	}
+
+	return nil
+}
+
+// Filename returns the base name of the uploaded file without control characters
+func (u AttachmentUpload) Filename() string {
+	name := filepath.Base(strings.ReplaceAll(u.File.Filename, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+
+	if name == "." || name == "/" {
+		return ""
+	}
+	return name
+}
+
+// ContentType returns the content type sent with the file, application/octet-stream when missing
+func (u AttachmentUpload) ContentType() string {
+	if contentType := u.File.Header.Get("Content-Type"); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"mime/multipart"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachmentUpload(t *testing.T) {
+	upload := AttachmentUpload{File: &multipart.FileHeader{
+		Filename: `C:\Users\me\shot.png`,
+		Size:     10,
+		Header:   textproto.MIMEHeader{},
+	}}
+
+	assert.NoError(t, upload.Validate())
+	assert.Equal(t, "shot.png", upload.Filename())
+	assert.Equal(t, "application/octet-stream", upload.ContentType())
+
+	upload.File.Filename = "../../etc/passwd"
+	assert.Equal(t, "passwd", upload.Filename())
+
+	upload.File.Size = MaxAttachmentSize + 1
+	assert.Error(t, upload.Validate())
+
+	assert.Error(t, AttachmentUpload{}.Validate())
+	assert.Error(t, (&AttachmentUpload{}).DecodeMultipart(&multipart.Form{}))
+}
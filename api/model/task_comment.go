@@ -0,0 +1,85 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Kbgjtn/notethingness-api.git/types"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+// DeletedCommentBody replaces the body of a soft-deleted comment
+const DeletedCommentBody = "[deleted]"
+
+var ErrCommentForbidden = errors.New("error: only the author of a comment or an owner of the workspace can change it")
+
+// TaskComment is a message posted on a task. Author is the user who posted
+// it; comments from before user accounts keep the author they were posted
+// as, and have no UserID.
+type TaskComment struct {
+	ID        int        `json:"id" example:"1"`
+	TaskID    int        `json:"task_id" example:"1"`
+	UserID    *int       `json:"user_id" example:"1"`
+	Author    Author     `json:"author"`
+	Body      string     `json:"body" example:"Called, waiting for an answer"`
+	CreatedAt time.Time  `json:"created_at" example:"2024-03-01T00:00:00Z"`
+	EditedAt  *time.Time `json:"edited_at" example:"2024-03-01T00:00:00Z"`
+	DeletedAt *time.Time `json:"deleted_at" example:"2024-03-01T00:00:00Z"`
+}
+
+// Redact hides the body of a deleted comment, keeping its place in the thread
+func (c *TaskComment) Redact() {
+	if c.DeletedAt != nil {
+		c.Body = DeletedCommentBody
+	}
+}
+
+func (c TaskComment) ToJSON(code int, message string) types.JSONResult {
+	return types.JSONResult{
+		Data:    c,
+		Code:    code,
+		Message: message,
+	}
+}
+
+type TaskComments []TaskComment
+
+func (c TaskComments) ToJSON(pag types.Pageable) types.JSONResultWithPaginate {
+	return pag.Result(c, len(c))
+}
+
+// TaskCommentFields are the keys comments are paginated by, oldest first
+var TaskCommentFields = util.FilterSchema{
+	"id": util.FieldInt,
+}
+
+// TaskCommentPayload is a new comment, posted as the authenticated user
+type TaskCommentPayload struct {
+	Body string `json:"body" example:"Called, waiting for an answer"`
+}
+
+func (p TaskCommentPayload) Validate() error {
+	return ValidateCommentBody(p.Body)
+}
+
+type TaskCommentUpdatePayload struct {
+	Body string `json:"body" example:"Called twice, waiting for an answer"`
+}
+
+func (p TaskCommentUpdatePayload) Validate() error {
+	return ValidateCommentBody(p.Body)
+}
+
+// ValidateCommentBody requires a non-blank body of at most 10000 characters
+func ValidateCommentBody(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return errors.New("error: body is required")
+	}
+
+	if len([]rune(body)) > 10000 {
+		return errors.New("error: body must be less than 10000 characters")
+	}
+
+	return nil
+}
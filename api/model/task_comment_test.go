@@ -0,0 +1,26 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskCommentRedact(t *testing.T) {
+	comment := TaskComment{Body: "secret"}
+	comment.Redact()
+	assert.Equal(t, "secret", comment.Body)
+
+	now := time.Now()
+	comment.DeletedAt = &now
+	comment.Redact()
+	assert.Equal(t, DeletedCommentBody, comment.Body)
+}
+
+func TestTaskCommentPayloadValidate(t *testing.T) {
+	assert.NoError(t, TaskCommentPayload{Body: "done?"}.Validate())
+	assert.Error(t, TaskCommentPayload{Body: "  "}.Validate())
+	assert.Error(t, TaskCommentUpdatePayload{Body: strings.Repeat("a", 10001)}.Validate())
+}
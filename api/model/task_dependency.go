@@ -0,0 +1,111 @@
+package model
+
+import (
+	"container/heap"
+	"errors"
+	"time"
+
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+// MaxExecutionOrder is the most tasks the execution order lists, blockers included
+const MaxExecutionOrder = 1000
+
+var (
+	ErrDependencyCycle       = errors.New("error: dependency would create a cycle")
+	ErrExecutionOrderTooLong = errors.New("error: too many tasks to order, narrow them down with status")
+)
+
+// TaskDependency records that TaskID is blocked by DependsOnID
+type TaskDependency struct {
+	TaskID      int       `json:"task_id" example:"2"`
+	DependsOnID int       `json:"depends_on_id" example:"1"`
+	CreatedAt   time.Time `json:"created_at" example:"2024-03-01T00:00:00Z"`
+}
+
+type TaskDependencyPayload struct {
+	DependsOnID int `json:"depends_on_id" example:"1"`
+}
+
+func (p TaskDependencyPayload) Validate(taskID int) error {
+	if p.DependsOnID <= 0 {
+		return errors.New("error: depends_on_id is required and must be a number greater than 0")
+	}
+
+	if p.DependsOnID == taskID {
+		return errors.New("error: a task cannot depend on itself")
+	}
+
+	return nil
+}
+
+func (d TaskDependency) CreateTaskDependencyResponseDto(code int, message string) types.JSONResult {
+	return types.JSONResult{
+		Data:    d,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// TopologicalOrder sorts tasks so that every task comes after the tasks it depends on.
+// Tasks that are ready at the same time keep their id order; edges pointing outside
+// of tasks are ignored. It returns ErrDependencyCycle when the graph is not acyclic.
+func (q Tasks) TopologicalOrder(edges []TaskDependency) (Tasks, error) {
+	byID := make(map[int]Task, len(q))
+	for _, task := range q {
+		byID[task.ID] = task
+	}
+
+	indegree := make(map[int]int, len(q))
+	dependents := make(map[int][]int)
+	for _, edge := range edges {
+		_, from := byID[edge.DependsOnID]
+		_, to := byID[edge.TaskID]
+		if !from || !to {
+			continue
+		}
+		dependents[edge.DependsOnID] = append(dependents[edge.DependsOnID], edge.TaskID)
+		indegree[edge.TaskID]++
+	}
+
+	ready := &idHeap{}
+	for id := range byID {
+		if indegree[id] == 0 {
+			heap.Push(ready, id)
+		}
+	}
+
+	ordered := make(Tasks, 0, len(byID))
+	for ready.Len() > 0 {
+		id := heap.Pop(ready).(int)
+		ordered = append(ordered, byID[id])
+
+		for _, next := range dependents[id] {
+			indegree[next]--
+			if indegree[next] == 0 {
+				heap.Push(ready, next)
+			}
+		}
+	}
+
+	if len(ordered) != len(byID) {
+		return nil, ErrDependencyCycle
+	}
+
+	return ordered, nil
+}
+
+// idHeap is a min-heap of task ids
+type idHeap []int
+
+func (h idHeap) Len() int            { return len(h) }
+func (h idHeap) Less(i, j int) bool  { return h[i] < h[j] }
+func (h idHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *idHeap) Push(x interface{}) { *h = append(*h, x.(int)) }
+
+func (h *idHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTasksTopologicalOrder(t *testing.T) {
+	tasks := Tasks{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}}
+	edges := []TaskDependency{
+		{TaskID: 1, DependsOnID: 3},
+		{TaskID: 3, DependsOnID: 4},
+		{TaskID: 2, DependsOnID: 9}, // 9 is outside of the list
+	}
+
+	ordered, err := tasks.TopologicalOrder(edges)
+	assert.NoError(t, err)
+
+	var ids []int
+	for _, task := range ordered {
+		ids = append(ids, task.ID)
+	}
+	assert.Equal(t, []int{2, 4, 3, 1}, ids)
+}
+
+func TestTasksTopologicalOrderCycle(t *testing.T) {
+	tasks := Tasks{{ID: 1}, {ID: 2}, {ID: 3}}
+	edges := []TaskDependency{
+		{TaskID: 1, DependsOnID: 2},
+		{TaskID: 2, DependsOnID: 3},
+		{TaskID: 3, DependsOnID: 1},
+	}
+
+	_, err := tasks.TopologicalOrder(edges)
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+}
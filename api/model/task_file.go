@@ -0,0 +1,411 @@
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TaskFileFormat is a file format of the task export and import
+type TaskFileFormat string
+
+const (
+	FormatCSV   TaskFileFormat = "csv"
+	FormatJSONL TaskFileFormat = "jsonl"
+)
+
+// ParseTaskFileFormat parses the "format" query parameter, csv by default
+func ParseTaskFileFormat(value string) (TaskFileFormat, error) {
+	switch format := TaskFileFormat(strings.ToLower(value)); format {
+	case "":
+		return FormatCSV, nil
+	case FormatCSV, FormatJSONL:
+		return format, nil
+	default:
+		return "", fmt.Errorf("error: \"format\" must be csv or jsonl, got %q", value)
+	}
+}
+
+// ContentType is the media type the format is exported as
+func (f TaskFileFormat) ContentType() string {
+	if f == FormatJSONL {
+		return "application/jsonl; charset=utf-8"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// MediaTypes are the media types a file of the format is accepted as
+func (f TaskFileFormat) MediaTypes() []string {
+	if f == FormatJSONL {
+		return []string{"application/jsonl", "application/x-ndjson", "application/x-jsonlines"}
+	}
+	return []string{"text/csv"}
+}
+
+// TaskFileFormatOf returns the format sent with a media type, "" when none matches
+func TaskFileFormatOf(mediaType string) TaskFileFormat {
+	for _, format := range []TaskFileFormat{FormatCSV, FormatJSONL} {
+		for _, accepted := range format.MediaTypes() {
+			if mediaType == accepted {
+				return format
+			}
+		}
+	}
+	return ""
+}
+
+// TaskCSVHeader is the header row of the CSV export, in CSVRecord order.
+// An export can be imported back as is: rows update the tasks of their id.
+var TaskCSVHeader = []string{
+	"id", "external_id", "title", "priority", "date", "status", "completed_at",
+	"parent_id", "project_id", "recurrence", "categories", "created_at", "updated_at",
+}
+
+// CSVRecord returns the task as a row of the CSV export, the labels of its
+// categories are joined with commas
+func (q Task) CSVRecord() []string {
+	labels := make([]string, len(q.Categories))
+	for i, category := range q.Categories {
+		labels[i] = category.Label
+	}
+
+	return []string{
+		strconv.Itoa(q.ID),
+		stringOrEmpty(q.ExternalID),
+		q.Title,
+		strconv.Itoa(q.Priority),
+		q.Date.UTC().Format(time.RFC3339),
+		string(q.Status),
+		timeOrEmpty(q.CompletedAt),
+		intOrEmpty(q.ParentID),
+		intOrEmpty(q.ProjectID),
+		stringOrEmpty(q.Recurrence),
+		strings.Join(labels, ","),
+		q.CreatedAt.UTC().Format(time.RFC3339),
+		q.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func intOrEmpty(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+func timeOrEmpty(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// ImportFields are the fields a row of an imported file can set, read from
+// the columns of the same name unless a ColumnMapping says otherwise
+var ImportFields = []string{
+	"id", "external_id", "title", "priority", "date", "status", "completed_at",
+	"parent_id", "parent_external_id", "project_id", "recurrence", "categories",
+}
+
+// ColumnMapping maps import fields to the columns of a file that hold them
+type ColumnMapping map[string]string
+
+// ParseColumnMapping parses the "mapping" query parameter, a comma separated
+// list of field=column pairs such as title=Task name,date=Due date
+func ParseColumnMapping(value string) (ColumnMapping, error) {
+	mapping := ColumnMapping{}
+	if strings.TrimSpace(value) == "" {
+		return mapping, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		field, column, ok := strings.Cut(pair, "=")
+		field, column = strings.TrimSpace(field), strings.TrimSpace(column)
+		if !ok || column == "" {
+			return nil, fmt.Errorf("error: \"mapping\" must be a comma separated list of field=column, got %q", pair)
+		}
+		if !isImportField(field) {
+			return nil, fmt.Errorf("error: \"mapping\" has unknown field %q, expected one of %s",
+				field, strings.Join(ImportFields, ", "))
+		}
+		if _, ok := mapping[field]; ok {
+			return nil, fmt.Errorf("error: \"mapping\" maps %q twice", field)
+		}
+		mapping[field] = column
+	}
+
+	return mapping, nil
+}
+
+func isImportField(field string) bool {
+	for _, f := range ImportFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Column returns the column holding a field
+func (m ColumnMapping) Column(field string) string {
+	if column, ok := m[field]; ok {
+		return column
+	}
+	return field
+}
+
+// TasksFromCSV reads a CSV file whose first row names the columns. A row
+// that cannot be mapped is kept with a Skip reason; an error is returned
+// only when the file is not CSV at all.
+func TasksFromCSV(r io.Reader, mapping ColumnMapping) ([]ImportedTask, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error: invalid CSV: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+	if len(header) > 0 {
+		// spreadsheets often start the file with a byte order mark
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	var tasks []ImportedTask
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return tasks, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error: invalid CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(row) != len(header) {
+			tasks = append(tasks, ImportedTask{
+				Line: line,
+				Skip: fmt.Sprintf("error: row has %d columns, the header has %d", len(row), len(header)),
+			})
+			continue
+		}
+
+		record := make(map[string]string, len(row))
+		for i, value := range row {
+			record[header[i]] = value
+		}
+
+		task := mapping.task(record)
+		task.Line = line
+		tasks = append(tasks, task)
+	}
+}
+
+// TasksFromJSONL reads a JSON Lines file of objects, such as the JSONL export.
+// Blank lines are ignored, a line that is not an object is kept with a Skip reason.
+func TasksFromJSONL(r io.Reader, mapping ColumnMapping) ([]ImportedTask, error) {
+	reader := bufio.NewReader(r)
+
+	var tasks []ImportedTask
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			task := ImportedTask{Line: line}
+
+			var object map[string]json.RawMessage
+			if decodeErr := json.Unmarshal(data, &object); decodeErr != nil || object == nil {
+				task.Skip = "error: line is not a JSON object"
+			} else if record, recordErr := mapping.jsonRecord(object); recordErr != nil {
+				task.Skip = recordErr.Error()
+			} else {
+				task = mapping.task(record)
+				task.Line = line
+			}
+			tasks = append(tasks, task)
+		}
+
+		if errors.Is(err, io.EOF) {
+			return tasks, nil
+		}
+	}
+}
+
+// jsonRecord turns the members of an object that hold import fields into the
+// text of CSV cells: null is empty and arrays are joined with commas, their
+// objects by "label" so that the categories of the JSONL export read as labels
+func (m ColumnMapping) jsonRecord(object map[string]json.RawMessage) (map[string]string, error) {
+	record := make(map[string]string, len(ImportFields))
+	for _, field := range ImportFields {
+		column := m.Column(field)
+		raw, ok := object[column]
+		if !ok {
+			continue
+		}
+
+		text, err := jsonText(raw, true)
+		if err != nil {
+			return nil, fmt.Errorf("error: %q %s", column, err)
+		}
+		record[column] = text
+	}
+	return record, nil
+}
+
+func jsonText(raw json.RawMessage, nested bool) (string, error) {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", err
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []interface{}:
+		if !nested {
+			return "", errors.New("must not nest arrays")
+		}
+		var items []json.RawMessage
+		json.Unmarshal(raw, &items)
+		texts := make([]string, len(items))
+		for i, item := range items {
+			var err error
+			if texts[i], err = jsonText(item, false); err != nil {
+				return "", err
+			}
+		}
+		return strings.Join(texts, ","), nil
+	case map[string]interface{}:
+		if label, ok := v["label"].(string); ok && !nested {
+			return label, nil
+		}
+		return "", errors.New("must not be an object")
+	default:
+		return strings.TrimSpace(string(raw)), nil
+	}
+}
+
+// task maps the cells of a row onto a task to import, by column name
+func (m ColumnMapping) task(record map[string]string) ImportedTask {
+	var task ImportedTask
+	row := importRow{record: record, mapping: m}
+
+	task.ID = row.int("id")
+	task.ExternalID = row.text("external_id")
+	task.Payload.Title = row.text("title")
+	task.Payload.Priority = row.int("priority")
+	if date := row.time("date"); date != nil {
+		task.Payload.Date = *date
+	}
+	if recurrence := row.text("recurrence"); recurrence != "" {
+		task.Payload.Recurrence = &recurrence
+	}
+	if status := row.text("status"); status != "" {
+		task.Status = TaskStatus(status)
+		if !task.Status.Valid() {
+			row.fail("status", "must be one of todo, in_progress, blocked, done, cancelled")
+		}
+	}
+	task.CompletedAt = row.time("completed_at")
+	task.ParentID = row.int("parent_id")
+	task.ParentExternalID = row.text("parent_external_id")
+	if project := row.int("project_id"); project > 0 {
+		task.Payload.ProjectID = &project
+	}
+
+	if categories, ok := row.cell("categories"); ok {
+		task.Categories = []string{}
+		for _, label := range strings.Split(categories, ",") {
+			if label = strings.TrimSpace(label); label != "" {
+				task.Categories = append(task.Categories, label)
+			}
+		}
+	}
+
+	switch {
+	case row.err != nil:
+		task.Skip = row.err.Error()
+	case task.Payload.Title == "":
+		task.Skip = fmt.Sprintf("error: %q is required", m.Column("title"))
+	case task.Payload.Date.IsZero():
+		task.Skip = fmt.Sprintf("error: %q is required", m.Column("date"))
+	case len(task.ExternalID) > 255:
+		task.Skip = fmt.Sprintf("error: %q must be at most 255 characters", m.Column("external_id"))
+	}
+
+	return task
+}
+
+// importRow reads the fields of a row, keeping the first invalid cell as err
+type importRow struct {
+	record  map[string]string
+	mapping ColumnMapping
+	err     error
+}
+
+func (r *importRow) cell(field string) (string, bool) {
+	value, ok := r.record[r.mapping.Column(field)]
+	return strings.TrimSpace(value), ok
+}
+
+func (r *importRow) text(field string) string {
+	value, _ := r.cell(field)
+	return value
+}
+
+func (r *importRow) int(field string) int {
+	value := r.text(field)
+	if value == "" {
+		return 0
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		r.fail(field, fmt.Sprintf("must be a positive number, got %q", value))
+	}
+	return n
+}
+
+func (r *importRow) time(field string) *time.Time {
+	value := r.text(field)
+	if value == "" {
+		return nil
+	}
+
+	t, err := parseTime(value)
+	if err != nil {
+		r.fail(field, fmt.Sprintf("must be a date or RFC 3339 timestamp, got %q", value))
+		return nil
+	}
+	return &t
+}
+
+func (r *importRow) fail(field, message string) {
+	if r.err == nil {
+		r.err = fmt.Errorf("error: %q %s", r.mapping.Column(field), message)
+	}
+}
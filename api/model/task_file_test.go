@@ -0,0 +1,121 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTaskFileFormat(t *testing.T) {
+	format, err := ParseTaskFileFormat("")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatCSV, format)
+
+	format, err = ParseTaskFileFormat("JSONL")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatJSONL, format)
+
+	_, err = ParseTaskFileFormat("xlsx")
+	assert.Error(t, err)
+
+	assert.Equal(t, FormatJSONL, TaskFileFormatOf("application/x-ndjson"))
+	assert.Equal(t, TaskFileFormat(""), TaskFileFormatOf("application/json"))
+}
+
+func TestParseColumnMapping(t *testing.T) {
+	mapping, err := ParseColumnMapping("title=Task name, date = Due date")
+	assert.NoError(t, err)
+	assert.Equal(t, "Task name", mapping.Column("title"))
+	assert.Equal(t, "Due date", mapping.Column("date"))
+	assert.Equal(t, "priority", mapping.Column("priority"))
+
+	for _, value := range []string{"title", "title=", "owner=Owner", "title=A,title=B"} {
+		_, err := ParseColumnMapping(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestTasksFromCSV(t *testing.T) {
+	mapping, _ := ParseColumnMapping("title=Task,date=Due")
+	file := "\ufeffTask,Due,priority,status,categories,notes\n" +
+		"Call John,2024-03-01,2,done,\"work, calls\",ignored\n" +
+		"Pay rent,2024-03-02T09:00:00Z,high,,,\n" +
+		",2024-03-03,,,,\n" +
+		"Too short\n" +
+		"Water plants,2024-03-04,,asleep,,\n"
+
+	tasks, err := TasksFromCSV(strings.NewReader(file), mapping)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 5)
+
+	assert.Equal(t, 2, tasks[0].Line)
+	assert.Equal(t, "Call John", tasks[0].Payload.Title)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), tasks[0].Payload.Date)
+	assert.Equal(t, 2, tasks[0].Payload.Priority)
+	assert.Equal(t, StatusDone, tasks[0].Status)
+	assert.Equal(t, []string{"work", "calls"}, tasks[0].Categories)
+	assert.Empty(t, tasks[0].Skip)
+
+	assert.Equal(t, `error: "priority" must be a positive number, got "high"`, tasks[1].Skip)
+	assert.Equal(t, `error: "Task" is required`, tasks[2].Skip)
+	assert.Contains(t, tasks[3].Skip, "columns")
+	assert.Contains(t, tasks[4].Skip, `"status"`)
+
+	_, err = TasksFromCSV(strings.NewReader("title\n\"unterminated\n"), mapping)
+	assert.Error(t, err)
+}
+
+func TestTasksFromJSONL(t *testing.T) {
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	parent, project := 1, 3
+	exported := Task{
+		ID: 2, Title: "Call John", Priority: 2, Date: at, Status: StatusTodo, ParentID: &parent, ProjectID: &project,
+		Categories: Categories{{ID: 1, Label: "work"}},
+	}
+	line, _ := json.Marshal(exported)
+
+	file := bytes.NewBuffer(line)
+	file.WriteString("\n\n[1, 2]\n")
+	file.WriteString(`{"title": "Pay rent", "date": "2024-03-02", "categories": null}`)
+
+	tasks, err := TasksFromJSONL(file, ColumnMapping{})
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 3)
+
+	assert.Equal(t, 1, tasks[0].Line)
+	assert.Equal(t, 2, tasks[0].ID)
+	assert.Equal(t, "Call John", tasks[0].Payload.Title)
+	assert.Equal(t, at, tasks[0].Payload.Date)
+	assert.Equal(t, 1, tasks[0].ParentID)
+	assert.Equal(t, &project, tasks[0].Payload.ProjectID)
+	assert.Equal(t, []string{"work"}, tasks[0].Categories)
+	assert.Empty(t, tasks[0].Skip)
+
+	assert.Equal(t, 3, tasks[1].Line)
+	assert.NotEmpty(t, tasks[1].Skip)
+
+	assert.Equal(t, 4, tasks[2].Line)
+	assert.Nil(t, tasks[2].Payload.ProjectID)
+	assert.Equal(t, []string{}, tasks[2].Categories)
+	assert.Empty(t, tasks[2].Skip)
+}
+
+func TestTaskCSVRecord(t *testing.T) {
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	project := 3
+	task := Task{
+		ID: 2, Title: "Call John", Priority: 2, Date: at, Status: StatusDone, CompletedAt: &at, ProjectID: &project,
+		CreatedAt: at, UpdatedAt: at, Categories: Categories{{ID: 1, Label: "work"}, {ID: 2, Label: "calls"}},
+	}
+
+	record := task.CSVRecord()
+	assert.Len(t, record, len(TaskCSVHeader))
+	assert.Equal(t, []string{
+		"2", "", "Call John", "2", "2024-03-01T09:00:00Z", "done", "2024-03-01T09:00:00Z",
+		"", "3", "", "work,calls", "2024-03-01T09:00:00Z", "2024-03-01T09:00:00Z",
+	}, record)
+}
@@ -0,0 +1,257 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Kbgjtn/notethingness-api.git/ical"
+)
+
+// TaskUIDDomain ends the iCalendar UID of every task, e.g. task-1@notethingness
+const TaskUIDDomain = "notethingness"
+
+// ICalProductID identifies this API as the producer of calendars
+const ICalProductID = "-//notethingness//tasks//EN"
+
+// TaskUID is the iCalendar UID of a task, stable for as long as the task exists
+func TaskUID(id int) string {
+	return "task-" + strconv.Itoa(id) + "@" + TaskUIDDomain
+}
+
+// ICalPriority maps a priority, where higher is more important, to the iCalendar
+// scale where 1 is the highest and 9 the lowest: 1 maps to 9 and 9 or more to 1.
+// Priorities below 1 are undefined (0).
+func ICalPriority(priority int) int {
+	switch {
+	case priority < 1:
+		return 0
+	case priority > 9:
+		return 1
+	default:
+		return 10 - priority
+	}
+}
+
+// ICalStatus maps a status to the STATUS of a VTODO, which has no blocked state
+func ICalStatus(status TaskStatus) string {
+	switch status {
+	case StatusInProgress:
+		return "IN-PROCESS"
+	case StatusDone:
+		return "COMPLETED"
+	case StatusCancelled:
+		return "CANCELLED"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+// VTODO renders the task as an iCalendar to-do
+func (q Task) VTODO() ical.Component {
+	todo := ical.Component{Name: "VTODO"}
+	todo.Add("UID", TaskUID(q.ID))
+	todo.Add("DTSTAMP", ical.FormatDateTime(q.UpdatedAt))
+	todo.Add("CREATED", ical.FormatDateTime(q.CreatedAt))
+	todo.Add("LAST-MODIFIED", ical.FormatDateTime(q.UpdatedAt))
+	if q.Version > 0 {
+		todo.Add("SEQUENCE", strconv.Itoa(q.Version-1))
+	}
+	todo.AddText("SUMMARY", q.Title)
+	todo.Add("PRIORITY", strconv.Itoa(ICalPriority(q.Priority)))
+	todo.Add("STATUS", ICalStatus(q.Status))
+
+	if q.Recurrence != nil && q.RecurrenceStart != nil {
+		// DUE of a recurring to-do is its next occurrence, DTSTART anchors the rule
+		todo.Add("DTSTART", ical.FormatDateTime(*q.RecurrenceStart))
+		todo.Add("RRULE", *q.Recurrence)
+	}
+	todo.Add("DUE", ical.FormatDateTime(q.Date))
+
+	if q.CompletedAt != nil {
+		todo.Add("COMPLETED", ical.FormatDateTime(*q.CompletedAt))
+		todo.Add("PERCENT-COMPLETE", "100")
+	}
+
+	if len(q.Categories) > 0 {
+		labels := make([]string, len(q.Categories))
+		for i, category := range q.Categories {
+			labels[i] = ical.EscapeText(category.Label)
+		}
+		todo.Add("CATEGORIES", strings.Join(labels, ","))
+	}
+
+	if q.ParentID != nil {
+		todo.Add("RELATED-TO", TaskUID(*q.ParentID), "RELTYPE", "PARENT")
+	}
+
+	return todo
+}
+
+// ICalendar renders the tasks as a calendar of to-dos named name
+func (q Tasks) ICalendar(name string) ical.Component {
+	calendar := ical.Component{Name: "VCALENDAR"}
+	calendar.Add("VERSION", "2.0")
+	calendar.Add("PRODID", ICalProductID)
+	calendar.Add("CALSCALE", "GREGORIAN")
+	calendar.AddText("X-WR-CALNAME", name)
+
+	for _, task := range q {
+		calendar.Components = append(calendar.Components, task.VTODO())
+	}
+
+	return calendar
+}
+
+// ParseTaskUID returns the id of a task from its TaskUID
+func ParseTaskUID(uid string) (int, bool) {
+	id, ok := strings.CutSuffix(uid, "@"+TaskUIDDomain)
+	if !ok {
+		return 0, false
+	}
+	id, ok = strings.CutPrefix(id, "task-")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(id)
+	return n, err == nil && n > 0
+}
+
+// PriorityFromICal reverses ICalPriority: 0 stays undefined, 1 becomes 9 and 9 becomes 1
+func PriorityFromICal(priority int) int {
+	if priority < 1 || priority > 9 {
+		return 0
+	}
+	return 10 - priority
+}
+
+// StatusFromICal maps the STATUS of a VTODO or VEVENT, "" when it has no equivalent
+func StatusFromICal(status string) TaskStatus {
+	switch strings.ToUpper(status) {
+	case "NEEDS-ACTION", "TENTATIVE", "CONFIRMED":
+		return StatusTodo
+	case "IN-PROCESS":
+		return StatusInProgress
+	case "COMPLETED":
+		return StatusDone
+	case "CANCELLED":
+		return StatusCancelled
+	default:
+		return ""
+	}
+}
+
+// TasksFromICal maps the VTODO and VEVENT components of decoded calendars
+// onto tasks to import. Components that cannot be mapped are kept with a
+// Skip reason so that they are reported.
+func TasksFromICal(calendars []ical.Component) []ImportedTask {
+	var tasks []ImportedTask
+
+	var walk func(components []ical.Component)
+	walk = func(components []ical.Component) {
+		for _, component := range components {
+			switch component.Name {
+			case "VTODO", "VEVENT":
+				task, err := TaskFromICal(component)
+				if err != nil {
+					task.Skip = err.Error()
+				}
+				tasks = append(tasks, task)
+			case "VCALENDAR":
+				walk(component.Components)
+			}
+		}
+	}
+	walk(calendars)
+
+	return tasks
+}
+
+// TaskFromICal maps a VTODO or VEVENT onto a task. A to-do is due at DUE, an
+// event at DTSTART; either falls back to DTSTAMP.
+func TaskFromICal(c ical.Component) (ImportedTask, error) {
+	var task ImportedTask
+
+	uid, _ := c.Get("UID")
+	if id, ok := ParseTaskUID(uid.Value); ok {
+		task.ID = id
+	} else {
+		task.ExternalID = uid.Value
+	}
+	task.Payload.Title = strings.TrimSpace(c.Text("SUMMARY"))
+
+	if _, ok := c.Get("RECURRENCE-ID"); ok {
+		return task, fmt.Errorf("error: a change to a single occurrence of a recurring %s is not imported", c.Name)
+	}
+	if task.Payload.Title == "" {
+		return task, fmt.Errorf("error: SUMMARY is missing")
+	}
+
+	dates := []string{"DUE", "DTSTART", "DTSTAMP"}
+	if c.Name == "VEVENT" {
+		dates = dates[1:]
+	}
+	for _, name := range dates {
+		if property, ok := c.Get(name); ok {
+			date, err := property.Time()
+			if err != nil {
+				return task, fmt.Errorf("error: %s", err)
+			}
+			task.Payload.Date = date
+			break
+		}
+	}
+	if task.Payload.Date.IsZero() {
+		return task, fmt.Errorf("error: %s has no date", c.Name)
+	}
+
+	if property, ok := c.Get("PRIORITY"); ok {
+		priority, err := strconv.Atoi(property.Value)
+		if err != nil || priority < 0 || priority > 9 {
+			return task, fmt.Errorf("error: PRIORITY must be a number from 0 to 9, got %q", property.Value)
+		}
+		task.Payload.Priority = PriorityFromICal(priority)
+	}
+
+	if property, ok := c.Get("RRULE"); ok {
+		start := task.Payload.Date
+		if dtstart, ok := c.Get("DTSTART"); ok {
+			if start, _ = dtstart.Time(); start.IsZero() {
+				start = task.Payload.Date
+			}
+		}
+		rule := property.Value
+		if err := ValidateRecurrence(&rule, start); err != nil {
+			return task, err
+		}
+		task.Payload.Recurrence = &rule
+	}
+
+	task.Status = StatusFromICal(c.Text("STATUS"))
+	if property, ok := c.Get("COMPLETED"); ok {
+		if completed, err := property.Time(); err == nil {
+			task.CompletedAt = &completed
+			if task.Status == "" {
+				task.Status = StatusDone
+			}
+		}
+	}
+
+	for _, property := range c.Properties {
+		switch property.Name {
+		case "CATEGORIES":
+			task.Categories = append(task.Categories, ical.SplitText(property.Value)...)
+		case "RELATED-TO":
+			if reltype := property.Params["RELTYPE"]; reltype != "" && !strings.EqualFold(reltype, "PARENT") {
+				continue
+			}
+			if id, ok := ParseTaskUID(property.Value); ok {
+				task.ParentID = id
+			} else {
+				task.ParentExternalID = property.Value
+			}
+		}
+	}
+
+	return task, nil
+}
@@ -0,0 +1,139 @@
+package model
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kbgjtn/notethingness-api.git/ical"
+)
+
+func TestICalPriority(t *testing.T) {
+	assert.Equal(t, 0, ICalPriority(0))
+	assert.Equal(t, 9, ICalPriority(1))
+	assert.Equal(t, 5, ICalPriority(5))
+	assert.Equal(t, 1, ICalPriority(9))
+	assert.Equal(t, 1, ICalPriority(123))
+}
+
+func TestTaskVTODO(t *testing.T) {
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	parent := 1
+	rule := "FREQ=WEEKLY;BYDAY=MO"
+	task := Task{
+		ID: 2, Title: "Call John, then Jane", Priority: 2, Date: at, Status: StatusDone,
+		CompletedAt: &at, ParentID: &parent, Recurrence: &rule, RecurrenceStart: &at,
+		CreatedAt: at, UpdatedAt: at, Version: 3,
+		Categories: Categories{{ID: 1, Label: "work"}, {ID: 2, Label: "calls"}},
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, ical.Encode(&out, task.VTODO()))
+	assert.Equal(t, "BEGIN:VTODO\r\n"+
+		"UID:task-2@notethingness\r\n"+
+		"DTSTAMP:20240301T090000Z\r\n"+
+		"CREATED:20240301T090000Z\r\n"+
+		"LAST-MODIFIED:20240301T090000Z\r\n"+
+		"SEQUENCE:2\r\n"+
+		`SUMMARY:Call John\, then Jane`+"\r\n"+
+		"PRIORITY:8\r\n"+
+		"STATUS:COMPLETED\r\n"+
+		"DTSTART:20240301T090000Z\r\n"+
+		"RRULE:FREQ=WEEKLY;BYDAY=MO\r\n"+
+		"DUE:20240301T090000Z\r\n"+
+		"COMPLETED:20240301T090000Z\r\n"+
+		"PERCENT-COMPLETE:100\r\n"+
+		"CATEGORIES:work,calls\r\n"+
+		"RELATED-TO;RELTYPE=PARENT:task-1@notethingness\r\n"+
+		"END:VTODO\r\n", out.String())
+}
+
+func TestParseTaskUID(t *testing.T) {
+	id, ok := ParseTaskUID(TaskUID(12))
+	assert.True(t, ok)
+	assert.Equal(t, 12, id)
+
+	for _, uid := range []string{"", "task-0@notethingness", "task-x@notethingness", "task-1@example.com", "1@notethingness"} {
+		_, ok := ParseTaskUID(uid)
+		assert.False(t, ok, uid)
+	}
+}
+
+func TestPriorityFromICal(t *testing.T) {
+	assert.Equal(t, 0, PriorityFromICal(0))
+	assert.Equal(t, 9, PriorityFromICal(1))
+	assert.Equal(t, 1, PriorityFromICal(9))
+	for priority := 1; priority <= 9; priority++ {
+		assert.Equal(t, priority, ICalPriority(PriorityFromICal(priority)))
+	}
+}
+
+func TestTasksFromICal(t *testing.T) {
+	calendars, err := ical.Decode(bytes.NewBufferString("BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:task-2@notethingness\r\n" +
+		`SUMMARY:Call John\, then Jane` + "\r\n" +
+		"DUE:20240301T090000Z\r\n" +
+		"PRIORITY:2\r\n" +
+		"STATUS:COMPLETED\r\n" +
+		"COMPLETED:20240302T100000Z\r\n" +
+		"CATEGORIES:work,calls\r\n" +
+		"CATEGORIES:home\r\n" +
+		"RELATED-TO;RELTYPE=PARENT:abc@example.com\r\n" +
+		"RELATED-TO;RELTYPE=SIBLING:task-9@notethingness\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:abc@example.com\r\n" +
+		"SUMMARY:Standup\r\n" +
+		"DTSTART;VALUE=DATE:20240304\r\n" +
+		"RRULE:FREQ=WEEKLY;BYDAY=MO\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:abc@example.com\r\n" +
+		"RECURRENCE-ID:20240311\r\n" +
+		"SUMMARY:Standup, moved\r\n" +
+		"DTSTART;VALUE=DATE:20240312\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:no-summary\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:bad-priority\r\n" +
+		"SUMMARY:Pay rent\r\n" +
+		"DTSTAMP:20240301T090000Z\r\n" +
+		"PRIORITY:high\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"))
+	assert.NoError(t, err)
+
+	tasks := TasksFromICal(calendars)
+	assert.Len(t, tasks, 5)
+
+	todo := tasks[0]
+	completed := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, 2, todo.ID)
+	assert.Empty(t, todo.ExternalID)
+	assert.Equal(t, "Call John, then Jane", todo.Payload.Title)
+	assert.Equal(t, time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), todo.Payload.Date)
+	assert.Equal(t, 8, todo.Payload.Priority)
+	assert.Equal(t, StatusDone, todo.Status)
+	assert.Equal(t, &completed, todo.CompletedAt)
+	assert.Equal(t, []string{"work", "calls", "home"}, todo.Categories)
+	assert.Equal(t, "abc@example.com", todo.ParentExternalID)
+	assert.Zero(t, todo.ParentID)
+	assert.Empty(t, todo.Skip)
+
+	event := tasks[1]
+	assert.Equal(t, "abc@example.com", event.ExternalID)
+	assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), event.Payload.Date)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO", *event.Payload.Recurrence)
+	assert.Empty(t, event.Status)
+	assert.False(t, event.HasParent())
+	assert.Empty(t, event.Skip)
+
+	assert.Contains(t, tasks[2].Skip, "single occurrence")
+	assert.Contains(t, tasks[3].Skip, "SUMMARY")
+	assert.Contains(t, tasks[4].Skip, "PRIORITY")
+}
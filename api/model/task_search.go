@@ -0,0 +1,163 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/Kbgjtn/notethingness-api.git/types"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+var ErrEmptySearch = errors.New("error: search query \"q\" is required")
+
+// TaskSearchResult is a task matching a full-text search
+type TaskSearchResult struct {
+	Task
+	Rank float64 `json:"rank" example:"0.1"`
+	// Snippet is the title as HTML, escaped, with the matching words in <mark> tags
+	Snippet string `json:"snippet" example:"<mark>Call</mark> John"`
+}
+
+type TaskSearchResults []TaskSearchResult
+
+// TaskSearchSort orders search results, best matches first
+var TaskSearchSort = []util.SortField{{Field: "rank", Desc: true}}
+
+// TaskSearchFields are the keys search results are paginated by
+var TaskSearchFields = util.FilterSchema{
+	"rank": util.FieldFloat,
+	"id":   util.FieldInt,
+}
+
+func (q TaskSearchResults) ToJSON(pag types.Pageable) types.JSONResultWithPaginate {
+	return pag.Result(q, len(q))
+}
+
+// ParseSearchQuery converts a user search into a Postgres tsquery expression.
+//
+//	call john       both words (call & john)
+//	"call john"     phrase (call <-> john)
+//	call*           prefix (call:*)
+//	call or email   either word (call | email)
+//	-john           exclude a word (!john)
+//
+// Anything but letters and digits is dropped from the words, so the result is
+// always a valid input for to_tsquery.
+func ParseSearchQuery(q string) (string, error) {
+	var terms []string
+	operator := " & "
+
+	for _, token := range tokenizeSearch(q) {
+		if !token.phrase && strings.EqualFold(token.text, "or") {
+			if len(terms) > 0 {
+				operator = " | "
+			}
+			continue
+		}
+
+		term := searchTerm(token)
+		if term == "" {
+			continue
+		}
+
+		if len(terms) > 0 {
+			terms = append(terms, operator)
+		}
+		terms = append(terms, term)
+		operator = " & "
+	}
+
+	if len(terms) == 0 {
+		return "", ErrEmptySearch
+	}
+
+	return strings.Join(terms, ""), nil
+}
+
+type searchToken struct {
+	text   string
+	phrase bool
+	negate bool
+}
+
+func tokenizeSearch(q string) []searchToken {
+	var tokens []searchToken
+	runes := []rune(strings.TrimSpace(q))
+
+	for i := 0; i < len(runes); i++ {
+		if unicode.IsSpace(runes[i]) {
+			continue
+		}
+
+		var token searchToken
+		if runes[i] == '-' {
+			token.negate = true
+			i++
+		}
+
+		if i < len(runes) && runes[i] == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			token.text = string(runes[i+1 : end])
+			token.phrase = true
+			i = end
+		} else {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) {
+				end++
+			}
+			token.text = string(runes[i:end])
+			i = end
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens
+}
+
+func searchTerm(token searchToken) string {
+	var term string
+
+	if token.phrase {
+		var words []string
+		for _, word := range strings.Fields(token.text) {
+			if word = searchWord(word); word != "" {
+				words = append(words, word)
+			}
+		}
+		if len(words) == 0 {
+			return ""
+		}
+		term = strings.Join(words, " <-> ")
+		if len(words) > 1 {
+			term = "(" + term + ")"
+		}
+	} else {
+		term = searchWord(token.text)
+		if term == "" {
+			return ""
+		}
+		if strings.HasSuffix(token.text, "*") {
+			term += ":*"
+		}
+	}
+
+	if token.negate {
+		term = "!" + term
+	}
+
+	return term
+}
+
+func searchWord(word string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, word)
+}
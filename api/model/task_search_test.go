@@ -0,0 +1,31 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	cases := map[string]string{
+		"call john":            "call & john",
+		`"call john" email`:    "(call <-> john) & email",
+		"invoi*":               "invoi:*",
+		"call or email":        "call | email",
+		"review -weekly":       "review & !weekly",
+		`-"monthly invoice"`:   "!(monthly <-> invoice)",
+		"it's 100% done!":      "its & 100 & done",
+		"drop'); DELETE --":    "drop & delete",
+		`"unterminated phrase`: "(unterminated <-> phrase)",
+		"OR leading or":        "leading",
+	}
+
+	for input, expected := range cases {
+		tsquery, err := ParseSearchQuery(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, tsquery, input)
+	}
+
+	_, err := ParseSearchQuery("  ** -- ")
+	assert.ErrorIs(t, err, ErrEmptySearch)
+}
@@ -0,0 +1,143 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+// TaskStatus represents a step in the task lifecycle
+type TaskStatus string
+
+const (
+	StatusTodo       TaskStatus = "todo"
+	StatusInProgress TaskStatus = "in_progress"
+	StatusBlocked    TaskStatus = "blocked"
+	StatusDone       TaskStatus = "done"
+	StatusCancelled  TaskStatus = "cancelled"
+)
+
+// statusOpen is a filter alias for every status that still needs work
+const statusOpen = "open"
+
+var ErrInvalidTransition = errors.New("error: invalid status transition")
+
+// transitions lists the statuses a task may move to from a given status.
+// Finished tasks (done, cancelled) can only be reopened back to todo.
+var transitions = map[TaskStatus][]TaskStatus{
+	StatusTodo:       {StatusInProgress, StatusBlocked, StatusDone, StatusCancelled},
+	StatusInProgress: {StatusTodo, StatusBlocked, StatusDone, StatusCancelled},
+	StatusBlocked:    {StatusTodo, StatusInProgress, StatusCancelled},
+	StatusDone:       {StatusTodo},
+	StatusCancelled:  {StatusTodo},
+}
+
+// OpenStatuses returns the statuses of tasks that are not finished yet
+func OpenStatuses() []TaskStatus {
+	return []TaskStatus{StatusTodo, StatusInProgress, StatusBlocked}
+}
+
+func (s TaskStatus) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// Finished reports whether s ends the task lifecycle
+func (s TaskStatus) Finished() bool {
+	return s == StatusDone || s == StatusCancelled
+}
+
+// CanTransition reports whether a task in status s may move to status to
+func (s TaskStatus) CanTransition(to TaskStatus) bool {
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition returns ErrInvalidTransition when moving from s to "to" is not allowed
+func (s TaskStatus) Transition(to TaskStatus) error {
+	if !s.CanTransition(to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, s, to)
+	}
+	return nil
+}
+
+// ParseTaskStatuses parses a comma separated list of statuses, e.g. "todo,blocked".
+// The alias "open" expands to every unfinished status.
+func ParseTaskStatuses(value string) ([]TaskStatus, error) {
+	var statuses []TaskStatus
+	if value == "" {
+		return statuses, nil
+	}
+
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if v == statusOpen {
+			statuses = append(statuses, OpenStatuses()...)
+			continue
+		}
+
+		status := TaskStatus(v)
+		if !status.Valid() {
+			return nil, fmt.Errorf("error: unknown status %q", v)
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+type TaskTransitionPayload struct {
+	Status TaskStatus `json:"status" example:"done"`
+}
+
+func (p TaskTransitionPayload) Validate() error {
+	if p.Status == "" {
+		return errors.New("error: status is required")
+	}
+
+	if !p.Status.Valid() {
+		return fmt.Errorf("error: unknown status %q", p.Status)
+	}
+
+	return nil
+}
+
+// TaskFilter narrows down the tasks returned by a list query
+type TaskFilter struct {
+	Statuses []TaskStatus
+	ParentID *int
+	// RootsOnly keeps tasks without a parent
+	RootsOnly bool
+	// Trashed lists the tasks in the trash instead of the others
+	Trashed bool
+	// CategoryIDs keeps tasks tagged with any of the categories
+	CategoryIDs []int
+	// ProjectID keeps the tasks of a project
+	ProjectID *int
+	// Expr and Sort come from the "filter" and "sort" query parameters
+	Expr util.FilterNode
+	Sort []util.SortField
+}
+
+// TaskFields whitelists the task fields of the "filter" and "sort" query parameters
+var TaskFields = util.FilterSchema{
+	"id":           util.FieldInt,
+	"title":        util.FieldString,
+	"priority":     util.FieldInt,
+	"date":         util.FieldTime,
+	"status":       util.FieldString,
+	"completed_at": util.FieldTime,
+	"parent_id":    util.FieldInt,
+	"project_id":   util.FieldInt,
+	"owner_id":     util.FieldInt,
+	"recurrence":   util.FieldString,
+	"created_at":   util.FieldTime,
+	"updated_at":   util.FieldTime,
+	"deleted_at":   util.FieldTime,
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskStatusTransition(t *testing.T) {
+	assert.NoError(t, StatusTodo.Transition(StatusInProgress))
+	assert.NoError(t, StatusInProgress.Transition(StatusDone))
+	assert.NoError(t, StatusDone.Transition(StatusTodo), "done tasks can be reopened")
+
+	err := StatusDone.Transition(StatusInProgress)
+	assert.True(t, errors.Is(err, ErrInvalidTransition))
+	assert.Error(t, StatusBlocked.Transition(StatusDone), "blocked tasks must be unblocked first")
+	assert.Error(t, StatusTodo.Transition(StatusTodo))
+}
+
+func TestParseTaskStatuses(t *testing.T) {
+	statuses, err := ParseTaskStatuses("open")
+	assert.NoError(t, err)
+	assert.Equal(t, OpenStatuses(), statuses)
+
+	statuses, err = ParseTaskStatuses("done, cancelled")
+	assert.NoError(t, err)
+	assert.Equal(t, []TaskStatus{StatusDone, StatusCancelled}, statuses)
+
+	statuses, err = ParseTaskStatuses("")
+	assert.NoError(t, err)
+	assert.Empty(t, statuses)
+
+	_, err = ParseTaskStatuses("todo,finished")
+	assert.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCategoryIDs(t *testing.T) {
+	ids, err := ParseCategoryIDs("1, 2,3")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, ids)
+
+	ids, err = ParseCategoryIDs("")
+	assert.NoError(t, err)
+	assert.Nil(t, ids)
+
+	_, err = ParseCategoryIDs("1,work")
+	assert.Error(t, err)
+	_, err = ParseCategoryIDs("0")
+	assert.Error(t, err)
+}
+
+func TestValidateCategoryIDs(t *testing.T) {
+	assert.NoError(t, ValidateCategoryIDs([]int{1, 2}))
+	assert.NoError(t, ValidateCategoryIDs(nil))
+	assert.Error(t, ValidateCategoryIDs([]int{1, -1}))
+}
+
+func TestTaskPayload(t *testing.T) {
+	parent := 2
+	task := Task{ID: 5, Title: "Call John", Priority: 1, ParentID: &parent, Status: StatusDone,
+		Categories: Categories{{ID: 3, Label: "work"}}}
+
+	payload := task.Payload()
+	assert.Equal(t, "Call John", payload.Title)
+	assert.Equal(t, &parent, payload.ParentID)
+	assert.Equal(t, []int{3}, payload.CategoryIDs)
+
+	assert.Equal(t, []int{}, Task{}.Payload().CategoryIDs)
+	assert.Equal(t, []int{}, TaskRequestPayload{}.Overwrite().CategoryIDs)
+	assert.Equal(t, []int{3}, payload.Overwrite().CategoryIDs)
+}
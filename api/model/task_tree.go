@@ -0,0 +1,58 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CascadePolicy decides what happens to the subtasks of a task
+// that is deleted or finished
+type CascadePolicy string
+
+const (
+	// CascadeReject refuses the operation while the task has subtasks
+	CascadeReject CascadePolicy = "reject"
+	// CascadeAll applies the same operation to every subtask
+	CascadeAll CascadePolicy = "cascade"
+	// CascadeReparent moves the subtasks up to the task's own parent
+	CascadeReparent CascadePolicy = "reparent"
+)
+
+var (
+	ErrTaskHasChildren = errors.New("error: task has subtasks")
+	ErrInvalidParent   = errors.New("error: invalid parent task")
+)
+
+// ParseCascadePolicy parses the "children" query parameter, defaulting to reject
+func ParseCascadePolicy(value string) (CascadePolicy, error) {
+	switch p := CascadePolicy(value); p {
+	case "":
+		return CascadeReject, nil
+	case CascadeReject, CascadeAll, CascadeReparent:
+		return p, nil
+	default:
+		return "", fmt.Errorf("error: children policy must be one of reject, cascade or reparent")
+	}
+}
+
+// Nest attaches descendants to their parents, returning roots as a tree
+func (q Tasks) Nest(descendants Tasks) Tasks {
+	byParent := make(map[int]Tasks)
+	for _, task := range descendants {
+		if task.ParentID != nil {
+			byParent[*task.ParentID] = append(byParent[*task.ParentID], task)
+		}
+	}
+
+	var attach func(tasks Tasks) Tasks
+	attach = func(tasks Tasks) Tasks {
+		nested := make(Tasks, len(tasks))
+		for i, task := range tasks {
+			task.Children = attach(byParent[task.ID])
+			nested[i] = task
+		}
+		return nested
+	}
+
+	return attach(q)
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTasksNest(t *testing.T) {
+	one, two, three := 1, 2, 3
+	roots := Tasks{{ID: 1}, {ID: 4}}
+	descendants := Tasks{
+		{ID: 2, ParentID: &one},
+		{ID: 3, ParentID: &two},
+		{ID: 5, ParentID: &one},
+		{ID: 6, ParentID: &three},
+	}
+
+	tree := roots.Nest(descendants)
+
+	assert.Len(t, tree, 2)
+	assert.Len(t, tree[0].Children, 2)
+	assert.Equal(t, 2, tree[0].Children[0].ID)
+	assert.Equal(t, 3, tree[0].Children[0].Children[0].ID)
+	assert.Equal(t, 6, tree[0].Children[0].Children[0].Children[0].ID)
+	assert.Equal(t, 5, tree[0].Children[1].ID)
+	assert.Empty(t, tree[1].Children)
+}
+
+func TestParseCascadePolicy(t *testing.T) {
+	policy, err := ParseCascadePolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, CascadeReject, policy)
+
+	policy, err = ParseCascadePolicy("reparent")
+	assert.NoError(t, err)
+	assert.Equal(t, CascadeReparent, policy)
+
+	_, err = ParseCascadePolicy("orphan")
+	assert.Error(t, err)
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrParentTrashed = errors.New("error: the parent task is in the trash, restore it first")
+
+// DefaultTrashRetention is how long tasks stay in the trash before they are purged
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// ParseTrashRetention parses the TRASH_RETENTION setting, a duration such as
+// "720h", defaulting to DefaultTrashRetention when empty
+func ParseTrashRetention(value string) (time.Duration, error) {
+	if value == "" {
+		return DefaultTrashRetention, nil
+	}
+
+	retention, err := time.ParseDuration(value)
+	if err != nil || retention <= 0 {
+		return 0, fmt.Errorf("error: TRASH_RETENTION must be a positive duration such as 720h, got %q", value)
+	}
+
+	return retention, nil
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTrashRetention(t *testing.T) {
+	retention, err := ParseTrashRetention("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultTrashRetention, retention)
+
+	retention, err = ParseTrashRetention("48h")
+	assert.NoError(t, err)
+	assert.Equal(t, 48*time.Hour, retention)
+
+	_, err = ParseTrashRetention("-1h")
+	assert.Error(t, err)
+	_, err = ParseTrashRetention("a month")
+	assert.Error(t, err)
+}
@@ -0,0 +1,157 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+const (
+	// DefaultAccessTokenTTL is how long an access token is valid unless ACCESS_TOKEN_TTL says otherwise
+	DefaultAccessTokenTTL = 15 * time.Minute
+	// DefaultRefreshTokenTTL is how long a refresh token is valid unless REFRESH_TOKEN_TTL says otherwise
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+	// MinPasswordLength and MaxPasswordLength bound passwords, bcrypt ignores bytes past 72
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+var ErrInvalidCredentials = errors.New("error: email or password is incorrect")
+
+type User struct {
+	ID        int       `json:"id" example:"1"`
+	Email     string    `json:"email" example:"jane@example.com"`
+	Name      string    `json:"name" example:"Jane"`
+	CreatedAt time.Time `json:"created_at" example:"2024-03-01T00:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2024-03-01T00:00:00Z"`
+}
+
+type RegisterPayload struct {
+	Email    string `json:"email" example:"jane@example.com"`
+	Password string `json:"password" example:"correct horse battery"`
+	Name     string `json:"name" example:"Jane"`
+}
+
+// Validate checks the payload and lowercases the email, which is the login
+func (p *RegisterPayload) Validate() error {
+	email, err := normalizeEmail(p.Email)
+	if err != nil {
+		return err
+	}
+	p.Email = email
+
+	if len(p.Password) < MinPasswordLength || len(p.Password) > MaxPasswordLength {
+		return fmt.Errorf(
+			"error: password must be between %d and %d characters", MinPasswordLength, MaxPasswordLength,
+		)
+	}
+
+	if len(p.Name) > 255 {
+		return errors.New("error: name must be at most 255 characters")
+	}
+
+	return nil
+}
+
+type LoginPayload struct {
+	Email    string `json:"email" example:"jane@example.com"`
+	Password string `json:"password" example:"correct horse battery"`
+}
+
+// Validate checks the payload and lowercases the email
+func (p *LoginPayload) Validate() error {
+	email, err := normalizeEmail(p.Email)
+	if err != nil {
+		return err
+	}
+	p.Email = email
+
+	if p.Password == "" {
+		return errors.New("error: password is required")
+	}
+
+	return nil
+}
+
+// RefreshPayload carries the refresh token to rotate or revoke
+type RefreshPayload struct {
+	RefreshToken string `json:"refresh_token" example:"p2K1c0bXh3..."`
+}
+
+func (p RefreshPayload) Validate() error {
+	if p.RefreshToken == "" {
+		return errors.New("error: refresh_token is required")
+	}
+	return nil
+}
+
+// TokenPair is returned on login and on refresh. The refresh token can be
+// used once: refreshing returns a new one and revokes it.
+type TokenPair struct {
+	AccessToken  string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIs..."`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int    `json:"expires_in" example:"900"`
+	RefreshToken string `json:"refresh_token" example:"p2K1c0bXh3..."`
+	// WorkspaceID is the workspace of the access token, 0 when the user has none
+	WorkspaceID int  `json:"workspace_id" example:"1"`
+	User        User `json:"user"`
+}
+
+func normalizeEmail(value string) (string, error) {
+	email := strings.ToLower(strings.TrimSpace(value))
+	if email == "" {
+		return "", errors.New("error: email is required")
+	}
+
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || len(email) > 255 {
+		return "", fmt.Errorf("error: %q is not a valid email address", value)
+	}
+
+	return email, nil
+}
+
+// ParseAccessTokenTTL parses the ACCESS_TOKEN_TTL setting, a duration such as
+// "15m", defaulting to DefaultAccessTokenTTL when empty
+func ParseAccessTokenTTL(value string) (time.Duration, error) {
+	return parseTokenTTL("ACCESS_TOKEN_TTL", value, DefaultAccessTokenTTL)
+}
+
+// ParseRefreshTokenTTL parses the REFRESH_TOKEN_TTL setting, a duration such as
+// "720h", defaulting to DefaultRefreshTokenTTL when empty
+func ParseRefreshTokenTTL(value string) (time.Duration, error) {
+	return parseTokenTTL("REFRESH_TOKEN_TTL", value, DefaultRefreshTokenTTL)
+}
+
+func parseTokenTTL(setting, value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("error: %s must be a positive duration such as %s, got %q", setting, fallback, value)
+	}
+
+	return ttl, nil
+}
+
+func (u User) ToJSON(code int, message string) types.JSONResult {
+	return types.JSONResult{
+		Data:    u,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (t TokenPair) ToJSON(code int, message string) types.JSONResult {
+	return types.JSONResult{
+		Data:    t,
+		Code:    code,
+		Message: message,
+	}
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterPayloadValidate(t *testing.T) {
+	payload := RegisterPayload{Email: " Jane@Example.com ", Password: "correct horse"}
+	assert.NoError(t, payload.Validate())
+	assert.Equal(t, "jane@example.com", payload.Email, "emails are lowercased")
+
+	for _, bad := range []RegisterPayload{
+		{Email: "", Password: "correct horse"},
+		{Email: "jane", Password: "correct horse"},
+		{Email: "Jane <jane@example.com>", Password: "correct horse"},
+		{Email: "jane@example.com", Password: "short"},
+		{Email: "jane@example.com", Password: strings.Repeat("a", MaxPasswordLength+1)},
+		{Email: "jane@example.com", Password: "correct horse", Name: strings.Repeat("a", 256)},
+	} {
+		assert.Error(t, bad.Validate(), bad)
+	}
+}
+
+func TestLoginPayloadValidate(t *testing.T) {
+	payload := LoginPayload{Email: "JANE@example.com", Password: "x"}
+	assert.NoError(t, payload.Validate())
+	assert.Equal(t, "jane@example.com", payload.Email)
+
+	assert.Error(t, (&LoginPayload{Email: "jane@example.com"}).Validate())
+}
+
+func TestParseTokenTTL(t *testing.T) {
+	ttl, err := ParseAccessTokenTTL("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultAccessTokenTTL, ttl)
+
+	ttl, err = ParseRefreshTokenTTL("48h")
+	assert.NoError(t, err)
+	assert.Equal(t, 48*time.Hour, ttl)
+
+	_, err = ParseAccessTokenTTL("-1m")
+	assert.Error(t, err)
+	_, err = ParseRefreshTokenTTL("soon")
+	assert.Error(t, err)
+}
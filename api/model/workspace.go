@@ -0,0 +1,145 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+// WorkspaceHeader picks the workspace of a request instead of the one of its
+// access token
+const WorkspaceHeader = "X-Workspace-ID"
+
+// Roles of the members of a workspace. Owners manage its members.
+const (
+	RoleOwner  = "owner"
+	RoleMember = "member"
+)
+
+// PersonalWorkspace names the workspace every user gets when they register
+const PersonalWorkspace = "Personal"
+
+var (
+	ErrWorkspaceRequired  = errors.New("error: no workspace, pick one with the " + WorkspaceHeader + " header")
+	ErrWorkspaceForbidden = errors.New("error: only owners of the workspace can manage its members")
+	ErrOwnerRemoval       = errors.New("error: owners cannot be removed from their workspace")
+	ErrAPIKeyWorkspace    = errors.New("error: the API key belongs to another workspace")
+)
+
+// Workspace is a tenant: its tasks, categories and projects are only seen by
+// its members
+type Workspace struct {
+	ID   int    `json:"id" example:"1"`
+	Name string `json:"name" example:"Acme"`
+	// Role is the role of the user in the workspace
+	Role      string    `json:"role" example:"owner"`
+	CreatedAt time.Time `json:"created_at" example:"2024-03-01T00:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2024-03-01T00:00:00Z"`
+}
+
+type Workspaces []Workspace
+
+type WorkspaceRequestPayload struct {
+	Name string `json:"name" example:"Acme"`
+}
+
+func (p WorkspaceRequestPayload) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("error: name is required")
+	}
+
+	if len(p.Name) > 255 {
+		return errors.New("error: name must be at most 255 characters")
+	}
+
+	return nil
+}
+
+// Member is a user of a workspace
+type Member struct {
+	UserID    int       `json:"user_id" example:"2"`
+	Email     string    `json:"email" example:"john@example.com"`
+	Name      string    `json:"name" example:"John"`
+	Role      string    `json:"role" example:"member"`
+	CreatedAt time.Time `json:"created_at" example:"2024-03-01T00:00:00Z"`
+}
+
+type Members []Member
+
+// MemberPayload adds a registered user to a workspace
+type MemberPayload struct {
+	Email string `json:"email" example:"john@example.com"`
+	Role  string `json:"role" example:"member"`
+}
+
+// Validate checks the payload, lowercases the email and defaults the role to RoleMember
+func (p *MemberPayload) Validate() error {
+	email, err := normalizeEmail(p.Email)
+	if err != nil {
+		return err
+	}
+	p.Email = email
+
+	switch p.Role {
+	case "":
+		p.Role = RoleMember
+	case RoleOwner, RoleMember:
+	default:
+		return fmt.Errorf("error: role must be %s or %s, got %q", RoleOwner, RoleMember, p.Role)
+	}
+
+	return nil
+}
+
+// ParseWorkspaceID parses the X-Workspace-ID header, an empty value means the
+// workspace of the access token
+func ParseWorkspaceID(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("error: %s must be a workspace id, got %q", WorkspaceHeader, value)
+	}
+
+	return id, nil
+}
+
+func (w Workspace) ToJSON(code int, message string) types.JSONResult {
+	return types.JSONResult{
+		Data:    w,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (w Workspaces) ToJSON() types.JSONResultWithPaginate {
+	return types.JSONResultWithPaginate{
+		Code:    200,
+		Message: "success",
+		Data:    w,
+		Length:  len(w),
+	}
+}
+
+func (m Member) ToJSON(code int, message string) types.JSONResult {
+	return types.JSONResult{
+		Data:    m,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (m Members) ToJSON() types.JSONResultWithPaginate {
+	return types.JSONResultWithPaginate{
+		Code:    200,
+		Message: "success",
+		Data:    m,
+		Length:  len(m),
+	}
+}
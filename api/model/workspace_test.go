@@ -0,0 +1,43 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspaceRequestPayloadValidate(t *testing.T) {
+	assert.NoError(t, WorkspaceRequestPayload{Name: "Acme"}.Validate())
+	assert.Error(t, WorkspaceRequestPayload{Name: " "}.Validate())
+	assert.Error(t, WorkspaceRequestPayload{Name: strings.Repeat("a", 256)}.Validate())
+}
+
+func TestMemberPayloadValidate(t *testing.T) {
+	payload := MemberPayload{Email: " John@Example.com "}
+	assert.NoError(t, payload.Validate())
+	assert.Equal(t, "john@example.com", payload.Email)
+	assert.Equal(t, RoleMember, payload.Role, "defaults to member")
+
+	payload = MemberPayload{Email: "john@example.com", Role: RoleOwner}
+	assert.NoError(t, payload.Validate())
+	assert.Equal(t, RoleOwner, payload.Role)
+
+	assert.Error(t, (&MemberPayload{Email: "john@example.com", Role: "admin"}).Validate())
+	assert.Error(t, (&MemberPayload{Email: "john"}).Validate())
+}
+
+func TestParseWorkspaceID(t *testing.T) {
+	id, err := ParseWorkspaceID("")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, id)
+
+	id, err = ParseWorkspaceID("4")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, id)
+
+	_, err = ParseWorkspaceID("0")
+	assert.Error(t, err)
+	_, err = ParseWorkspaceID("acme")
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/repository"
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+// purgeInterval is how often expired data is looked for
+const purgeInterval = time.Hour
+
+// purge removes expired data every purgeInterval until ctx is cancelled
+func (s *Server) purge(ctx context.Context) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		s.purgeWorkspaces(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeWorkspaces purges the data of every workspace in turn: the repositories
+// only see the data of the workspace of their context
+func (s *Server) purgeWorkspaces(ctx context.Context) {
+	ids, err := repository.NewWorkspaceRepo(s.db).IDs(ctx)
+	if err != nil {
+		slog.Error("failed to list the workspaces to purge: " + err.Error())
+		return
+	}
+
+	store := repository.NewStore(s.db)
+	for _, id := range ids {
+		workspace := types.WithWorkspace(ctx, id)
+		s.purgeTrash(workspace, store)
+		s.purgeIdempotencyKeys(workspace, store)
+	}
+}
+
+// purgeTrash permanently deletes the tasks that have been in the trash for
+// longer than the retention period
+func (s *Server) purgeTrash(ctx context.Context, store *repository.Store) {
+	purged, err := repository.NewTaskRepo(store).Purge(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		slog.Error("failed to purge the trash: " + err.Error())
+		return
+	}
+	if purged == 0 {
+		return
+	}
+
+	slog.Info(fmt.Sprintf("[ Purged %d tasks from the trash of workspace %d ]", purged, types.WorkspaceFrom(ctx)))
+	if err := repository.NewAttachmentRepo(store, s.blobs).CollectGarbage(ctx); err != nil {
+		slog.Error("failed to collect attachment blobs: " + err.Error())
+	}
+}
+
+// purgeIdempotencyKeys forgets the responses whose Idempotency-Key has expired
+func (s *Server) purgeIdempotencyKeys(ctx context.Context, store *repository.Store) {
+	purged, err := repository.NewIdempotencyRepo(store, s.idempotencyTTL).Purge(ctx)
+	if err != nil {
+		slog.Error("failed to purge idempotency keys: " + err.Error())
+		return
+	}
+	if purged > 0 {
+		slog.Info(fmt.Sprintf("[ Purged %d expired idempotency keys of workspace %d ]", purged, types.WorkspaceFrom(ctx)))
+	}
+}
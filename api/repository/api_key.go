@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/types"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+// apiKeyColumns is the column list every API key query selects, in scanAPIKey order
+const apiKeyColumns = `"id", "name", "prefix", "scopes", "workspace_id", "expires_at", "last_used_at", "created_at"`
+
+// apiKeyPrefixLength is how much of a key is kept to tell keys apart
+const apiKeyPrefixLength = 9
+
+var (
+	ErrAPIKeyNotFound = errors.New("error: API key not found")
+	ErrInvalidAPIKey  = errors.New("error: API key is invalid, expired or revoked")
+)
+
+// APIKeyRepository keeps the API keys of the users
+type APIKeyRepository struct {
+	store *sql.DB
+}
+
+func NewAPIKeyRepo(store *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{store}
+}
+
+func scanAPIKey(row scanner, key *model.APIKey, extra ...interface{}) error {
+	dest := []interface{}{
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		pq.Array(&key.Scopes),
+		&key.WorkspaceID,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.CreatedAt,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// Create mints a key for a user in a workspace. The key is only returned here,
+// only its hash is stored.
+func (r APIKeyRepository) Create(
+	c context.Context, userID, workspaceID int, payload model.APIKeyRequestPayload,
+) (model.CreatedAPIKey, error) {
+	var created model.CreatedAPIKey
+
+	key, hash, err := util.NewOpaqueToken(model.APIKeyPrefix)
+	if err != nil {
+		return created, err
+	}
+
+	query := `INSERT INTO "api_keys" ("user_id", "workspace_id", "name", "prefix", "key_hash", "scopes", "expires_at")
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING ` + apiKeyColumns
+	row := r.store.QueryRowContext(
+		c, query,
+		userID, workspaceID, payload.Name, key[:apiKeyPrefixLength], hash, pq.Array(payload.Scopes), payload.ExpiresAt,
+	)
+	if err := scanAPIKey(row, &created.APIKey); err != nil {
+		return created, err
+	}
+
+	created.Key = key
+	return created, nil
+}
+
+// List returns the keys of a user that are not revoked, expired ones included,
+// in every workspace
+func (r APIKeyRepository) List(c context.Context, userID int) (model.APIKeys, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM "api_keys"
+		WHERE "user_id" = $1 AND "revoked_at" IS NULL ORDER BY "id"`
+	rows, err := r.store.QueryContext(c, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := model.APIKeys{}
+	for rows.Next() {
+		var key model.APIKey
+		if err := scanAPIKey(rows, &key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Revoke revokes a key of a user, ErrAPIKeyNotFound when the user has no such key
+func (r APIKeyRepository) Revoke(c context.Context, userID int, args model.RequestURLParam) error {
+	query := `UPDATE "api_keys" SET "revoked_at" = now()
+		WHERE "id" = $1 AND "user_id" = $2 AND "revoked_at" IS NULL`
+	result, err := r.store.ExecContext(c, query, args.ID, userID)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: \"id\" %d", ErrAPIKeyNotFound, args.ID)
+	}
+
+	return nil
+}
+
+// Authenticate returns the principal of a key that is neither expired nor
+// revoked, and records that the key was used
+func (r APIKeyRepository) Authenticate(c context.Context, key string) (types.Principal, error) {
+	var principal types.Principal
+	var stale bool
+
+	query := `SELECT "k"."id", "k"."user_id", "u"."email", "k"."scopes", "k"."workspace_id",
+			"k"."last_used_at" IS NULL OR "k"."last_used_at" < now() - interval '1 minute'
+		FROM "api_keys" "k" JOIN "users" "u" ON "u"."id" = "k"."user_id"
+		WHERE "k"."key_hash" = $1 AND "k"."revoked_at" IS NULL
+			AND ("k"."expires_at" IS NULL OR "k"."expires_at" > now())`
+	err := r.store.QueryRowContext(c, query, util.HashToken(key)).Scan(
+		&principal.KeyID, &principal.UserID, &principal.Email, pq.Array(&principal.Scopes), &principal.WorkspaceID,
+		&stale,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return principal, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return principal, err
+	}
+
+	// keys used by busy scripts are not written to on every request
+	if stale {
+		query = `UPDATE "api_keys" SET "last_used_at" = now() WHERE "id" = $1`
+		if _, err := r.store.ExecContext(c, query, principal.KeyID); err != nil {
+			return principal, err
+		}
+	}
+
+	if principal.Scopes == nil {
+		principal.Scopes = []string{}
+	}
+	return principal, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/lib/pq"
@@ -11,124 +12,185 @@ import (
 	"github.com/Kbgjtn/notethingness-api.git/types"
 )
 
+var (
+	ErrCategoryNotFound = errors.New("error: category not found")
+	ErrCategoryExists   = errors.New("error: category with this label already exists")
+)
+
+// categoryColumns is the column list the category queries select, in scanCategory order
+const categoryColumns = `"id", "label", "owner_id"`
+
 type CategoryRepository struct {
-	store *sql.DB
+	store *Store
 }
 
-func NewCategoryRepo(store *sql.DB) *CategoryRepository {
+func NewCategoryRepo(store *Store) *CategoryRepository {
 	return &CategoryRepository{store}
 }
 
+func scanCategory(row scanner, category *model.Category, extra ...interface{}) error {
+	dest := []interface{}{&category.ID, &category.Label, &category.OwnerID}
+	return row.Scan(append(dest, extra...)...)
+}
+
 func (r CategoryRepository) List(
 	ctx context.Context,
 	args *types.Pageable,
+	filter model.CategoryFilter,
 ) (model.Categories, error) {
-	query := `SELECT *, COUNT(*) OVER() AS total FROM "categories" ORDER BY "id" LIMIT $1 OFFSET $2`
-	rows, err := r.store.QueryContext(ctx, query, args.Limit, args.Offset)
-	if err != nil {
-		fmt.Println(err.Error())
-		return nil, err
+	var conditions []string
+	var params []interface{}
+	if filter.Expr != nil {
+		var condition string
+		condition, params = filterSQL(filter.Expr, params)
+		conditions = append(conditions, condition)
 	}
 
 	var categories model.Categories
-
-	for rows.Next() {
-		var category model.Category
-
-		err := rows.Scan(
-			&category.ID,
-			&category.Label,
-			&args.Total,
-		)
-		if err != nil {
-			fmt.Println(err.Error())
-			return nil, err
-		}
-
-		args.Calc()
-		categories = append(categories, category)
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		var err error
+		categories, err = paginate(ctx, tx, listQuery{
+			table:      "categories",
+			columns:    categoryColumns,
+			conditions: conditions,
+			params:     params,
+			sort:       filter.Sort,
+			schema:     model.CategoryFields,
+		}, args, func(row scanner, category *model.Category, key ...interface{}) error {
+			return scanCategory(row, category, key...)
+		})
+		return err
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+		return nil, err
 	}
 
+	args.Calc()
 	return categories, nil
 }
 
+// Get returns a category, ErrCategoryNotFound when it does not exist
 func (r CategoryRepository) Get(
 	ctx context.Context,
 	args model.RequestURLParam,
 ) (model.Category, error) {
 	var category model.Category
 
-	query := `SELECT * FROM "categories" WHERE "id" = $1 LIMIT 1`
+	query := `SELECT ` + categoryColumns + ` FROM "categories" WHERE "id" = $1`
 
-	row, err := r.store.QueryContext(ctx, query, args.ID)
-	if err != nil {
-		return category, err
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		return scanCategory(tx.QueryRowContext(ctx, query, args.ID), &category)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return category, fmt.Errorf("%w: \"id\" %d", ErrCategoryNotFound, args.ID)
 	}
 
-	if row.Next() {
-		if err = row.Scan(&category.ID, &category.Label); err != nil {
-			return category, err
-		}
-	}
-
-	return category, nil
+	return category, err
 }
 
 func (r CategoryRepository) Create(c context.Context, label string) (model.Category, error) {
-	query := `INSERT intO "categories" ("label") VALUES ($1) RETURNING *`
-	row := r.store.QueryRowContext(c, query, label)
-
 	var category model.Category
 
-	if err := row.Scan(&category.ID, &category.Label); err != nil {
-		pqErr, ok := err.(*pq.Error)
+	tx, err := r.store.BeginTx(c, nil)
+	if err != nil {
+		return category, err
+	}
+	defer tx.Rollback()
 
-		if ok && pqErr.Constraint == "categories_label_key" {
-			return model.Category{}, fmt.Errorf(
-				"error: category with label %s already exists", label,
-			)
-		}
+	query := `INSERT INTO "categories" ("label", "owner_id") VALUES ($1, $2) RETURNING ` + categoryColumns
+	if err := scanCategory(tx.QueryRowContext(c, query, label, ownerID(c)), &category); err != nil {
+		return model.Category{}, labelError(err, label)
+	}
 
+	if err := recordHistory(c, tx, model.EntityCategory, category.ID, model.HistoryCreate, nil, category); err != nil {
 		return model.Category{}, err
 	}
 
-	return category, nil
+	return category, tx.Commit()
 }
 
+// Delete removes a category, ErrCategoryNotFound when it does not exist
 func (r CategoryRepository) Delete(c context.Context, args model.RequestURLParam) error {
-	query := `DELETE FROM "categories" WHERE "id" = $1`
-	_, err := r.store.ExecContext(c, query, args.ID)
+	tx, err := r.store.BeginTx(c, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	return nil
+	var category model.Category
+	query := `DELETE FROM "categories" WHERE "id" = $1 RETURNING ` + categoryColumns
+	err = scanCategory(tx.QueryRowContext(c, query, args.ID), &category)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: \"id\" %d", ErrCategoryNotFound, args.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := recordHistory(c, tx, model.EntityCategory, category.ID, model.HistoryDelete, category, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
+// Update renames a category, ErrCategoryNotFound when it does not exist
 func (r CategoryRepository) Update(
 	c context.Context, args model.RequestURLParam, label string,
 ) (model.Category, error) {
-	query := `UPDATE "categories" SET "label" = $1 WHERE "id" = $2 RETURNING *`
+	return r.Patch(c, args, func(model.CategoryRequestPayload) (model.CategoryRequestPayload, error) {
+		return model.CategoryRequestPayload{Label: label}, nil
+	})
+}
+
+// Patch changes a category: patch receives its editable fields as they are
+// and returns them as they should be, all while the category is locked
+func (r CategoryRepository) Patch(
+	c context.Context,
+	args model.RequestURLParam,
+	patch func(model.CategoryRequestPayload) (model.CategoryRequestPayload, error),
+) (model.Category, error) {
+	var before, category model.Category
 
-	row, err := r.store.QueryContext(c, query, label, args.ID)
+	tx, err := r.store.BeginTx(c, nil)
 	if err != nil {
-		pqErr, ok := err.(*pq.Error)
-		if ok && pqErr.Constraint == "categories_label_key" {
-			return model.Category{}, fmt.Errorf(
-				"error: category with label %s already exists", label,
-			)
-		}
-		return model.Category{}, err
+		return category, err
 	}
+	defer tx.Rollback()
 
-	if row.Next() {
-		var category model.Category
-		if err := row.Scan(&category.ID, &category.Label); err != nil {
-			return category, err
-		}
-		category.Label = label
-		return category, nil
+	query := `SELECT ` + categoryColumns + ` FROM "categories" WHERE "id" = $1 FOR UPDATE`
+	err = scanCategory(tx.QueryRowContext(c, query, args.ID), &before)
+	if errors.Is(err, sql.ErrNoRows) {
+		return category, fmt.Errorf("%w: \"id\" %d", ErrCategoryNotFound, args.ID)
+	}
+	if err != nil {
+		return category, err
 	}
 
-	return model.Category{}, nil
+	payload, err := patch(model.CategoryRequestPayload{Label: before.Label})
+	if err != nil {
+		return category, err
+	}
+
+	query = `UPDATE "categories" SET "label" = $1 WHERE "id" = $2 RETURNING ` + categoryColumns
+	err = scanCategory(tx.QueryRowContext(c, query, payload.Label, args.ID), &category)
+	if err != nil {
+		return category, labelError(err, payload.Label)
+	}
+
+	if err := recordHistory(c, tx, model.EntityCategory, category.ID, model.HistoryUpdate, before, category); err != nil {
+		return category, err
+	}
+
+	return category, tx.Commit()
+}
+
+// labelError reports a violation of the unique label constraint as ErrCategoryExists
+func labelError(err error, label string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Constraint == "categories_label_key" {
+		return fmt.Errorf("%w: %q", ErrCategoryExists, label)
+	}
+	return err
 }
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+// filterSQL translates a parsed filter expression into a parameterized SQL condition,
+// appending its values to params. Field names come from a util.FilterSchema
+// whitelist and are used as column names.
+func filterSQL(node util.FilterNode, params []interface{}) (string, []interface{}) {
+	switch n := node.(type) {
+	case util.FilterLogical:
+		var left, right string
+		left, params = filterSQL(n.Left, params)
+		right, params = filterSQL(n.Right, params)
+		return "(" + left + " " + strings.ToUpper(n.Op) + " " + right + ")", params
+	case util.FilterNot:
+		var expr string
+		expr, params = filterSQL(n.Expr, params)
+		return "NOT (" + expr + ")", params
+	case util.FilterComparison:
+		column := `"` + n.Field + `"`
+
+		if n.Value == nil {
+			if n.Op == "!=" {
+				return column + " IS NOT NULL", params
+			}
+			return column + " IS NULL", params
+		}
+
+		if n.Op == "~" {
+			value := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(n.Value.(string))
+			params = append(params, "%"+value+"%")
+			return fmt.Sprintf(`%s ILIKE $%d`, column, len(params)), params
+		}
+
+		params = append(params, n.Value)
+		return fmt.Sprintf(`%s %s $%d`, column, n.Op, len(params)), params
+	default:
+		return "TRUE", params
+	}
+}
+
+// orderSQL builds an ORDER BY list from sort fields, ending with "id"
+// when it is not sorted on already so that pages are stable
+func orderSQL(fields []util.SortField) string {
+	var order []string
+	hasID := false
+
+	for _, field := range fields {
+		direction := "ASC"
+		if field.Desc {
+			direction = "DESC"
+		}
+		order = append(order, fmt.Sprintf(`"%s" %s`, field.Field, direction))
+		hasID = hasID || field.Field == "id"
+	}
+
+	if !hasID {
+		order = append(order, `"id" ASC`)
+	}
+
+	return strings.Join(order, ", ")
+}
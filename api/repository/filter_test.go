@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+func TestFilterSQL(t *testing.T) {
+	schema := util.FilterSchema{"title": util.FieldString, "priority": util.FieldInt, "parent_id": util.FieldInt}
+	node, err := util.ParseFilter(`priority>=2 and (title~"50%_off" or not parent_id=null)`, schema)
+	assert.NoError(t, err)
+
+	where, params := filterSQL(node, []interface{}{"existing"})
+
+	assert.Equal(t, `("priority" >= $2 AND ("title" ILIKE $3 OR NOT ("parent_id" IS NULL)))`, where)
+	assert.Equal(t, []interface{}{"existing", int64(2), `%50\%\_off%`}, params)
+}
+
+func TestOrderSQL(t *testing.T) {
+	assert.Equal(t, `"id" ASC`, orderSQL(nil))
+	assert.Equal(t, `"priority" DESC, "date" ASC, "id" ASC`, orderSQL([]util.SortField{
+		{Field: "priority", Desc: true}, {Field: "date"},
+	}))
+	assert.Equal(t, `"id" DESC`, orderSQL([]util.SortField{{Field: "id", Desc: true}}))
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/types"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+const historyColumns = `"id", "entity", "entity_id", "action", "changes", "actor", "request_id", "created_at"`
+
+// recordHistory stores the fields that differ between before and after, either
+// of which is nil on create and delete, with the actor and request ID of ctx.
+// Updates that change nothing are not recorded.
+func recordHistory(
+	ctx context.Context, q querier, entity string, id int, action model.HistoryAction, before, after interface{},
+) error {
+	changes, err := model.Diff(before, after)
+	if err != nil {
+		return err
+	}
+
+	return recordChanges(ctx, q, entity, id, action, changes)
+}
+
+// recordChanges stores changes built by the caller, see recordHistory
+func recordChanges(
+	ctx context.Context, q querier, entity string, id int, action model.HistoryAction, changes model.Changes,
+) error {
+	if action == model.HistoryUpdate && len(changes) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	audit := types.AuditFrom(ctx)
+	query := `INSERT INTO "task_history" ("entity", "entity_id", "action", "changes", "actor", "request_id")
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err = q.ExecContext(
+		ctx, query,
+		entity, id, action, data, nullIfEmpty(&audit.Actor), nullIfEmpty(&audit.RequestID),
+	)
+	return err
+}
+
+// listHistory returns a page of the changes of an entity, newest first
+func listHistory(
+	ctx context.Context, q querier, entity string, id int, p *types.Pageable,
+) (model.History, error) {
+	history, err := paginate(ctx, q, listQuery{
+		table:      "task_history",
+		columns:    historyColumns,
+		conditions: []string{`"entity" = $1`, `"entity_id" = $2`},
+		params:     []interface{}{entity, id},
+		sort:       []util.SortField{{Field: "id", Desc: true}},
+		schema:     model.HistoryFields,
+	}, p, func(row scanner, entry *model.HistoryEntry, key ...interface{}) error {
+		var changes []byte
+		dest := []interface{}{
+			&entry.ID, &entry.Entity, &entry.EntityID, &entry.Action,
+			&changes, &entry.Actor, &entry.RequestID, &entry.CreatedAt,
+		}
+		if err := row.Scan(append(dest, key...)...); err != nil {
+			return err
+		}
+		return json.Unmarshal(changes, &entry.Changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.Calc()
+	return history, nil
+}
+
+// History returns a page of the changes of a task, newest first.
+// The history of a deleted task stays available.
+func (r TaskRepository) History(
+	ctx context.Context,
+	args model.TaskURLParams,
+	p *types.Pageable,
+) (model.History, error) {
+	var history model.History
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		var err error
+		history, err = listHistory(ctx, tx, model.EntityTask, args.ID, p)
+		return err
+	})
+	return history, err
+}
+
+// History returns a page of the changes of a category, newest first
+func (r CategoryRepository) History(
+	ctx context.Context,
+	args model.RequestURLParam,
+	p *types.Pageable,
+) (model.History, error) {
+	var history model.History
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		var err error
+		history, err = listHistory(ctx, tx, model.EntityCategory, args.ID, p)
+		return err
+	})
+	return history, err
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+)
+
+// IdempotencyRepository keeps the responses of requests sent with an Idempotency-Key
+type IdempotencyRepository struct {
+	store *Store
+	ttl   time.Duration
+}
+
+func NewIdempotencyRepo(store *Store, ttl time.Duration) *IdempotencyRepository {
+	return &IdempotencyRepository{store, ttl}
+}
+
+// Begin claims a key for a request. It returns nil when the request should be
+// handled, or the saved response of the first request sent with the key.
+// A key used with another fingerprint fails with ErrIdempotencyMismatch, one
+// whose first request is still running with ErrIdempotencyInProgress: a key
+// stays claimed until that request saves its response or releases the key.
+func (r IdempotencyRepository) Begin(
+	c context.Context, actor, key, fingerprint string,
+) (*model.IdempotentResponse, error) {
+	var saved *model.IdempotentResponse
+	err := r.store.run(c, func(tx *sql.Tx) error {
+		var err error
+		saved, err = begin(c, tx, actor, key, fingerprint, r.ttl)
+		return err
+	})
+	return saved, err
+}
+
+// begin claims a key as Begin does, within the transaction of q
+func begin(
+	c context.Context, q querier, actor, key, fingerprint string, ttl time.Duration,
+) (*model.IdempotentResponse, error) {
+	query := `DELETE FROM "idempotency_keys" WHERE "actor" = $1 AND "key" = $2 AND "expires_at" < now()`
+	if _, err := q.ExecContext(c, query, actor, key); err != nil {
+		return nil, err
+	}
+
+	query = `INSERT INTO "idempotency_keys" ("actor", "key", "fingerprint", "expires_at")
+		VALUES ($1, $2, $3, now() + make_interval(secs => $4)) ON CONFLICT DO NOTHING`
+	result, err := q.ExecContext(c, query, actor, key, fingerprint, ttl.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	if claimed, err := result.RowsAffected(); err != nil || claimed == 1 {
+		return nil, err
+	}
+
+	var saved model.IdempotentResponse
+	var savedFingerprint string
+	var status sql.NullInt64
+	var headers []byte
+	query = `SELECT "fingerprint", "status", "headers", "body" FROM "idempotency_keys"
+		WHERE "actor" = $1 AND "key" = $2`
+	err = q.QueryRowContext(c, query, actor, key).Scan(&savedFingerprint, &status, &headers, &saved.Body)
+	if errors.Is(err, sql.ErrNoRows) {
+		// released by the first request in between
+		return nil, model.ErrIdempotencyInProgress
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if savedFingerprint != fingerprint {
+		return nil, model.ErrIdempotencyMismatch
+	}
+	if !status.Valid {
+		return nil, model.ErrIdempotencyInProgress
+	}
+
+	saved.Status = int(status.Int64)
+	if err := json.Unmarshal(headers, &saved.Header); err != nil {
+		return nil, err
+	}
+
+	return &saved, nil
+}
+
+// Save stores the response of the request that claimed the key
+func (r IdempotencyRepository) Save(c context.Context, actor, key string, response model.IdempotentResponse) error {
+	headers, err := json.Marshal(response.Header)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE "idempotency_keys" SET "status" = $3, "headers" = $4, "body" = $5
+		WHERE "actor" = $1 AND "key" = $2`
+	return r.store.run(c, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(c, query, actor, key, response.Status, headers, response.Body)
+		return err
+	})
+}
+
+// Release gives up a claimed key without a response, so that the request can be retried
+func (r IdempotencyRepository) Release(c context.Context, actor, key string) error {
+	query := `DELETE FROM "idempotency_keys" WHERE "actor" = $1 AND "key" = $2 AND "status" IS NULL`
+	return r.store.run(c, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(c, query, actor, key)
+		return err
+	})
+}
+
+// Purge removes the keys of the workspace of c that have expired and returns
+// how many were removed
+func (r IdempotencyRepository) Purge(c context.Context) (int, error) {
+	var purged int64
+	err := r.store.run(c, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(c, `DELETE FROM "idempotency_keys" WHERE "expires_at" < now()`)
+		if err != nil {
+			return err
+		}
+
+		purged, err = result.RowsAffected()
+		return err
+	})
+	return int(purged), err
+}
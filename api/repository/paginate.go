@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Kbgjtn/notethingness-api.git/types"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+// listQuery describes a paginated list over a single table
+type listQuery struct {
+	table string
+	// from replaces the table in FROM, a subquery aliased as the table that
+	// adds computed columns to it; the estimated count still reads the table
+	from    string
+	columns string
+	// conditions are joined with AND, params hold their values
+	conditions []string
+	params     []interface{}
+	sort       []util.SortField
+	schema     util.FilterSchema
+}
+
+// paginate runs a list query for the page described by p and returns its rows
+// in sort order. Pages are selected by the p.After / p.Before cursors when given,
+// by p.Offset otherwise. scan reads one row into item, with the destinations of
+// the row's sort key appended; p receives the total according to p.Count,
+// whether more rows follow and the cursors of the neighbouring pages.
+func paginate[T any](
+	ctx context.Context,
+	q querier,
+	list listQuery,
+	p *types.Pageable,
+	scan func(row scanner, item *T, key ...interface{}) error,
+) ([]T, error) {
+	keys := sortKeys(list.sort)
+	conditions, params := list.conditions, list.params
+
+	if err := countRows(ctx, q, list, p); err != nil {
+		return nil, err
+	}
+
+	backward := p.Before != ""
+	order := keys
+	if p.Keyset() {
+		cursor := p.After
+		if backward {
+			cursor = p.Before
+			order = reverseKeys(keys)
+		}
+
+		values, err := util.DecodeCursor(cursor, keys, list.schema)
+		if err != nil {
+			return nil, err
+		}
+
+		var condition string
+		condition, params = keysetSQL(order, values, params)
+		conditions = append(conditions, condition)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	keyColumns := make([]string, len(keys))
+	for i, key := range keys {
+		keyColumns[i] = `"` + key.Field + `"`
+	}
+
+	// one extra row tells whether another page follows
+	params = append(params, p.Limit+1)
+	query := fmt.Sprintf(
+		`SELECT %s, %s FROM %s %s ORDER BY %s LIMIT $%d`,
+		list.columns, strings.Join(keyColumns, ", "), list.fromSQL(), where, orderSQL(order), len(params),
+	)
+	if !p.Keyset() {
+		params = append(params, p.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(params))
+	}
+
+	rows, err := q.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []T
+	var rowKeys [][]interface{}
+	for rows.Next() {
+		if uint64(len(items)) == p.Limit {
+			p.More = true
+			break
+		}
+
+		var item T
+		key := make([]interface{}, len(keys))
+		dest := make([]interface{}, len(keys))
+		for i := range key {
+			dest[i] = &key[i]
+		}
+
+		if err := scan(rows, &item, dest...); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		rowKeys = append(rowKeys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return items, nil
+	}
+
+	if backward {
+		// rows were read walking back from the cursor: restore the sort order.
+		// More rows exist after this page, "More" told whether some exist before it
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+			rowKeys[i], rowKeys[j] = rowKeys[j], rowKeys[i]
+		}
+		if p.More {
+			p.PrevCursor = util.EncodeCursor(keys, rowKeys[0])
+		}
+		p.NextCursor = util.EncodeCursor(keys, rowKeys[len(rowKeys)-1])
+		p.More = true
+		return items, nil
+	}
+
+	if p.More {
+		p.NextCursor = util.EncodeCursor(keys, rowKeys[len(rowKeys)-1])
+	}
+	if p.After != "" || p.Offset > 0 {
+		p.PrevCursor = util.EncodeCursor(keys, rowKeys[0])
+	}
+
+	return items, nil
+}
+
+// countRows fills p.Total according to p.Count
+func countRows(ctx context.Context, q querier, list listQuery, p *types.Pageable) error {
+	switch p.Count {
+	case types.CountNone:
+		return nil
+	case types.CountEstimate:
+		query := `SELECT GREATEST("reltuples", 0)::bigint FROM "pg_class" WHERE "oid" = $1::regclass`
+		return q.QueryRowContext(ctx, query, `"`+list.table+`"`).Scan(&p.Total)
+	default:
+		where := ""
+		if len(list.conditions) > 0 {
+			where = "WHERE " + strings.Join(list.conditions, " AND ")
+		}
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM %s %s`, list.fromSQL(), where)
+		return q.QueryRowContext(ctx, query, list.params...).Scan(&p.Total)
+	}
+}
+
+func (l listQuery) fromSQL() string {
+	if l.from != "" {
+		return l.from
+	}
+	return `"` + l.table + `"`
+}
+
+// sortKeys returns the sort fields, ending with "id" so that every row has a unique key
+func sortKeys(fields []util.SortField) []util.SortField {
+	for _, field := range fields {
+		if field.Field == "id" {
+			return fields
+		}
+	}
+	return append(append([]util.SortField{}, fields...), util.SortField{Field: "id"})
+}
+
+func reverseKeys(keys []util.SortField) []util.SortField {
+	reversed := make([]util.SortField, len(keys))
+	for i, key := range keys {
+		reversed[i] = util.SortField{Field: key.Field, Desc: !key.Desc}
+	}
+	return reversed
+}
+
+// keysetSQL builds the condition selecting the rows that come strictly after values
+// in the order of keys. Postgres sorts NULL last ascending and first descending,
+// which the comparisons below follow.
+func keysetSQL(keys []util.SortField, values []interface{}, params []interface{}) (string, []interface{}) {
+	var alternatives []string
+
+	for i, key := range keys {
+		// nothing sorts after NULL on an ascending key
+		if !key.Desc && values[i] == nil {
+			continue
+		}
+
+		var terms []string
+		for j := 0; j < i; j++ {
+			var term string
+			term, params = keyEqualSQL(keys[j], values[j], params)
+			terms = append(terms, term)
+		}
+
+		var term string
+		term, params = keyAfterSQL(key, values[i], params)
+		terms = append(terms, term)
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+
+	if len(alternatives) == 0 {
+		return "FALSE", params
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", params
+}
+
+func keyEqualSQL(key util.SortField, value interface{}, params []interface{}) (string, []interface{}) {
+	column := `"` + key.Field + `"`
+	if value == nil {
+		return column + " IS NULL", params
+	}
+	params = append(params, value)
+	return fmt.Sprintf("%s = $%d", column, len(params)), params
+}
+
+func keyAfterSQL(key util.SortField, value interface{}, params []interface{}) (string, []interface{}) {
+	column := `"` + key.Field + `"`
+
+	if key.Desc {
+		if value == nil {
+			return column + " IS NOT NULL", params
+		}
+		params = append(params, value)
+		return fmt.Sprintf("%s < $%d", column, len(params)), params
+	}
+
+	params = append(params, value)
+	return fmt.Sprintf("(%s > $%d OR %s IS NULL)", column, len(params), column), params
+}
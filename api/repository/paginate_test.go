@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+func TestKeysetSQL(t *testing.T) {
+	keys := sortKeys([]util.SortField{{Field: "priority", Desc: true}, {Field: "date"}})
+
+	where, params := keysetSQL(keys, []interface{}{int64(2), "2024-03-01", int64(7)}, []interface{}{"status"})
+	assert.Equal(t, `(("priority" < $2)`+
+		` OR ("priority" = $3 AND ("date" > $4 OR "date" IS NULL))`+
+		` OR ("priority" = $5 AND "date" = $6 AND ("id" > $7 OR "id" IS NULL)))`, where)
+	assert.Equal(t, []interface{}{"status", int64(2), int64(2), "2024-03-01", int64(2), "2024-03-01", int64(7)}, params)
+
+	// a NULL date sorts last: only rows with the same priority and a NULL date can follow
+	where, params = keysetSQL(keys, []interface{}{int64(2), nil, int64(7)}, nil)
+	assert.Equal(t, `(("priority" < $1) OR ("priority" = $2 AND "date" IS NULL AND ("id" > $3 OR "id" IS NULL)))`, where)
+	assert.Len(t, params, 3)
+}
+
+func TestReverseKeys(t *testing.T) {
+	keys := reverseKeys(sortKeys([]util.SortField{{Field: "date", Desc: true}}))
+	assert.Equal(t, []util.SortField{{Field: "date"}, {Field: "id", Desc: true}}, keys)
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+// projectColumns is the column list every project query selects, in scanProject order
+const projectColumns = `"id", "name", "description", "archived_at", "owner_id", "created_at", "updated_at"`
+
+var ErrProjectNotFound = errors.New("error: project not found")
+
+type ProjectRepository struct {
+	store *Store
+}
+
+func NewProjectRepo(store *Store) *ProjectRepository {
+	return &ProjectRepository{store}
+}
+
+func scanProject(row scanner, project *model.Project, extra ...interface{}) error {
+	dest := []interface{}{
+		&project.ID,
+		&project.Name,
+		&project.Description,
+		&project.ArchivedAt,
+		&project.OwnerID,
+		&project.CreatedAt,
+		&project.UpdatedAt,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// List returns the projects, leaving out the archived ones unless filter.Archived is set
+func (r ProjectRepository) List(
+	ctx context.Context,
+	args *types.Pageable,
+	filter model.ProjectFilter,
+) (model.Projects, error) {
+	var conditions []string
+	var params []interface{}
+	if !filter.Archived {
+		conditions = append(conditions, `"archived_at" IS NULL`)
+	}
+	if filter.Expr != nil {
+		var condition string
+		condition, params = filterSQL(filter.Expr, params)
+		conditions = append(conditions, condition)
+	}
+
+	var projects model.Projects
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		var err error
+		projects, err = paginate(ctx, tx, listQuery{
+			table:      "projects",
+			columns:    projectColumns,
+			conditions: conditions,
+			params:     params,
+			sort:       filter.Sort,
+			schema:     model.ProjectFields,
+		}, args, scanProject)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	args.Calc()
+	return projects, nil
+}
+
+// Get returns a project, ErrProjectNotFound when it does not exist
+func (r ProjectRepository) Get(ctx context.Context, args model.RequestURLParam) (model.Project, error) {
+	var project model.Project
+
+	query := `SELECT ` + projectColumns + ` FROM "projects" WHERE "id" = $1`
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		return scanProject(tx.QueryRowContext(ctx, query, args.ID), &project)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return project, fmt.Errorf("%w: \"id\" %d", ErrProjectNotFound, args.ID)
+	}
+
+	return project, err
+}
+
+func (r ProjectRepository) Create(c context.Context, payload model.ProjectRequestPayload) (model.Project, error) {
+	var project model.Project
+
+	query := `INSERT INTO "projects" ("name", "description", "owner_id") VALUES ($1, $2, $3) RETURNING ` + projectColumns
+	err := r.store.run(c, func(tx *sql.Tx) error {
+		return scanProject(tx.QueryRowContext(c, query, payload.Name, payload.Description, ownerID(c)), &project)
+	})
+	return project, err
+}
+
+// Update renames or redescribes a project, ErrProjectNotFound when it does not exist
+func (r ProjectRepository) Update(
+	c context.Context, args model.RequestURLParam, payload model.ProjectRequestPayload,
+) (model.Project, error) {
+	var project model.Project
+
+	query := `UPDATE "projects" SET "name" = $1, "description" = $2, "updated_at" = now()
+		WHERE "id" = $3 RETURNING ` + projectColumns
+	err := r.store.run(c, func(tx *sql.Tx) error {
+		return scanProject(tx.QueryRowContext(c, query, payload.Name, payload.Description, args.ID), &project)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return project, fmt.Errorf("%w: \"id\" %d", ErrProjectNotFound, args.ID)
+	}
+
+	return project, err
+}
+
+// Archive archives or unarchives a project. Archived projects are hidden from
+// the list and take no new tasks, their tasks are kept as they are.
+func (r ProjectRepository) Archive(
+	c context.Context, args model.RequestURLParam, archived bool,
+) (model.Project, error) {
+	var project model.Project
+
+	// archiving an archived project keeps the date it was first archived
+	query := `UPDATE "projects" SET
+		"archived_at" = CASE WHEN $1::boolean THEN COALESCE("archived_at", now()) ELSE NULL END,
+		"updated_at" = now()
+		WHERE "id" = $2 RETURNING ` + projectColumns
+	err := r.store.run(c, func(tx *sql.Tx) error {
+		return scanProject(tx.QueryRowContext(c, query, archived, args.ID), &project)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return project, fmt.Errorf("%w: \"id\" %d", ErrProjectNotFound, args.ID)
+	}
+
+	return project, err
+}
+
+// Delete removes a project, its tasks are kept outside of any project
+func (r ProjectRepository) Delete(c context.Context, args model.RequestURLParam) error {
+	tx, err := r.store.BeginTx(c, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// detach the tasks first so that their history records the change
+	query := `UPDATE "tasks" SET "project_id" = NULL, "updated_at" = now()
+		WHERE "project_id" = $1 RETURNING "id"`
+	rows, err := tx.QueryContext(c, query, args.ID)
+	if err != nil {
+		return err
+	}
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	result, err := tx.ExecContext(c, `DELETE FROM "projects" WHERE "id" = $1`, args.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("%w: \"id\" %d", ErrProjectNotFound, args.ID)
+	}
+
+	for _, id := range ids {
+		changes := model.Changes{}
+		if changes["project_id"], err = model.Change(args.ID, nil); err != nil {
+			return err
+		}
+		if err := recordChanges(c, tx, model.EntityTask, id, model.HistoryUpdate, changes); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
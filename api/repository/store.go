@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+// tenantRole is the role tenant transactions run as, see db/migration/19_workspaces.up.sql
+const tenantRole = "notethingness_tenant"
+
+var ErrNoWorkspace = errors.New("error: no workspace is selected")
+
+// Store opens the transactions of the repositories of workspace data: tasks,
+// categories, projects and everything attached to them. Every transaction is
+// scoped to the workspace of its context, and the row-level security
+// policies of those tables hide the rows of other workspaces from it, so a
+// query that forgets to filter by workspace cannot see them either.
+//
+// Store runs no query outside of a workspace transaction on purpose, but for
+// the blob garbage queue, see blobGarbage.
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db}
+}
+
+// BeginTx starts a transaction scoped to the workspace carried by ctx, see
+// types.WithWorkspace. It fails with ErrNoWorkspace when ctx carries none.
+func (s *Store) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	workspaceID := types.WorkspaceFrom(ctx)
+	if workspaceID == 0 {
+		return nil, ErrNoWorkspace
+	}
+
+	tx, err := s.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// set_config(..., true) is SET LOCAL taking parameters: both settings are
+	// reset when the transaction ends, before the connection is reused
+	query := `SELECT set_config('role', $1, true), set_config('app.workspace_id', $2, true)`
+	if _, err := tx.ExecContext(ctx, query, tenantRole, strconv.Itoa(workspaceID)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// run runs fn in a transaction scoped to the workspace of ctx and commits it
+// when fn succeeds
+func (s *Store) run(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// blobGarbage returns the keys of the blobs queued in "blob_garbage", oldest
+// first. Unlike the other methods it is not scoped to a workspace: the queue
+// is shared by every workspace, holds no data of theirs but blob keys, and
+// tenants may only add to it, see db/migration/19_workspaces.up.sql.
+func (s *Store) blobGarbage(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT "blob_key" FROM "blob_garbage" ORDER BY "created_at"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// dropBlobGarbage removes key from the blob garbage queue once its blob is
+// deleted, unscoped for the same reason as blobGarbage
+func (s *Store) dropBlobGarbage(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM "blob_garbage" WHERE "blob_key" = $1`, key)
+	return err
+}
@@ -3,8 +3,10 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/Kbgjtn/notethingness-api.git/api/model"
@@ -12,127 +14,545 @@ import (
 	"github.com/lib/pq"
 )
 
+// taskFields are the task columns in scanTask order
+var taskFields = []string{
+	"id", "title", "priority", "date", "status", "completed_at", "parent_id",
+	"recurrence", "recurrence_start", "created_at", "updated_at", "deleted_at", "version",
+	"external_id", "project_id", "owner_id",
+}
+
+// taskColumns is the column list every task query selects
+var taskColumns = taskColumnsOf("")
+
+// taskColumnsOf qualifies taskFields with a table alias, e.g. "t"."id"
+func taskColumnsOf(alias string) string {
+	columns := make([]string, len(taskFields))
+	for i, field := range taskFields {
+		columns[i] = `"` + field + `"`
+		if alias != "" {
+			columns[i] = `"` + alias + `".` + columns[i]
+		}
+	}
+	return strings.Join(columns, ", ")
+}
+
+var ErrTaskNotFound = errors.New("error: task not found")
+
 type TaskRepository struct {
-	store *sql.DB
+	store *Store
 }
 
-func NewTaskRepo(store *sql.DB) *TaskRepository {
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+}
+
+// scanTask scans a row selected with taskColumns into task,
+// followed by any extra destinations such as a window count
+func scanTask(row scanner, task *model.Task, extra ...interface{}) error {
+	dest := []interface{}{
+		&task.ID,
+		&task.Title,
+		&task.Priority,
+		&task.Date,
+		&task.Status,
+		&task.CompletedAt,
+		&task.ParentID,
+		&task.Recurrence,
+		&task.RecurrenceStart,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+		&task.DeletedAt,
+		&task.Version,
+		&task.ExternalID,
+		&task.ProjectID,
+		&task.OwnerID,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// collectTasks scans and closes rows selected with taskColumns
+func collectTasks(rows *sql.Rows) (model.Tasks, error) {
+	defer rows.Close()
+
+	var tasks model.Tasks
+	for rows.Next() {
+		var task model.Task
+		if err := scanTask(rows, &task); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rows.Err()
+}
+
+func NewTaskRepo(store *Store) *TaskRepository {
 	return &TaskRepository{store}
 }
 
 func (r TaskRepository) List(
 	ctx context.Context,
 	args *types.Pageable,
+	filter model.TaskFilter,
 ) (model.Tasks, error) {
-	query := `SELECT *, COUNT(*) OVER() AS total FROM "tasks" ORDER BY "id" LIMIT $1 OFFSET $2`
-	rows, err := r.store.QueryContext(ctx, query, args.Limit, args.Offset)
+	conditions, params := taskConditions(filter)
+
+	var tasks model.Tasks
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		var err error
+		tasks, err = paginate(ctx, tx, listQuery{
+			table:      "tasks",
+			columns:    taskColumns,
+			conditions: conditions,
+			params:     params,
+			sort:       filter.Sort,
+			schema:     model.TaskFields,
+		}, args, func(row scanner, task *model.Task, key ...interface{}) error {
+			return scanTask(row, task, key...)
+		})
+		if err != nil {
+			fmt.Println(err.Error())
+			return err
+		}
+
+		return withCategories(ctx, tx, tasks)
+	})
 	if err != nil {
-		fmt.Println(err.Error())
 		return nil, err
 	}
 
-	var tasks model.Tasks
+	args.Calc()
+	return tasks, nil
+}
 
-	for rows.Next() {
-		var task model.Task
+// taskConditions builds the WHERE conditions and their parameters for a task filter
+func taskConditions(filter model.TaskFilter) ([]string, []interface{}) {
+	var conditions []string
+	var params []interface{}
 
-		err := rows.Scan(
-			&task.ID,
-			&task.Title,
-			&task.Priority,
-			&task.Date,
-			&task.CreatedAt, &task.UpdatedAt,
-			&args.Total,
-		)
-		if err != nil {
-			fmt.Println(err.Error())
-			return nil, err
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = string(status)
 		}
+		params = append(params, pq.Array(statuses))
+		conditions = append(conditions, fmt.Sprintf(`"status" = ANY($%d)`, len(params)))
+	}
 
-		args.Calc()
-		tasks = append(tasks, task)
+	if filter.ParentID != nil {
+		params = append(params, *filter.ParentID)
+		conditions = append(conditions, fmt.Sprintf(`"parent_id" = $%d`, len(params)))
 	}
 
-	return tasks, nil
+	if filter.ProjectID != nil {
+		params = append(params, *filter.ProjectID)
+		conditions = append(conditions, fmt.Sprintf(`"project_id" = $%d`, len(params)))
+	}
+
+	if filter.Trashed {
+		conditions = append(conditions, `"deleted_at" IS NOT NULL`)
+	} else {
+		conditions = append(conditions, `"deleted_at" IS NULL`)
+	}
+
+	if filter.RootsOnly {
+		conditions = append(conditions, `"parent_id" IS NULL`)
+	}
+
+	if len(filter.CategoryIDs) > 0 {
+		params = append(params, pq.Array(filter.CategoryIDs))
+		conditions = append(conditions, fmt.Sprintf(
+			`"id" IN (SELECT "task_id" FROM "task_categories" WHERE "category_id" = ANY($%d))`, len(params),
+		))
+	}
+
+	if filter.Expr != nil {
+		var condition string
+		condition, params = filterSQL(filter.Expr, params)
+		conditions = append(conditions, condition)
+	}
+
+	return conditions, params
+}
+
+// whereSQL joins conditions into a WHERE clause, empty without conditions
+func whereSQL(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conditions, " AND ")
 }
 
+// Get returns a task, reporting whether it is currently blocked by an open dependency
+// and how many comments it has
 func (r TaskRepository) Get(
 	ctx context.Context,
 	args model.TaskURLParams,
 ) (model.Task, error) {
 	var task model.Task
+	var blocked bool
+	var comments int
 
-	query := `SELECT * FROM "tasks" WHERE "id" = $1 LIMIT 1`
+	query := `SELECT ` + taskColumns + `, ` + blockedExpr + `, ` + commentCountExpr +
+		` FROM "tasks" WHERE "id" = $1 AND "deleted_at" IS NULL LIMIT 1`
 
-	row, err := r.store.QueryContext(ctx, query, args.ID)
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		err := scanTask(tx.QueryRowContext(ctx, query, args.ID), &task, &blocked, &comments)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTaskNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		task.Blocked = &blocked
+		task.CommentCount = &comments
+
+		categories, err := loadCategories(ctx, tx, []int{task.ID})
+		task.Categories = categories[task.ID]
+		return err
+	})
+	return task, err
+}
+
+// Create inserts a task and attaches its categories in one transaction
+func (r TaskRepository) Create(c context.Context, payload model.TaskRequestPayload) (model.Task, error) {
+	tx, err := r.store.BeginTx(c, nil)
 	if err != nil {
-		return task, err
+		return model.Task{}, err
 	}
+	defer tx.Rollback()
 
-	if row.Next() {
-		if err = row.Scan(&task.ID, &task.Title, &task.Priority, &task.Date, &task.CreatedAt, &task.UpdatedAt); err != nil {
-			return task, err
-		}
+	task, err := createTask(c, tx, payload)
+	if err != nil {
+		return task, err
 	}
 
-	return task, nil
+	return task, tx.Commit()
 }
 
-func (r TaskRepository) Create(c context.Context, title string, priority int, date time.Time) (model.Task, error) {
-	query := `INSERT INTO "tasks" ("title", "priority", "date") VALUES ($1, $2, $3) RETURNING *`
-	row := r.store.QueryRowContext(c, query, title, priority, date)
-
+// createTask inserts a task with its categories and records its creation
+func createTask(c context.Context, q querier, payload model.TaskRequestPayload) (model.Task, error) {
 	var task model.Task
+	var err error
+
+	if err := checkParent(c, q, 0, payload.ParentID); err != nil {
+		return task, err
+	}
+
+	projectID, err := checkProject(c, q, payload.ParentID, payload.ProjectID, nil)
+	if err != nil {
+		return task, err
+	}
 
-	if err := row.Scan(&task.ID, &task.Title, &task.Priority, &task.Date, &task.CreatedAt, &task.UpdatedAt); err != nil {
+	query := `INSERT INTO "tasks"
+		("title", "priority", "date", "parent_id", "recurrence", "recurrence_start", "project_id", "owner_id")
+		VALUES ($1, $2, $3, $4, $5, CASE WHEN $5::varchar IS NULL THEN NULL ELSE $3::timestamp END, $6, $7)
+		RETURNING ` + taskColumns
+	row := q.QueryRowContext(
+		c, query,
+		payload.Title, payload.Priority, payload.Date, payload.ParentID, nullIfEmpty(payload.Recurrence), projectID,
+		ownerID(c),
+	)
+
+	if err := scanTask(row, &task); err != nil {
 		pqErr, ok := err.(*pq.Error)
 
 		if ok && pqErr.Constraint == "tasks_title_key" {
 			return model.Task{}, fmt.Errorf(
-				"error: task with title %s already exists", title,
+				"error: task with title %s already exists", payload.Title,
 			)
 		}
 
+		return model.Task{}, parentError(err)
+	}
+
+	if task.Categories, err = setTaskCategories(c, q, task.ID, payload.CategoryIDs); err != nil {
+		return model.Task{}, err
+	}
+
+	if err := recordHistory(c, q, model.EntityTask, task.ID, model.HistoryCreate, nil, task); err != nil {
 		return model.Task{}, err
 	}
 
 	return task, nil
 }
 
-func (r TaskRepository) Delete(c context.Context, args model.TaskURLParams) error {
-	query := `DELETE FROM "tasks" WHERE "id" = $1`
-	_, err := r.store.ExecContext(c, query, args.ID)
+// Update overwrites a task; its categories are replaced when payload.CategoryIDs is set
+func (db TaskRepository) Update(
+	c context.Context, args model.TaskURLParams, payload model.TaskRequestPayload,
+) (model.Task, error) {
+	tx, err := db.store.BeginTx(c, nil)
 	if err != nil {
-		return err
+		return model.Task{}, err
+	}
+	defer tx.Rollback()
+
+	task, err := updateTask(c, tx, args, payload)
+	if err != nil {
+		return task, err
 	}
 
-	return nil
+	return task, tx.Commit()
 }
 
-func (db TaskRepository) Update(
-	c context.Context, args model.TaskURLParams, payload model.Task,
+// Patch changes the editable fields of a task: patch receives them as they
+// are and returns them as they should be, all while the task is locked
+func (db TaskRepository) Patch(
+	c context.Context,
+	args model.TaskURLParams,
+	patch func(model.TaskRequestPayload) (model.TaskRequestPayload, error),
 ) (model.Task, error) {
-	query := `UPDATE "tasks" SET "title" = $1, "priority" = $2, "date" = $3 WHERE "id" = $4 RETURNING *`
+	tx, err := db.store.BeginTx(c, nil)
+	if err != nil {
+		return model.Task{}, err
+	}
+	defer tx.Rollback()
 
-	row := db.store.QueryRowContext(
-		c, query,
-		payload.Title, payload.Priority, payload.Date, payload.ID,
-	)
+	current, err := lockTask(c, tx, args.ID)
+	if err != nil {
+		return current, err
+	}
+
+	if err := args.CheckVersion(current); err != nil {
+		return current, err
+	}
+
+	payload, err := patch(current.Payload())
+	if err != nil {
+		return current, err
+	}
+
+	task, err := updateTask(c, tx, args, payload.Overwrite())
+	if err != nil {
+		return task, err
+	}
+
+	return task, tx.Commit()
+}
+
+// updateTask locks and overwrites a task and records the change
+func updateTask(
+	c context.Context, q querier, args model.TaskURLParams, payload model.TaskRequestPayload,
+) (model.Task, error) {
 	var task model.Task
 
-	err := row.Scan(
-		&task.ID,
-		&task.Title,
-		&task.Priority,
-		&task.Date,
-		&task.CreatedAt,
-		&task.UpdatedAt,
+	before, err := lockTask(c, q, args.ID)
+	if err != nil {
+		return task, err
+	}
+
+	if err := args.CheckVersion(before); err != nil {
+		return task, err
+	}
+
+	if err := checkParent(c, q, args.ID, payload.ParentID); err != nil {
+		return task, err
+	}
+
+	projectID, err := checkProject(c, q, payload.ParentID, payload.ProjectID, before.ProjectID)
+	if err != nil {
+		return task, err
+	}
+
+	// a changed rule starts a new series at the task's date
+	query := `UPDATE "tasks" SET "title" = $1, "priority" = $2, "date" = $3, "parent_id" = $4,
+		"recurrence" = $6,
+		"recurrence_start" = CASE
+			WHEN $6::varchar IS NULL THEN NULL
+			WHEN "recurrence" IS NOT DISTINCT FROM $6::varchar THEN COALESCE("recurrence_start", $3::timestamp)
+			ELSE $3::timestamp
+		END,
+		"project_id" = $7,
+		"updated_at" = now()
+		WHERE "id" = $5 RETURNING ` + taskColumns
+
+	row := q.QueryRowContext(
+		c, query,
+		payload.Title, payload.Priority, payload.Date, payload.ParentID, args.ID, nullIfEmpty(payload.Recurrence),
+		projectID,
 	)
+
+	err = scanTask(row, &task)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return task, fmt.Errorf("%w: \"id\" %d", ErrTaskNotFound, args.ID)
+		}
 		log.Println(err)
-		log.Println(row)
-		return task, fmt.Errorf("error: task with \"id\" %d not found", payload.ID)
+		return task, parentError(err)
+	}
+
+	if payload.CategoryIDs != nil {
+		task.Categories, err = setTaskCategories(c, q, task.ID, payload.CategoryIDs)
+	} else {
+		var categories map[int]model.Categories
+		categories, err = loadCategories(c, q, []int{task.ID})
+		task.Categories = categories[task.ID]
+	}
+	if err != nil {
+		return task, err
+	}
+
+	if err := recordHistory(c, q, model.EntityTask, task.ID, model.HistoryUpdate, before, task); err != nil {
+		return task, err
+	}
+
+	// subtasks follow their parent into another project
+	if err := moveDescendants(c, q, []int{task.ID}, task.ProjectID); err != nil {
+		return task, err
 	}
+
 	return task, nil
+}
+
+// Transition moves a task to another status, enforcing the lifecycle state machine.
+// completed_at is stamped when the task reaches done and cleared when it is reopened.
+// Finishing a task (done or cancelled) handles its open subtasks according to policy.
+func (r TaskRepository) Transition(
+	c context.Context, args model.TaskURLParams, to model.TaskStatus, policy model.CascadePolicy,
+) (model.Task, error) {
+	var task model.Task
+
+	tx, err := r.store.BeginTx(c, nil)
+	if err != nil {
+		return task, err
+	}
+	defer tx.Rollback()
+
+	before, err := lockTask(c, tx, args.ID)
+	if err != nil {
+		return task, err
+	}
+
+	if err := before.Status.Transition(to); err != nil {
+		return task, err
+	}
+
+	if to.Finished() {
+		if err := finishChildren(c, tx, args.ID, to, policy); err != nil {
+			return task, err
+		}
+	}
+
+	query := `UPDATE "tasks" SET
+		"status" = $1::varchar,
+		"completed_at" = CASE WHEN $1::varchar = 'done' THEN now() ELSE NULL END,
+		"updated_at" = now()
+		WHERE "id" = $2 RETURNING ` + taskColumns
+
+	if err := scanTask(tx.QueryRowContext(c, query, to, args.ID), &task); err != nil {
+		return task, err
+	}
+	task.Categories = before.Categories
+
+	if err := recordHistory(c, tx, model.EntityTask, task.ID, model.HistoryUpdate, before, task); err != nil {
+		return task, err
+	}
 
+	if to == model.StatusDone {
+		if err := materializeNext(c, tx, task); err != nil {
+			return task, err
+		}
+	}
+
+	return task, tx.Commit()
+}
+
+// Occurrences expands the recurrence of a task between from and to without persisting anything
+func (r TaskRepository) Occurrences(
+	c context.Context, args model.TaskURLParams, from, to time.Time,
+) ([]time.Time, error) {
+	var task model.Task
+
+	query := `SELECT ` + taskColumns + ` FROM "tasks" WHERE "id" = $1 AND "deleted_at" IS NULL`
+	err := r.store.run(c, func(tx *sql.Tx) error {
+		return scanTask(tx.QueryRowContext(c, query, args.ID), &task)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return task.Occurrences(from, to)
+}
+
+// materializeNext creates the next occurrence of a completed recurring task.
+// Completing the same occurrence twice (e.g. after reopening it) does not create duplicates.
+func materializeNext(c context.Context, q querier, task model.Task) error {
+	next, ok, err := task.NextOccurrence()
+	if err != nil || !ok {
+		return err
+	}
+
+	// the next occurrence keeps the categories of the completed one
+	query := `WITH "next" AS (
+			INSERT INTO "tasks"
+				("title", "priority", "date", "parent_id", "recurrence", "recurrence_start", "project_id", "owner_id")
+			SELECT $1::varchar, $2::bigint, $3::timestamp, $4::bigint, $5::varchar, $6::timestamp, $8::bigint, $9::bigint
+			WHERE NOT EXISTS (
+				SELECT 1 FROM "tasks"
+				WHERE "recurrence" = $5 AND "recurrence_start" = $6 AND "date" = $3 AND "title" = $1
+			)
+			RETURNING ` + taskColumns + `
+		), "categories" AS (
+			INSERT INTO "task_categories" ("task_id", "category_id")
+			SELECT "next"."id", "tc"."category_id" FROM "next", "task_categories" "tc" WHERE "tc"."task_id" = $7
+		)
+		SELECT ` + taskColumns + ` FROM "next"`
+
+	var created model.Task
+	row := q.QueryRowContext(
+		c, query,
+		task.Title, task.Priority, next, task.ParentID, task.Recurrence, task.RecurrenceStart, task.ID,
+		task.ProjectID, task.OwnerID,
+	)
+	if err := scanTask(row, &created); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	categories, err := loadCategories(c, q, []int{created.ID})
+	if err != nil {
+		return err
+	}
+	created.Categories = categories[created.ID]
+
+	return recordHistory(c, q, model.EntityTask, created.ID, model.HistoryCreate, nil, created)
+}
+
+// lockTask returns a task that is not in the trash with its categories,
+// locking its row until the transaction ends
+func lockTask(c context.Context, q querier, id int) (model.Task, error) {
+	var task model.Task
+
+	query := `SELECT ` + taskColumns + ` FROM "tasks" WHERE "id" = $1 AND "deleted_at" IS NULL FOR UPDATE`
+	err := scanTask(q.QueryRowContext(c, query, id), &task)
+	if errors.Is(err, sql.ErrNoRows) {
+		return task, fmt.Errorf("%w: \"id\" %d", ErrTaskNotFound, id)
+	}
+	if err != nil {
+		return task, err
+	}
+
+	categories, err := loadCategories(c, q, []int{id})
+	task.Categories = categories[id]
+	return task, err
+}
+
+// nullIfEmpty stores an absent or empty optional string as NULL
+func nullIfEmpty(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return s
 }
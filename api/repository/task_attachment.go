@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/storage"
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+var ErrAttachmentNotFound = errors.New("error: attachment not found")
+
+const attachmentColumns = `"id", "task_id", "filename", "content_type", "size", "sha256", "blob_key", "created_at"`
+
+// AttachmentRepository keeps attachment metadata in Postgres and their content in a blob store
+type AttachmentRepository struct {
+	store *Store
+	blobs storage.BlobStore
+}
+
+func NewAttachmentRepo(store *Store, blobs storage.BlobStore) *AttachmentRepository {
+	return &AttachmentRepository{store, blobs}
+}
+
+func scanAttachment(row scanner, attachment *model.TaskAttachment, extra ...interface{}) error {
+	dest := []interface{}{
+		&attachment.ID,
+		&attachment.TaskID,
+		&attachment.Filename,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.SHA256,
+		&attachment.BlobKey,
+		&attachment.CreatedAt,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// List returns a page of the attachments of a task, oldest first
+func (r AttachmentRepository) List(
+	ctx context.Context,
+	args model.TaskURLParams,
+	p *types.Pageable,
+) (model.TaskAttachments, error) {
+	var attachments model.TaskAttachments
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		if err := taskExists(ctx, tx, args.ID); err != nil {
+			return err
+		}
+
+		var err error
+		attachments, err = paginate(ctx, tx, listQuery{
+			table:      "task_attachments",
+			columns:    attachmentColumns,
+			conditions: []string{`"task_id" = $1`},
+			params:     []interface{}{args.ID},
+			schema:     model.TaskAttachmentFields,
+		}, p, func(row scanner, attachment *model.TaskAttachment, key ...interface{}) error {
+			return scanAttachment(row, attachment, key...)
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.Calc()
+	return attachments, nil
+}
+
+// Get returns the metadata of an attachment of a task
+func (r AttachmentRepository) Get(
+	ctx context.Context,
+	args model.TaskURLParams,
+	attachmentID int,
+) (model.TaskAttachment, error) {
+	var attachment model.TaskAttachment
+
+	query := `SELECT ` + attachmentColumns + ` FROM "task_attachments" WHERE "id" = $1 AND "task_id" = $2`
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		return scanAttachment(tx.QueryRowContext(ctx, query, attachmentID, args.ID), &attachment)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return attachment, fmt.Errorf("%w: \"id\" %d", ErrAttachmentNotFound, attachmentID)
+	}
+
+	return attachment, err
+}
+
+// Open returns an attachment with a reader over its content
+func (r AttachmentRepository) Open(
+	ctx context.Context,
+	args model.TaskURLParams,
+	attachmentID int,
+) (model.TaskAttachment, io.ReadSeekCloser, error) {
+	attachment, err := r.Get(ctx, args, attachmentID)
+	if err != nil {
+		return attachment, nil, err
+	}
+
+	content, err := r.blobs.Open(ctx, attachment.BlobKey)
+	return attachment, content, err
+}
+
+// Create stores an uploaded file and records its metadata. The content is
+// hashed while it is written, so it is read only once.
+func (r AttachmentRepository) Create(
+	ctx context.Context,
+	args model.TaskURLParams,
+	upload model.AttachmentUpload,
+) (model.TaskAttachment, error) {
+	var attachment model.TaskAttachment
+
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		return taskExists(ctx, tx, args.ID)
+	})
+	if err != nil {
+		return attachment, err
+	}
+
+	file, err := upload.File.Open()
+	if err != nil {
+		return attachment, err
+	}
+	defer file.Close()
+
+	key, err := blobKey(args.ID)
+	if err != nil {
+		return attachment, err
+	}
+
+	hash := sha256.New()
+	if err := r.blobs.Put(ctx, key, io.TeeReader(file, hash), upload.File.Size, upload.ContentType()); err != nil {
+		return attachment, err
+	}
+
+	query := `INSERT INTO "task_attachments" ("task_id", "filename", "content_type", "size", "sha256", "blob_key")
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING ` + attachmentColumns
+	err = r.store.run(ctx, func(tx *sql.Tx) error {
+		row := tx.QueryRowContext(
+			ctx, query,
+			args.ID, upload.Filename(), upload.ContentType(), upload.File.Size, hex.EncodeToString(hash.Sum(nil)), key,
+		)
+		return scanAttachment(row, &attachment)
+	})
+	if err != nil {
+		// the task may have been deleted meanwhile: do not leave the blob behind
+		if deleteErr := r.blobs.Delete(ctx, key); deleteErr != nil {
+			slog.Error(deleteErr.Error())
+		}
+		return attachment, err
+	}
+
+	return attachment, nil
+}
+
+// Delete removes an attachment and its content. The attachment is gone once
+// its row is, so a blob that cannot be removed yet is only logged: it stays
+// queued for the next CollectGarbage.
+func (r AttachmentRepository) Delete(
+	ctx context.Context,
+	args model.TaskURLParams,
+	attachmentID int,
+) error {
+	query := `DELETE FROM "task_attachments" WHERE "id" = $1 AND "task_id" = $2`
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, attachmentID, args.ID)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			return fmt.Errorf("%w: \"id\" %d", ErrAttachmentNotFound, attachmentID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := r.CollectGarbage(ctx); err != nil {
+		slog.Error("failed to collect attachment blobs: " + err.Error())
+	}
+	return nil
+}
+
+// CollectGarbage removes the blobs of deleted attachments. Every removed
+// attachment row queues its blob in "blob_garbage", including rows removed
+// by a cascading task delete, so this is safe to call at any time. The queue
+// is shared by the workspaces and holds no data of theirs but blob keys.
+func (r AttachmentRepository) CollectGarbage(ctx context.Context) error {
+	keys, err := r.store.blobGarbage(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := r.blobs.Delete(ctx, key); err != nil {
+			return err
+		}
+
+		if err := r.store.dropBlobGarbage(ctx, key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// blobKey returns a new, unguessable key for a blob of a task
+func blobKey(taskID int) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("tasks/%d/%s", taskID, hex.EncodeToString(random)), nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+)
+
+// BatchOutcome is what one operation of a batch produced: the created or
+// updated task, nothing for a delete, or the error that made it fail
+type BatchOutcome struct {
+	Task *model.Task
+	Err  error
+}
+
+// Batch runs validated operations in order inside a single transaction.
+// When atomic, the first failing operation rolls everything back and the
+// operations after it are not run; their outcomes are left empty. Otherwise
+// every operation runs in its own savepoint so that a failure only undoes
+// that operation, and the rest are committed.
+func (r TaskRepository) Batch(
+	c context.Context, ops []model.BatchOperation, atomic bool,
+) ([]BatchOutcome, error) {
+	outcomes := make([]BatchOutcome, len(ops))
+
+	tx, err := r.store.BeginTx(c, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for i, op := range ops {
+		if atomic {
+			if outcomes[i].Task, outcomes[i].Err = runBatchOp(c, tx, op); outcomes[i].Err != nil {
+				return outcomes, nil
+			}
+			continue
+		}
+
+		if _, err := tx.ExecContext(c, `SAVEPOINT "batch_op"`); err != nil {
+			return nil, err
+		}
+
+		release := `RELEASE SAVEPOINT "batch_op"`
+		if outcomes[i].Task, outcomes[i].Err = runBatchOp(c, tx, op); outcomes[i].Err != nil {
+			release = `ROLLBACK TO SAVEPOINT "batch_op"`
+		}
+
+		if _, err := tx.ExecContext(c, release); err != nil {
+			return nil, err
+		}
+	}
+
+	return outcomes, tx.Commit()
+}
+
+func runBatchOp(c context.Context, q querier, op model.BatchOperation) (*model.Task, error) {
+	var task model.Task
+	var err error
+
+	switch op.Op {
+	case model.BatchCreate:
+		task, err = createTask(c, q, *op.Task)
+	case model.BatchUpdate:
+		task, err = updateTask(c, q, op.Params(), *op.Task)
+	default:
+		args := op.Params()
+		if op.Permanent {
+			return nil, deletePermanently(c, q, args, op.Children)
+		}
+		return nil, trashTask(c, q, args, op.Children)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
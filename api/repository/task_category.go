@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+var ErrUnknownCategory = errors.New("error: \"category_ids\" references a category that does not exist")
+
+// ListByCategory returns the tasks tagged with a category
+func (r TaskRepository) ListByCategory(
+	ctx context.Context,
+	args model.RequestURLParam,
+	p *types.Pageable,
+	filter model.TaskFilter,
+) (model.Tasks, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM "categories" WHERE "id" = $1)`
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		return tx.QueryRowContext(ctx, query, args.ID).Scan(&exists)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%w: \"id\" %d", ErrCategoryNotFound, args.ID)
+	}
+
+	filter.CategoryIDs = []int{args.ID}
+	return r.List(ctx, p, filter)
+}
+
+// setTaskCategories replaces the categories of a task and returns them
+func setTaskCategories(c context.Context, q querier, taskID int, ids []int) (model.Categories, error) {
+	if _, err := q.ExecContext(c, `DELETE FROM "task_categories" WHERE "task_id" = $1`, taskID); err != nil {
+		return nil, err
+	}
+
+	if len(ids) > 0 {
+		query := `INSERT INTO "task_categories" ("task_id", "category_id")
+			SELECT $1, unnest($2::bigint[]) ON CONFLICT DO NOTHING`
+		if _, err := q.ExecContext(c, query, taskID, pq.Array(ids)); err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Constraint == "task_categories_category_id_fkey" {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownCategory, pqErr.Detail)
+			}
+			return nil, err
+		}
+	}
+
+	categories, err := loadCategories(c, q, []int{taskID})
+	return categories[taskID], err
+}
+
+// withCategories fills in the categories of every task
+func withCategories(c context.Context, q querier, tasks model.Tasks) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	categories, err := loadCategories(c, q, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range tasks {
+		tasks[i].Categories = categories[tasks[i].ID]
+	}
+	return nil
+}
+
+// loadCategories returns the categories of the given tasks by task id,
+// with an empty list for tasks without categories
+func loadCategories(c context.Context, q querier, taskIDs []int) (map[int]model.Categories, error) {
+	query := `SELECT "tc"."task_id", "c"."id", "c"."label"
+		FROM "task_categories" "tc" JOIN "categories" "c" ON "c"."id" = "tc"."category_id"
+		WHERE "tc"."task_id" = ANY($1) ORDER BY "c"."label", "c"."id"`
+
+	rows, err := q.QueryContext(c, query, pq.Array(taskIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := make(map[int]model.Categories, len(taskIDs))
+	for _, id := range taskIDs {
+		categories[id] = model.Categories{}
+	}
+
+	for rows.Next() {
+		var taskID int
+		var category model.Category
+		if err := rows.Scan(&taskID, &category.ID, &category.Label); err != nil {
+			return nil, err
+		}
+		categories[taskID] = append(categories[taskID], category)
+	}
+
+	return categories, rows.Err()
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+var ErrCommentNotFound = errors.New("error: comment not found")
+
+// commentColumns selects a comment with its author: the user who posted it,
+// or the author of a comment from before user accounts
+const commentColumns = `"id", "task_id", "user_id", COALESCE("user_id", "author_id", 0),
+	COALESCE(
+		(SELECT "name" FROM "users" WHERE "users"."id" = "task_comments"."user_id"),
+		(SELECT "name" FROM "authors" WHERE "authors"."id" = "task_comments"."author_id"),
+		''
+	),
+	"body", "created_at", "edited_at", "deleted_at"`
+
+// commentCountExpr counts the comments of the task row that are not deleted
+const commentCountExpr = `(SELECT COUNT(*) FROM "task_comments" "c"
+	WHERE "c"."task_id" = "tasks"."id" AND "c"."deleted_at" IS NULL)`
+
+// scanComment scans a row selected with commentColumns, redacting deleted comments
+func scanComment(row scanner, comment *model.TaskComment, extra ...interface{}) error {
+	dest := []interface{}{
+		&comment.ID,
+		&comment.TaskID,
+		&comment.UserID,
+		&comment.Author.ID,
+		&comment.Author.Name,
+		&comment.Body,
+		&comment.CreatedAt,
+		&comment.EditedAt,
+		&comment.DeletedAt,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+
+	comment.Redact()
+	return nil
+}
+
+// Comments returns a page of the comments of a task, oldest first
+func (r TaskRepository) Comments(
+	ctx context.Context,
+	args model.TaskURLParams,
+	p *types.Pageable,
+) (model.TaskComments, error) {
+	var comments model.TaskComments
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		if err := taskExists(ctx, tx, args.ID); err != nil {
+			return err
+		}
+
+		var err error
+		comments, err = paginate(ctx, tx, listQuery{
+			table:      "task_comments",
+			columns:    commentColumns,
+			conditions: []string{`"task_id" = $1`},
+			params:     []interface{}{args.ID},
+			schema:     model.TaskCommentFields,
+		}, p, func(row scanner, comment *model.TaskComment, key ...interface{}) error {
+			return scanComment(row, comment, key...)
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.Calc()
+	return comments, nil
+}
+
+// Comment returns a comment of a task
+func (r TaskRepository) Comment(
+	ctx context.Context,
+	args model.TaskURLParams,
+	commentID int,
+) (model.TaskComment, error) {
+	var comment model.TaskComment
+
+	query := `SELECT ` + commentColumns + ` FROM "task_comments" WHERE "id" = $1 AND "task_id" = $2`
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		return scanComment(tx.QueryRowContext(ctx, query, commentID, args.ID), &comment)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return comment, fmt.Errorf("%w: \"id\" %d", ErrCommentNotFound, commentID)
+	}
+
+	return comment, err
+}
+
+// AddComment posts a comment on a task as the user of the request
+func (r TaskRepository) AddComment(
+	ctx context.Context,
+	args model.TaskURLParams,
+	payload model.TaskCommentPayload,
+) (model.TaskComment, error) {
+	var comment model.TaskComment
+
+	query := `INSERT INTO "task_comments" ("task_id", "user_id", "body") VALUES ($1, $2, $3)
+		RETURNING ` + commentColumns
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		return scanComment(tx.QueryRowContext(ctx, query, args.ID, ownerID(ctx), payload.Body), &comment)
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Constraint == "task_comments_task_id_fkey" {
+			return comment, fmt.Errorf("%w: \"id\" %d", ErrTaskNotFound, args.ID)
+		}
+		return comment, err
+	}
+
+	return comment, nil
+}
+
+// EditComment replaces the body of a comment and records when it was edited.
+// Deleted comments cannot be edited, see canChangeComment for who can.
+func (r TaskRepository) EditComment(
+	ctx context.Context,
+	args model.TaskURLParams,
+	commentID int,
+	body string,
+) (model.TaskComment, error) {
+	var comment model.TaskComment
+
+	query := `UPDATE "task_comments" SET "body" = $1, "edited_at" = now()
+		WHERE "id" = $2 AND "task_id" = $3 AND "deleted_at" IS NULL
+		RETURNING ` + commentColumns
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		if err := canChangeComment(ctx, tx, args, commentID); err != nil {
+			return err
+		}
+		return scanComment(tx.QueryRowContext(ctx, query, body, commentID, args.ID), &comment)
+	})
+
+	return comment, err
+}
+
+// DeleteComment soft-deletes a comment: it stays in the thread as "[deleted]".
+// See canChangeComment for who can delete it.
+func (r TaskRepository) DeleteComment(
+	ctx context.Context,
+	args model.TaskURLParams,
+	commentID int,
+) error {
+	query := `UPDATE "task_comments" SET "deleted_at" = now() WHERE "id" = $1`
+	return r.store.run(ctx, func(tx *sql.Tx) error {
+		if err := canChangeComment(ctx, tx, args, commentID); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, query, commentID)
+		return err
+	})
+}
+
+// canChangeComment locks a comment that is not deleted and lets only its
+// author or an owner of the workspace through. Comments from before user
+// accounts have no user, only owners can change them.
+func canChangeComment(ctx context.Context, q querier, args model.TaskURLParams, commentID int) error {
+	var userID *int
+	query := `SELECT "user_id" FROM "task_comments"
+		WHERE "id" = $1 AND "task_id" = $2 AND "deleted_at" IS NULL FOR UPDATE`
+	err := q.QueryRowContext(ctx, query, commentID, args.ID).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: \"id\" %d", ErrCommentNotFound, commentID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if types.WorkspaceRoleFrom(ctx) == model.RoleOwner {
+		return nil
+	}
+	if principal, ok := types.PrincipalFrom(ctx); ok && userID != nil && *userID == principal.UserID {
+		return nil
+	}
+	return model.ErrCommentForbidden
+}
+
+// taskExists returns ErrTaskNotFound unless the task exists outside of the trash
+func taskExists(ctx context.Context, q querier, id int) error {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM "tasks" WHERE "id" = $1 AND "deleted_at" IS NULL)`
+	if err := q.QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("%w: \"id\" %d", ErrTaskNotFound, id)
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+)
+
+var ErrDependencyNotFound = errors.New("error: dependency not found")
+
+// blockedExpr is true while any task the row depends on is still open and not in the trash
+const blockedExpr = `EXISTS (
+	SELECT 1 FROM "task_dependencies" "d" JOIN "tasks" "b" ON "b"."id" = "d"."depends_on_id"
+	WHERE "d"."task_id" = "tasks"."id" AND "b"."status" NOT IN ('done', 'cancelled')
+		AND "b"."deleted_at" IS NULL
+)`
+
+// Dependencies returns the tasks that block the given task
+func (r TaskRepository) Dependencies(
+	ctx context.Context,
+	args model.TaskURLParams,
+) (model.Tasks, error) {
+	query := `SELECT ` + taskColumnsOf("t") + ` FROM "tasks" "t"
+		JOIN "task_dependencies" "d" ON "d"."depends_on_id" = "t"."id"
+		WHERE "d"."task_id" = $1 AND "t"."deleted_at" IS NULL ORDER BY "t"."id"`
+
+	var tasks model.Tasks
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, query, args.ID)
+		if err != nil {
+			return err
+		}
+
+		tasks, err = collectTasks(rows)
+		return err
+	})
+	return tasks, err
+}
+
+// AddDependency records that the task is blocked by dependsOn,
+// rejecting any edge that would close a cycle
+func (r TaskRepository) AddDependency(
+	ctx context.Context,
+	args model.TaskURLParams,
+	dependsOn int,
+) (model.TaskDependency, error) {
+	dependency := model.TaskDependency{TaskID: args.ID, DependsOnID: dependsOn}
+
+	tx, err := r.store.BeginTx(ctx, nil)
+	if err != nil {
+		return dependency, err
+	}
+	defer tx.Rollback()
+
+	// serialize writers so two concurrent edges cannot form a cycle together
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE "task_dependencies" IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return dependency, err
+	}
+
+	// a cycle exists when dependsOn already (transitively) depends on the task
+	var cyclic bool
+	query := `WITH RECURSIVE "upstream" AS (
+		SELECT "depends_on_id" AS "id" FROM "task_dependencies" WHERE "task_id" = $1
+		UNION
+		SELECT "d"."depends_on_id" FROM "task_dependencies" "d" JOIN "upstream" ON "d"."task_id" = "upstream"."id"
+	) SELECT EXISTS (SELECT 1 FROM "upstream" WHERE "id" = $2)`
+	if err := tx.QueryRowContext(ctx, query, dependsOn, args.ID).Scan(&cyclic); err != nil {
+		return dependency, err
+	}
+
+	if cyclic {
+		return dependency, fmt.Errorf("%w: task %d already depends on task %d", model.ErrDependencyCycle, dependsOn, args.ID)
+	}
+
+	query = `INSERT INTO "task_dependencies" ("task_id", "depends_on_id") VALUES ($1, $2)
+		ON CONFLICT ("task_id", "depends_on_id") DO UPDATE SET "task_id" = EXCLUDED."task_id"
+		RETURNING "created_at"`
+	if err := tx.QueryRowContext(ctx, query, args.ID, dependsOn).Scan(&dependency.CreatedAt); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+			return dependency, ErrTaskNotFound
+		}
+		return dependency, err
+	}
+
+	return dependency, tx.Commit()
+}
+
+// RemoveDependency deletes the edge between the task and dependsOn
+func (r TaskRepository) RemoveDependency(
+	ctx context.Context,
+	args model.TaskURLParams,
+	dependsOn int,
+) error {
+	query := `DELETE FROM "task_dependencies" WHERE "task_id" = $1 AND "depends_on_id" = $2`
+	return r.store.run(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, args.ID, dependsOn)
+		if err != nil {
+			return err
+		}
+
+		if affected, err := result.RowsAffected(); err != nil {
+			return err
+		} else if affected == 0 {
+			return ErrDependencyNotFound
+		}
+
+		return nil
+	})
+}
+
+// ExecutionOrder returns the tasks matching filter, with every open task
+// blocking them even when it does not match filter, in an order where every
+// task comes after the tasks blocking it. It fails with
+// ErrExecutionOrderTooLong beyond model.MaxExecutionOrder tasks.
+func (r TaskRepository) ExecutionOrder(
+	ctx context.Context,
+	filter model.TaskFilter,
+) (model.Tasks, error) {
+	conditions, params := taskConditions(filter)
+	params = append(params, model.MaxExecutionOrder+1)
+	// blockers are followed as far as blockedExpr would, through open tasks
+	// that are not in the trash
+	query := fmt.Sprintf(`WITH RECURSIVE "ordered" AS (
+			SELECT "id" FROM "tasks" %s
+			UNION
+			SELECT "b"."id" FROM "ordered" "o"
+			JOIN "task_dependencies" "d" ON "d"."task_id" = "o"."id"
+			JOIN "tasks" "b" ON "b"."id" = "d"."depends_on_id"
+			WHERE "b"."status" NOT IN ('done', 'cancelled') AND "b"."deleted_at" IS NULL
+		)
+		SELECT `+taskColumns+` FROM "tasks" WHERE "id" IN (SELECT "id" FROM "ordered")
+		ORDER BY "id" LIMIT $%d`, whereSQL(conditions), len(params))
+
+	var tasks model.Tasks
+	var edges []model.TaskDependency
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, query, params...)
+		if err != nil {
+			return err
+		}
+
+		if tasks, err = collectTasks(rows); err != nil {
+			return err
+		}
+		if len(tasks) > model.MaxExecutionOrder {
+			return fmt.Errorf("%w: at most %d are listed", model.ErrExecutionOrderTooLong, model.MaxExecutionOrder)
+		}
+
+		ids := make([]int, len(tasks))
+		for i, task := range tasks {
+			ids[i] = task.ID
+		}
+
+		edges, err = dependencyEdges(ctx, tx, ids)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks.TopologicalOrder(edges)
+}
+
+// dependencyEdges returns the dependencies between the tasks of ids
+func dependencyEdges(ctx context.Context, q querier, ids []int) ([]model.TaskDependency, error) {
+	query := `SELECT "task_id", "depends_on_id", "created_at" FROM "task_dependencies"
+		WHERE "task_id" = ANY($1) AND "depends_on_id" = ANY($1)`
+
+	rows, err := q.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var edges []model.TaskDependency
+	for rows.Next() {
+		var edge model.TaskDependency
+		if err := rows.Scan(&edge.TaskID, &edge.DependsOnID, &edge.CreatedAt); err != nil {
+			return nil, err
+		}
+		edges = append(edges, edge)
+	}
+
+	return edges, rows.Err()
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+)
+
+// Export returns every task matching filter in sort order, with their
+// categories, for the feeds and exports that are not paginated
+func (r TaskRepository) Export(ctx context.Context, filter model.TaskFilter) (model.Tasks, error) {
+	conditions, params := taskConditions(filter)
+	query := `SELECT ` + taskColumns + ` FROM "tasks" ` + whereSQL(conditions) + ` ORDER BY ` + orderSQL(filter.Sort)
+
+	var tasks model.Tasks
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, query, params...)
+		if err != nil {
+			return err
+		}
+
+		if tasks, err = collectTasks(rows); err != nil {
+			return err
+		}
+
+		return withCategories(ctx, tx, tasks)
+	})
+	return tasks, err
+}
+
+// Stream calls fn with every task matching filter in sort order, with their
+// categories, reading them from the database one at a time
+func (r TaskRepository) Stream(ctx context.Context, filter model.TaskFilter, fn func(model.Task) error) error {
+	conditions, params := taskConditions(filter)
+	query := `SELECT ` + taskColumns + `,
+		ARRAY(SELECT "c"."id" FROM "task_categories" "tc" JOIN "categories" "c" ON "c"."id" = "tc"."category_id"
+			WHERE "tc"."task_id" = "tasks"."id" ORDER BY "c"."label", "c"."id"),
+		ARRAY(SELECT "c"."label" FROM "task_categories" "tc" JOIN "categories" "c" ON "c"."id" = "tc"."category_id"
+			WHERE "tc"."task_id" = "tasks"."id" ORDER BY "c"."label", "c"."id")
+		FROM "tasks" ` + whereSQL(conditions) + ` ORDER BY ` + orderSQL(filter.Sort)
+
+	return r.store.run(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, query, params...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var task model.Task
+			var ids pq.Int64Array
+			var labels pq.StringArray
+			if err := scanTask(rows, &task, &ids, &labels); err != nil {
+				return err
+			}
+
+			task.Categories = make(model.Categories, len(ids))
+			for i, id := range ids {
+				task.Categories[i] = model.Category{ID: int(id), Label: labels[i]}
+			}
+
+			if err := fn(task); err != nil {
+				return err
+			}
+		}
+
+		return rows.Err()
+	})
+}
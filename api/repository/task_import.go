@@ -0,0 +1,525 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+// Import upserts tasks read from another tool inside a single transaction and
+// reports what happened to each of them. Items that match an existing task
+// update it one by one, each in its own savepoint, so an item that fails is
+// skipped with its error as the reason and the others are kept. New tasks are
+// checked up front and created together with COPY. Parents are linked once
+// every item is in, which lets an item point at one that comes after it in the
+// file. A dry run does all of it and rolls back.
+func (r TaskRepository) Import(
+	c context.Context, items []model.ImportedTask, dryRun bool,
+) (model.ImportReport, error) {
+	report := model.ImportReport{DryRun: dryRun, Categories: []model.Category{}, Items: []model.ImportItem{}}
+
+	tx, err := r.store.BeginTx(c, nil)
+	if err != nil {
+		return report, err
+	}
+	defer tx.Rollback()
+
+	categories, err := categoryLabels(c, tx)
+	if err != nil {
+		return report, err
+	}
+	if report.Categories, err = createImportedCategories(c, tx, items, categories); err != nil {
+		return report, err
+	}
+
+	entries := make([]model.ImportItem, len(items))
+	// imported maps the index of every created or updated item to its task,
+	// external maps external ids to the tasks they were imported as
+	imported := map[int]int{}
+	external := map[string]int{}
+	var creates []int
+
+	for i, item := range items {
+		entries[i] = model.ImportItem{
+			Index: i, Line: item.Line, ExternalID: item.ExternalID, Title: item.Payload.Title,
+		}
+
+		if item.Skip != "" {
+			entries[i].Action, entries[i].Reason = model.ImportSkipped, item.Skip
+			continue
+		}
+
+		var task model.Task
+		failed, err := savepoint(c, tx, "import_item", func() error {
+			id, err := findImportedTask(c, tx, item)
+			if err != nil || id == 0 {
+				return err
+			}
+			entries[i].Action = model.ImportUpdated
+			task, err = updateImportedTask(c, tx, id, item, categories)
+			return err
+		})
+		if err != nil {
+			return report, err
+		}
+		if failed != nil {
+			entries[i].Action, entries[i].Reason = model.ImportSkipped, failed.Error()
+			continue
+		}
+
+		if entries[i].Action != model.ImportUpdated {
+			creates = append(creates, i)
+			continue
+		}
+
+		entries[i].TaskID = task.ID
+		imported[i] = task.ID
+		if item.ExternalID != "" {
+			external[item.ExternalID] = task.ID
+		}
+	}
+
+	// skip what COPY would fail on, so that one bad item does not fail them all
+	var created []model.ImportedTask
+	var payloads []model.TaskRequestPayload
+	var indexes []int
+	for _, i := range creates {
+		item := items[i]
+		payload := importPayload(item, categories)
+		if err := payload.Validate(); err != nil {
+			entries[i].Action, entries[i].Reason = model.ImportSkipped, err.Error()
+			continue
+		}
+		if payload.ProjectID != nil {
+			err := lockProject(c, tx, *payload.ProjectID)
+			if errors.Is(err, ErrUnknownProject) || errors.Is(err, model.ErrProjectArchived) {
+				entries[i].Action, entries[i].Reason = model.ImportSkipped, err.Error()
+				continue
+			}
+			if err != nil {
+				return report, err
+			}
+		}
+		if item.ExternalID != "" {
+			if _, ok := external[item.ExternalID]; ok {
+				entries[i].Action = model.ImportSkipped
+				entries[i].Reason = fmt.Sprintf("error: external id %q appears more than once", item.ExternalID)
+				continue
+			}
+			external[item.ExternalID] = 0
+		}
+		created = append(created, item)
+		payloads = append(payloads, payload)
+		indexes = append(indexes, i)
+	}
+
+	tasks, err := copyTasks(c, tx, created, payloads)
+	if err != nil {
+		return report, err
+	}
+	for n, i := range indexes {
+		entries[i].Action, entries[i].TaskID = model.ImportCreated, tasks[n].ID
+		imported[i] = tasks[n].ID
+		if items[i].ExternalID != "" {
+			external[items[i].ExternalID] = tasks[n].ID
+		}
+	}
+
+	for i, item := range items {
+		id, ok := imported[i]
+		if !ok || !item.HasParent() {
+			continue
+		}
+
+		failed, err := savepoint(c, tx, "import_parent", func() error {
+			return linkImportedParent(c, tx, id, item, external)
+		})
+		if err != nil {
+			return report, err
+		}
+		if failed != nil {
+			entries[i].Reason = failed.Error()
+		}
+	}
+
+	for _, entry := range entries {
+		if dryRun && entry.Action == model.ImportCreated {
+			entry.TaskID = 0
+		}
+		report.Add(entry)
+	}
+
+	if dryRun {
+		return report, tx.Rollback()
+	}
+	return report, tx.Commit()
+}
+
+// savepoint runs fn inside a savepoint and rolls back to it when fn fails.
+// failed is the error of fn, err is set when the transaction itself failed.
+func savepoint(c context.Context, q querier, name string, fn func() error) (failed, err error) {
+	if _, err := q.ExecContext(c, `SAVEPOINT "`+name+`"`); err != nil {
+		return nil, err
+	}
+
+	release := `RELEASE SAVEPOINT "` + name + `"`
+	if failed = fn(); failed != nil {
+		release = `ROLLBACK TO SAVEPOINT "` + name + `"`
+	}
+
+	_, err = q.ExecContext(c, release)
+	return failed, err
+}
+
+// categoryLabels maps the lowercased label of every category to its id
+func categoryLabels(c context.Context, q querier) (map[string]int, error) {
+	rows, err := q.QueryContext(c, `SELECT "id", "label" FROM "categories"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	labels := map[string]int{}
+	for rows.Next() {
+		var id int
+		var label string
+		if err := rows.Scan(&id, &label); err != nil {
+			return nil, err
+		}
+		labels[strings.ToLower(label)] = id
+	}
+
+	return labels, rows.Err()
+}
+
+// createImportedCategories creates the categories that items name and that do
+// not exist yet, adding them to categories
+func createImportedCategories(
+	c context.Context, q querier, items []model.ImportedTask, categories map[string]int,
+) ([]model.Category, error) {
+	created := []model.Category{}
+
+	for _, item := range items {
+		if item.Skip != "" {
+			continue
+		}
+
+		for _, name := range item.Categories {
+			label := model.CategoryLabel(name)
+			if _, ok := categories[strings.ToLower(label)]; ok || label == "" {
+				continue
+			}
+
+			var category model.Category
+			query := `INSERT INTO "categories" ("label", "owner_id") VALUES ($1, $2) RETURNING ` + categoryColumns
+			if err := scanCategory(q.QueryRowContext(c, query, label, ownerID(c)), &category); err != nil {
+				return nil, labelError(err, label)
+			}
+			if err := recordHistory(c, q, model.EntityCategory, category.ID, model.HistoryCreate, nil, category); err != nil {
+				return nil, err
+			}
+
+			categories[strings.ToLower(label)] = category.ID
+			created = append(created, category)
+		}
+	}
+
+	return created, nil
+}
+
+// importPayload returns the fields of an item without its parent, with the
+// ids of the categories it names. CategoryIDs stays nil when the item has none.
+// A category named twice is tagged once.
+func importPayload(item model.ImportedTask, categories map[string]int) model.TaskRequestPayload {
+	payload := item.Payload
+	payload.ParentID = nil
+	payload.CategoryIDs = nil
+	if item.Categories != nil {
+		payload.CategoryIDs = []int{}
+		tagged := map[int]bool{}
+		for _, label := range item.Categories {
+			if id, ok := categories[strings.ToLower(model.CategoryLabel(label))]; ok && !tagged[id] {
+				payload.CategoryIDs = append(payload.CategoryIDs, id)
+				tagged[id] = true
+			}
+		}
+	}
+	return payload
+}
+
+// updateImportedTask overwrites a task with an item, leaving its parent alone.
+// An item that names no project keeps the task in its project.
+func updateImportedTask(
+	c context.Context, q querier, id int, item model.ImportedTask, categories map[string]int,
+) (model.Task, error) {
+	payload := importPayload(item, categories)
+	if err := payload.Validate(); err != nil {
+		return model.Task{}, err
+	}
+
+	current, err := lockTask(c, q, id)
+	if err != nil {
+		return current, err
+	}
+
+	payload.ParentID = current.ParentID
+	if payload.ProjectID == nil {
+		payload.ProjectID = current.ProjectID
+	}
+	if payload.CategoryIDs == nil {
+		payload.CategoryIDs = current.Payload().CategoryIDs
+	}
+	task, err := updateTask(c, q, model.TaskURLParams{ID: id}, payload.Overwrite())
+	if err != nil {
+		return task, err
+	}
+
+	if item.Status == "" || (item.Status == task.Status && item.CompletedAt == nil) {
+		return task, nil
+	}
+
+	return setImportedStatus(c, q, task, item)
+}
+
+// findImportedTask returns the id of the task an item updates, 0 when it is new.
+// An item that points at one of our tasks must find it outside of the trash.
+func findImportedTask(c context.Context, q querier, item model.ImportedTask) (int, error) {
+	var id int
+	var trashed bool
+
+	if item.ID > 0 {
+		query := `SELECT "id", "deleted_at" IS NOT NULL FROM "tasks" WHERE "id" = $1`
+		err := q.QueryRowContext(c, query, item.ID).Scan(&id, &trashed)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%w: \"id\" %d", ErrTaskNotFound, item.ID)
+		}
+		if err != nil {
+			return 0, err
+		}
+	} else if item.ExternalID != "" {
+		query := `SELECT "id", "deleted_at" IS NOT NULL FROM "tasks" WHERE "external_id" = $1`
+		err := q.QueryRowContext(c, query, item.ExternalID).Scan(&id, &trashed)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if trashed {
+		return 0, fmt.Errorf("error: task %d is in the trash, restore it to import it again", id)
+	}
+
+	return id, nil
+}
+
+// setImportedStatus applies the status of an item as is: the other tool has
+// already gone through the lifecycle, so the transitions are not enforced
+func setImportedStatus(c context.Context, q querier, before model.Task, item model.ImportedTask) (model.Task, error) {
+	var task model.Task
+
+	query := `UPDATE "tasks" SET
+		"status" = $1::varchar,
+		"completed_at" = CASE WHEN $1::varchar = 'done' THEN COALESCE($2::timestamp, "completed_at", now()) ELSE NULL END,
+		"updated_at" = now()
+		WHERE "id" = $3 RETURNING ` + taskColumns
+
+	if err := scanTask(q.QueryRowContext(c, query, item.Status, item.CompletedAt, before.ID), &task); err != nil {
+		return task, err
+	}
+	task.Categories = before.Categories
+
+	return task, recordHistory(c, q, model.EntityTask, task.ID, model.HistoryUpdate, before, task)
+}
+
+// copyTasks creates the tasks of items, without parents, with COPY and records
+// their creation. It returns them in the order of items.
+func copyTasks(
+	c context.Context, tx *sql.Tx, items []model.ImportedTask, payloads []model.TaskRequestPayload,
+) (model.Tasks, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	// COPY cannot return what it inserted, so the ids are reserved up front,
+	// in ascending order
+	query := `SELECT nextval(pg_get_serial_sequence('tasks', 'id')) FROM generate_series(1, $1)`
+	rows, err := tx.QueryContext(c, query, len(items))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(items))
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	owner := ownerID(c)
+	var taskRows, categoryRows [][]interface{}
+	for n, item := range items {
+		payload := payloads[n]
+
+		status := item.Status
+		if status == "" {
+			status = model.StatusTodo
+		}
+		// "now" is read by Postgres as the start of the transaction, like now()
+		var completedAt interface{}
+		if status == model.StatusDone {
+			completedAt = "now"
+			if item.CompletedAt != nil {
+				completedAt = *item.CompletedAt
+			}
+		}
+		var recurrenceStart interface{}
+		recurrence := nullIfEmpty(payload.Recurrence)
+		if recurrence != nil {
+			recurrenceStart = payload.Date
+		}
+		externalID := nullIfEmpty(&item.ExternalID)
+
+		taskRows = append(taskRows, []interface{}{
+			ids[n], payload.Title, payload.Priority, payload.Date, string(status), completedAt,
+			recurrence, recurrenceStart, externalID, payload.ProjectID, owner,
+		})
+		for _, categoryID := range payload.CategoryIDs {
+			categoryRows = append(categoryRows, []interface{}{ids[n], categoryID})
+		}
+	}
+
+	columns := []string{
+		"id", "title", "priority", "date", "status", "completed_at",
+		"recurrence", "recurrence_start", "external_id", "project_id", "owner_id",
+	}
+	if err := copyIn(c, tx, "tasks", columns, taskRows); err != nil {
+		return nil, err
+	}
+	if err := copyIn(c, tx, "task_categories", []string{"task_id", "category_id"}, categoryRows); err != nil {
+		return nil, err
+	}
+
+	query = `SELECT ` + taskColumns + ` FROM "tasks" WHERE "id" = ANY($1) ORDER BY "id"`
+	if rows, err = tx.QueryContext(c, query, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+	tasks, err := collectTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+	if err := withCategories(c, tx, tasks); err != nil {
+		return nil, err
+	}
+
+	audit := types.AuditFrom(c)
+	historyRows := make([][]interface{}, len(tasks))
+	for n, task := range tasks {
+		changes, err := model.Diff(nil, task)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(changes)
+		if err != nil {
+			return nil, err
+		}
+		historyRows[n] = []interface{}{
+			model.EntityTask, task.ID, string(model.HistoryCreate), string(data),
+			nullIfEmpty(&audit.Actor), nullIfEmpty(&audit.RequestID),
+		}
+	}
+	columns = []string{"entity", "entity_id", "action", "changes", "actor", "request_id"}
+	if err := copyIn(c, tx, "task_history", columns, historyRows); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// copyIn loads rows into a table with COPY FROM STDIN. COPY FROM does not
+// support tables under row-level security, so the rows are copied into a
+// temporary table first and inserted from there, which the policies check.
+func copyIn(c context.Context, tx *sql.Tx, table string, columns []string, rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	staging := "copy_" + table
+	list := `"` + strings.Join(columns, `", "`) + `"`
+	query := `CREATE TEMPORARY TABLE "` + staging + `" ON COMMIT DROP AS SELECT ` + list +
+		` FROM "` + table + `" WITH NO DATA`
+	if _, err := tx.ExecContext(c, query); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(c, pq.CopyIn(staging, columns...))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(c, row...); err != nil {
+			return err
+		}
+	}
+
+	if _, err := stmt.ExecContext(c); err != nil {
+		return err
+	}
+
+	query = `INSERT INTO "` + table + `" (` + list + `) SELECT ` + list + ` FROM "` + staging + `"`
+	if _, err := tx.ExecContext(c, query); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(c, `DROP TABLE "`+staging+`"`)
+	return err
+}
+
+// linkImportedParent moves an imported task below the parent its item names,
+// found among the imported items first and then among the existing tasks
+func linkImportedParent(c context.Context, q querier, id int, item model.ImportedTask, external map[string]int) error {
+	parentID := item.ParentID
+	if parentID == 0 {
+		var ok bool
+		if parentID, ok = external[item.ParentExternalID]; !ok {
+			query := `SELECT "id" FROM "tasks" WHERE "external_id" = $1 AND "deleted_at" IS NULL`
+			err := q.QueryRowContext(c, query, item.ParentExternalID).Scan(&parentID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w: parent %q was not found", model.ErrInvalidParent, item.ParentExternalID)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	current, err := lockTask(c, q, id)
+	if err != nil {
+		return err
+	}
+	if current.ParentID != nil && *current.ParentID == parentID {
+		return nil
+	}
+
+	payload := current.Payload()
+	payload.ParentID = &parentID
+	_, err = updateTask(c, q, model.TaskURLParams{ID: id}, payload.Overwrite())
+	return err
+}
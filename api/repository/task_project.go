@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+var ErrUnknownProject = errors.New("error: \"project_id\" references a project that does not exist")
+
+// ListByProject returns the tasks of a project
+func (r TaskRepository) ListByProject(
+	ctx context.Context,
+	args model.RequestURLParam,
+	p *types.Pageable,
+	filter model.TaskFilter,
+) (model.Tasks, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM "projects" WHERE "id" = $1)`
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		return tx.QueryRowContext(ctx, query, args.ID).Scan(&exists)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%w: \"id\" %d", ErrProjectNotFound, args.ID)
+	}
+
+	filter.ProjectID = &args.ID
+	return r.List(ctx, p, filter)
+}
+
+// MoveToProject moves tasks into a project along with their subtasks.
+// A moved subtask whose parent stays in another project is detached from it.
+func (r TaskRepository) MoveToProject(
+	c context.Context, args model.RequestURLParam, ids []int,
+) (model.Tasks, error) {
+	tx, err := r.store.BeginTx(c, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockProject(c, tx, args.ID); errors.Is(err, ErrUnknownProject) {
+		return nil, fmt.Errorf("%w: \"id\" %d", ErrProjectNotFound, args.ID)
+	} else if err != nil {
+		return nil, err
+	}
+
+	moving := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		moving[id] = true
+	}
+
+	var tasks model.Tasks
+	for _, id := range ids {
+		before, err := lockTask(c, tx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		parentID := before.ParentID
+		if parentID != nil && !moving[*parentID] {
+			var parentProject *int
+			query := `SELECT "project_id" FROM "tasks" WHERE "id" = $1`
+			if err := tx.QueryRowContext(c, query, *parentID).Scan(&parentProject); err != nil {
+				return nil, err
+			}
+			if parentProject == nil || *parentProject != args.ID {
+				parentID = nil
+			}
+		}
+
+		var task model.Task
+		query := `UPDATE "tasks" SET "project_id" = $1, "parent_id" = $2, "updated_at" = now()
+			WHERE "id" = $3 RETURNING ` + taskColumns
+		if err := scanTask(tx.QueryRowContext(c, query, args.ID, parentID, id), &task); err != nil {
+			return nil, err
+		}
+		task.Categories = before.Categories
+
+		if err := recordHistory(c, tx, model.EntityTask, task.ID, model.HistoryUpdate, before, task); err != nil {
+			return nil, err
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	if err := moveDescendants(c, tx, ids, &args.ID); err != nil {
+		return nil, err
+	}
+
+	return tasks, tx.Commit()
+}
+
+// checkProject returns the project a task goes into. A subtask is in the
+// project of its parent: without a project it inherits it, and a different
+// one is rejected. Moving a task into an archived project is rejected too,
+// a task that is already there stays.
+func checkProject(c context.Context, q querier, parentID, projectID, current *int) (*int, error) {
+	if parentID != nil {
+		var parentProject *int
+		query := `SELECT "project_id" FROM "tasks" WHERE "id" = $1`
+		err := q.QueryRowContext(c, query, *parentID).Scan(&parentProject)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: parent task does not exist", model.ErrInvalidParent)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if projectID == nil {
+			projectID = parentProject
+		} else if parentProject == nil || *parentProject != *projectID {
+			return nil, fmt.Errorf("%w: a subtask must be in the project of its parent task", model.ErrInvalidParent)
+		}
+	}
+
+	if projectID == nil || (current != nil && *current == *projectID) {
+		return projectID, nil
+	}
+
+	return projectID, lockProject(c, q, *projectID)
+}
+
+// lockProject keeps a project from being archived or deleted until the
+// transaction ends, rejecting one that is missing or archived
+func lockProject(c context.Context, q querier, id int) error {
+	var archived bool
+	query := `SELECT "archived_at" IS NOT NULL FROM "projects" WHERE "id" = $1 FOR SHARE`
+	err := q.QueryRowContext(c, query, id).Scan(&archived)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %d", ErrUnknownProject, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	if archived {
+		return fmt.Errorf("%w: \"id\" %d", model.ErrProjectArchived, id)
+	}
+	return nil
+}
+
+// moveDescendants puts every subtask of the tasks, in the trash or not,
+// into their project and records the change
+func moveDescendants(c context.Context, q querier, ids []int, projectID *int) error {
+	query := descendantsCTE + `, "moved" AS (
+			SELECT "id", "project_id" FROM "tasks"
+			WHERE "id" IN (SELECT "id" FROM "tree") AND "project_id" IS DISTINCT FROM $2
+			FOR UPDATE
+		)
+		UPDATE "tasks" SET "project_id" = $2, "updated_at" = now()
+		FROM "moved" WHERE "tasks"."id" = "moved"."id"
+		RETURNING "tasks"."id", "moved"."project_id"`
+
+	rows, err := q.QueryContext(c, query, pq.Array(ids), projectID)
+	if err != nil {
+		return err
+	}
+
+	type move struct {
+		id   int
+		from *int
+	}
+	var moves []move
+	for rows.Next() {
+		var m move
+		if err := rows.Scan(&m.id, &m.from); err != nil {
+			rows.Close()
+			return err
+		}
+		moves = append(moves, m)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	// the transaction's connection is busy until the rows are closed
+	rows.Close()
+
+	for _, m := range moves {
+		changes := model.Changes{}
+		if changes["project_id"], err = model.Change(m.from, projectID); err != nil {
+			return err
+		}
+		if err := recordChanges(c, q, model.EntityTask, m.id, model.HistoryUpdate, changes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
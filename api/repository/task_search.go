@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+// searchConfig is the text search configuration of the "search" column
+const searchConfig = "english"
+
+// escapedTitle is the title with its HTML special characters escaped, so that
+// the only markup of a snippet is the <mark> tags added by ts_headline
+const escapedTitle = `replace(replace(replace(replace(replace("title",
+	'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+
+// Search returns a page of the tasks matching a tsquery expression (see
+// model.ParseSearchQuery), best matches first, with the matching words of the
+// title highlighted
+func (r TaskRepository) Search(
+	ctx context.Context,
+	tsquery string,
+	args *types.Pageable,
+	filter model.TaskFilter,
+) (model.TaskSearchResults, error) {
+	conditions, params := taskConditions(filter)
+	params = append(params, tsquery)
+	match := fmt.Sprintf(`to_tsquery('%s', $%d)`, searchConfig, len(params))
+
+	// the rank is a double so that it survives the round trip through a cursor
+	from := fmt.Sprintf(
+		`(SELECT *,
+			ts_rank_cd("search", %s)::double precision AS "rank",
+			ts_headline('%s', %s, %s, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS "snippet"
+		FROM "tasks" WHERE "search" @@ %s) AS "tasks"`,
+		match, searchConfig, escapedTitle, match, match,
+	)
+
+	var results model.TaskSearchResults
+	err := r.store.run(ctx, func(tx *sql.Tx) error {
+		var err error
+		results, err = paginate(ctx, tx, listQuery{
+			table:      "tasks",
+			from:       from,
+			columns:    taskColumns + `, "rank", "snippet"`,
+			conditions: conditions,
+			params:     params,
+			sort:       model.TaskSearchSort,
+			schema:     model.TaskSearchFields,
+		}, args, func(row scanner, result *model.TaskSearchResult, key ...interface{}) error {
+			return scanTask(row, &result.Task, append([]interface{}{&result.Rank, &result.Snippet}, key...)...)
+		})
+		if err != nil {
+			return err
+		}
+
+		ids := make([]int, len(results))
+		for i, result := range results {
+			ids[i] = result.ID
+		}
+		categories, err := loadCategories(ctx, tx, ids)
+		if err != nil {
+			return err
+		}
+		for i := range results {
+			results[i].Categories = categories[results[i].ID]
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if results == nil {
+		results = model.TaskSearchResults{}
+	}
+	args.Calc()
+	return results, nil
+}
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+)
+
+// Delete moves a task to the trash, handling its subtasks according to policy.
+// With permanent set the task is removed for good, from the trash or not.
+func (r TaskRepository) Delete(
+	c context.Context, args model.TaskURLParams, policy model.CascadePolicy, permanent bool,
+) error {
+	tx, err := r.store.BeginTx(c, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if permanent {
+		err = deletePermanently(c, tx, args, policy)
+	} else {
+		err = trashTask(c, tx, args, policy)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// trashTask sets "deleted_at" on a task, and on its descendants with CascadeAll.
+// now() is fixed for the transaction, so everything trashed together shares
+// one timestamp and can be restored together.
+func trashTask(c context.Context, q querier, args model.TaskURLParams, policy model.CascadePolicy) error {
+	id := args.ID
+
+	task, err := lockTask(c, q, id)
+	if err != nil {
+		return err
+	}
+
+	if err := args.CheckVersion(task); err != nil {
+		return err
+	}
+
+	switch policy {
+	case model.CascadeAll:
+	case model.CascadeReparent:
+		if err := reparentChildren(c, q, id, false); err != nil {
+			return err
+		}
+	default:
+		if err := rejectChildren(c, q, id, false); err != nil {
+			return err
+		}
+	}
+
+	query := descendantsCTE + ` UPDATE "tasks" SET "deleted_at" = now()
+		WHERE "deleted_at" IS NULL AND ("id" = $2 OR ($3 AND "id" IN (SELECT "id" FROM "tree")))
+		RETURNING "id", "deleted_at"`
+	rows, err := q.QueryContext(c, query, pq.Array([]int{id}), id, policy == model.CascadeAll)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	trashed := map[int]time.Time{}
+	for rows.Next() {
+		var taskID int
+		var deletedAt time.Time
+		if err := rows.Scan(&taskID, &deletedAt); err != nil {
+			return err
+		}
+		trashed[taskID] = deletedAt
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for taskID, deletedAt := range trashed {
+		change, err := model.Change(nil, deletedAt)
+		if err != nil {
+			return err
+		}
+		changes := model.Changes{"deleted_at": change}
+		if err := recordChanges(c, q, model.EntityTask, taskID, model.HistoryTrash, changes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deletePermanently removes a task for good. Subtasks in the trash go with it,
+// as do all subtasks of a task that is in the trash itself: nothing outside of
+// the trash can live below a trashed task.
+func deletePermanently(c context.Context, q querier, args model.TaskURLParams, policy model.CascadePolicy) error {
+	id := args.ID
+
+	var task model.Task
+	query := `SELECT ` + taskColumns + ` FROM "tasks" WHERE "id" = $1 FOR UPDATE`
+	err := scanTask(q.QueryRowContext(c, query, id), &task)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: \"id\" %d", ErrTaskNotFound, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := args.CheckVersion(task); err != nil {
+		return err
+	}
+
+	everything := policy == model.CascadeAll || task.DeletedAt != nil
+	if !everything {
+		if policy == model.CascadeReparent {
+			err = reparentChildren(c, q, id, false)
+		} else {
+			err = rejectChildren(c, q, id, false)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	query = descendantsCTE + ` SELECT ` + taskColumns + ` FROM "tasks"
+		WHERE "id" = $2 OR ("id" IN (SELECT "id" FROM "tree") AND ($3 OR "deleted_at" IS NOT NULL))
+		FOR UPDATE`
+	rows, err := q.QueryContext(c, query, pq.Array([]int{id}), id, everything)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := collectTasks(rows)
+	if err != nil {
+		return err
+	}
+
+	return deleteTasks(c, q, deleted)
+}
+
+// deleteTasks removes tasks in one statement, so that parents and their
+// subtasks can go together, and records their last state in the history
+func deleteTasks(c context.Context, q querier, tasks model.Tasks) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	if err := withCategories(c, q, tasks); err != nil {
+		return err
+	}
+
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	if _, err := q.ExecContext(c, `DELETE FROM "tasks" WHERE "id" = ANY($1)`, pq.Array(ids)); err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if err := recordHistory(c, q, model.EntityTask, task.ID, model.HistoryDelete, task, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Restore takes a task out of the trash together with the subtasks that were
+// trashed along with it. A task whose parent is still in the trash cannot be restored.
+func (r TaskRepository) Restore(c context.Context, args model.TaskURLParams) (model.Task, error) {
+	var task model.Task
+
+	tx, err := r.store.BeginTx(c, nil)
+	if err != nil {
+		return task, err
+	}
+	defer tx.Rollback()
+
+	query := `SELECT ` + taskColumns + ` FROM "tasks" WHERE "id" = $1 AND "deleted_at" IS NOT NULL FOR UPDATE`
+	err = scanTask(tx.QueryRowContext(c, query, args.ID), &task)
+	if errors.Is(err, sql.ErrNoRows) {
+		return task, fmt.Errorf("%w: \"id\" %d is not in the trash", ErrTaskNotFound, args.ID)
+	}
+	if err != nil {
+		return task, err
+	}
+
+	if task.ParentID != nil {
+		var parentTrashed bool
+		query = `SELECT "deleted_at" IS NOT NULL FROM "tasks" WHERE "id" = $1`
+		if err := tx.QueryRowContext(c, query, *task.ParentID).Scan(&parentTrashed); err != nil {
+			return task, err
+		}
+		if parentTrashed {
+			return task, model.ErrParentTrashed
+		}
+	}
+
+	deletedAt := *task.DeletedAt
+	query = descendantsCTE + ` UPDATE "tasks" SET "deleted_at" = NULL
+		WHERE "deleted_at" = $3 AND ("id" = $2 OR "id" IN (SELECT "id" FROM "tree"))
+		RETURNING ` + taskColumns
+	rows, err := tx.QueryContext(c, query, pq.Array([]int{args.ID}), args.ID, deletedAt)
+	if err != nil {
+		return task, err
+	}
+
+	restored, err := collectTasks(rows)
+	if err != nil {
+		return task, err
+	}
+
+	change, err := model.Change(deletedAt, nil)
+	if err != nil {
+		return task, err
+	}
+	for _, t := range restored {
+		changes := model.Changes{"deleted_at": change}
+		if err := recordChanges(c, tx, model.EntityTask, t.ID, model.HistoryRestore, changes); err != nil {
+			return task, err
+		}
+		if t.ID == args.ID {
+			task = t
+		}
+	}
+
+	categories, err := loadCategories(c, tx, []int{task.ID})
+	if err != nil {
+		return task, err
+	}
+	task.Categories = categories[task.ID]
+
+	return task, tx.Commit()
+}
+
+// Purge permanently removes the tasks of the workspace of c that went to the
+// trash before a point in time and returns how many were removed. Subtasks are never trashed after their
+// parent, so a purged task takes its whole trashed subtree with it.
+func (r TaskRepository) Purge(c context.Context, before time.Time) (int, error) {
+	tx, err := r.store.BeginTx(c, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `SELECT ` + taskColumns + ` FROM "tasks" WHERE "deleted_at" < $1 FOR UPDATE`
+	rows, err := tx.QueryContext(c, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	purged, err := collectTasks(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := deleteTasks(c, tx, purged); err != nil {
+		return 0, err
+	}
+
+	return len(purged), tx.Commit()
+}
@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+// descendantsCTE selects into "tree" the ids of every descendant of the task ids in $1
+const descendantsCTE = `WITH RECURSIVE "tree" AS (
+	SELECT "id" FROM "tasks" WHERE "parent_id" = ANY($1)
+	UNION ALL
+	SELECT "t"."id" FROM "tasks" "t" JOIN "tree" ON "t"."parent_id" = "tree"."id"
+)`
+
+// finishedStatuses is passed to queries that only touch open subtasks
+var finishedStatuses = pq.Array([]string{string(model.StatusDone), string(model.StatusCancelled)})
+
+// Children returns the direct subtasks of a task
+func (r TaskRepository) Children(
+	ctx context.Context,
+	args model.TaskURLParams,
+	p *types.Pageable,
+) (model.Tasks, error) {
+	return r.List(ctx, p, model.TaskFilter{ParentID: &args.ID})
+}
+
+// Tree returns a page of root tasks with all of their subtasks nested below them
+func (r TaskRepository) Tree(
+	ctx context.Context,
+	p *types.Pageable,
+	filter model.TaskFilter,
+) (model.Tasks, error) {
+	filter.RootsOnly = true
+	filter.ParentID = nil
+
+	roots, err := r.List(ctx, p, filter)
+	if err != nil || len(roots) == 0 {
+		return roots, err
+	}
+
+	ids := make([]int, len(roots))
+	for i, root := range roots {
+		ids[i] = root.ID
+	}
+
+	var descendants model.Tasks
+	err = r.store.run(ctx, func(tx *sql.Tx) error {
+		var err error
+		if descendants, err = descendantsOf(ctx, tx, ids); err != nil {
+			return err
+		}
+		return withCategories(ctx, tx, descendants)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return roots.Nest(descendants), nil
+}
+
+func descendantsOf(ctx context.Context, q querier, ids []int) (model.Tasks, error) {
+	query := descendantsCTE + ` SELECT ` + taskColumns +
+		` FROM "tasks" WHERE "id" IN (SELECT "id" FROM "tree") AND "deleted_at" IS NULL ORDER BY "id"`
+
+	rows, err := q.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	return collectTasks(rows)
+}
+
+// checkParent rejects a parent that does not exist, is in the trash,
+// or is the task itself or one of its descendants
+func checkParent(c context.Context, q querier, id int, parentID *int) error {
+	if parentID == nil {
+		return nil
+	}
+
+	if *parentID == id {
+		return fmt.Errorf("%w: a task cannot be its own parent", model.ErrInvalidParent)
+	}
+
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM "tasks" WHERE "id" = $1 AND "deleted_at" IS NULL)`
+	if err := q.QueryRowContext(c, query, *parentID).Scan(&exists); err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("%w: parent task does not exist", model.ErrInvalidParent)
+	}
+
+	var cyclic bool
+	query = descendantsCTE + ` SELECT EXISTS (SELECT 1 FROM "tree" WHERE "id" = $2)`
+	if err := q.QueryRowContext(c, query, pq.Array([]int{id}), *parentID).Scan(&cyclic); err != nil {
+		return err
+	}
+
+	if cyclic {
+		return fmt.Errorf("%w: task %d is a subtask of task %d", model.ErrInvalidParent, *parentID, id)
+	}
+
+	return nil
+}
+
+// parentError translates a foreign key violation on "parent_id" into ErrInvalidParent
+func parentError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Constraint == "tasks_parent_id_fkey" {
+		return fmt.Errorf("%w: parent task does not exist", model.ErrInvalidParent)
+	}
+	return err
+}
+
+// rejectChildren fails with ErrTaskHasChildren when the task has subtasks
+// outside of the trash; with openOnly set, finished subtasks are ignored
+func rejectChildren(c context.Context, q querier, id int, openOnly bool) error {
+	var exists bool
+	query := `SELECT EXISTS (
+		SELECT 1 FROM "tasks" WHERE "parent_id" = $1 AND "deleted_at" IS NULL
+			AND (NOT $2 OR "status" <> ALL($3))
+	)`
+	if err := q.QueryRowContext(c, query, id, openOnly, finishedStatuses).Scan(&exists); err != nil {
+		return err
+	}
+
+	if exists {
+		return model.ErrTaskHasChildren
+	}
+
+	return nil
+}
+
+// reparentChildren moves the subtasks of a task up to the task's own parent;
+// with openOnly set, finished subtasks stay where they are. Subtasks in the
+// trash stay with the task.
+func reparentChildren(c context.Context, q querier, id int, openOnly bool) error {
+	query := `UPDATE "tasks" SET
+		"parent_id" = (SELECT "parent_id" FROM "tasks" WHERE "id" = $1),
+		"updated_at" = now()
+		WHERE "parent_id" = $1 AND "deleted_at" IS NULL AND (NOT $2 OR "status" <> ALL($3))
+		RETURNING "id", "parent_id"`
+	rows, err := q.QueryContext(c, query, id, openOnly, finishedStatuses)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	moved := map[int]*int{}
+	for rows.Next() {
+		var childID int
+		var parentID *int
+		if err := rows.Scan(&childID, &parentID); err != nil {
+			return err
+		}
+		moved[childID] = parentID
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for childID, parentID := range moved {
+		change, err := model.Change(id, parentID)
+		if err != nil {
+			return err
+		}
+		changes := model.Changes{"parent_id": change}
+		if err := recordChanges(c, q, model.EntityTask, childID, model.HistoryUpdate, changes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// finishChildren prepares the open subtasks of a task that moves to a finished status
+func finishChildren(
+	c context.Context, q querier, id int, to model.TaskStatus, policy model.CascadePolicy,
+) error {
+	switch policy {
+	case model.CascadeReparent:
+		return reparentChildren(c, q, id, true)
+	case model.CascadeAll:
+		return cascadeTransition(c, q, id, to)
+	default:
+		return rejectChildren(c, q, id, true)
+	}
+}
+
+// cascadeTransition moves every open descendant of a task to status "to",
+// failing when any of them cannot legally make that transition
+func cascadeTransition(c context.Context, q querier, id int, to model.TaskStatus) error {
+	query := descendantsCTE + ` SELECT "id", "status" FROM "tasks"
+		WHERE "id" IN (SELECT "id" FROM "tree") AND "deleted_at" IS NULL AND "status" <> ALL($2)
+		FOR UPDATE`
+
+	rows, err := q.QueryContext(c, query, pq.Array([]int{id}), finishedStatuses)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var ids []int
+	statuses := map[int]model.TaskStatus{}
+	for rows.Next() {
+		var childID int
+		var status model.TaskStatus
+		if err := rows.Scan(&childID, &status); err != nil {
+			return err
+		}
+
+		if err := status.Transition(to); err != nil {
+			return fmt.Errorf("%w (subtask %d)", err, childID)
+		}
+		ids = append(ids, childID)
+		statuses[childID] = status
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	// the transaction's connection is busy until the rows are closed
+	rows.Close()
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query = `UPDATE "tasks" SET
+		"status" = $1::varchar,
+		"completed_at" = CASE WHEN $1::varchar = 'done' THEN now() ELSE NULL END,
+		"updated_at" = now()
+		WHERE "id" = ANY($2) RETURNING ` + taskColumns
+	rows, err = q.QueryContext(c, query, to, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+
+	finished, err := collectTasks(rows)
+	if err != nil {
+		return err
+	}
+
+	for _, task := range finished {
+		changes := model.Changes{}
+		if changes["status"], err = model.Change(statuses[task.ID], to); err != nil {
+			return err
+		}
+		if err := recordChanges(c, q, model.EntityTask, task.ID, model.HistoryUpdate, changes); err != nil {
+			return err
+		}
+	}
+
+	if to != model.StatusDone {
+		return nil
+	}
+
+	for _, task := range finished {
+		if err := materializeNext(c, q, task); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+	"github.com/Kbgjtn/notethingness-api.git/types"
+	"github.com/Kbgjtn/notethingness-api.git/util"
+)
+
+// userColumns is the column list every user query selects, in scanUser order
+const userColumns = `"id", "email", "name", "created_at", "updated_at"`
+
+var (
+	ErrUserNotFound        = errors.New("error: user not found")
+	ErrUserExists          = errors.New("error: a user with this email already exists")
+	ErrInvalidRefreshToken = errors.New("error: refresh token is invalid, expired or already used")
+)
+
+// dummyPasswordHash is checked when no user has the email of a login, so that
+// the response time does not tell which emails are registered
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := util.HashPassword("dummy password")
+	return hash
+})
+
+// UserRepository keeps the user accounts and their refresh tokens
+type UserRepository struct {
+	store *sql.DB
+	// refreshTTL is how long a refresh token can be used
+	refreshTTL time.Duration
+}
+
+func NewUserRepo(store *sql.DB, refreshTTL time.Duration) *UserRepository {
+	return &UserRepository{store, refreshTTL}
+}
+
+func scanUser(row scanner, user *model.User, extra ...interface{}) error {
+	dest := []interface{}{&user.ID, &user.Email, &user.Name, &user.CreatedAt, &user.UpdatedAt}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// Create registers a user together with their personal workspace,
+// ErrUserExists when the email is taken
+func (r UserRepository) Create(c context.Context, payload model.RegisterPayload) (model.User, error) {
+	var user model.User
+
+	hash, err := util.HashPassword(payload.Password)
+	if err != nil {
+		return user, err
+	}
+
+	tx, err := r.store.BeginTx(c, nil)
+	if err != nil {
+		return user, err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO "users" ("email", "name", "password_hash") VALUES ($1, $2, $3) RETURNING ` + userColumns
+	err = scanUser(tx.QueryRowContext(c, query, payload.Email, payload.Name, hash), &user)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Constraint == "users_email_key" {
+		return user, fmt.Errorf("%w: %q", ErrUserExists, payload.Email)
+	}
+	if err != nil {
+		return user, err
+	}
+
+	if _, err := createWorkspace(c, tx, user.ID, model.PersonalWorkspace); err != nil {
+		return user, err
+	}
+
+	return user, tx.Commit()
+}
+
+// Get returns a user, ErrUserNotFound when it does not exist
+func (r UserRepository) Get(c context.Context, id int) (model.User, error) {
+	var user model.User
+
+	query := `SELECT ` + userColumns + ` FROM "users" WHERE "id" = $1`
+	err := scanUser(r.store.QueryRowContext(c, query, id), &user)
+	if errors.Is(err, sql.ErrNoRows) {
+		return user, fmt.Errorf("%w: \"id\" %d", ErrUserNotFound, id)
+	}
+
+	return user, err
+}
+
+// Authenticate returns the user with the email and password of a login,
+// model.ErrInvalidCredentials when there is none
+func (r UserRepository) Authenticate(c context.Context, payload model.LoginPayload) (model.User, error) {
+	var user model.User
+	var hash string
+
+	query := `SELECT ` + userColumns + `, "password_hash" FROM "users" WHERE "email" = $1`
+	err := scanUser(r.store.QueryRowContext(c, query, payload.Email), &user, &hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		util.CheckPassword(dummyPasswordHash(), payload.Password)
+		return model.User{}, model.ErrInvalidCredentials
+	}
+	if err != nil {
+		return user, err
+	}
+
+	ok, err := util.CheckPassword(hash, payload.Password)
+	if err != nil {
+		return model.User{}, err
+	}
+	if !ok {
+		return model.User{}, model.ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+// IssueRefreshToken starts a new family of refresh tokens for a user, on login
+func (r UserRepository) IssueRefreshToken(c context.Context, userID int) (string, error) {
+	family, _, err := util.NewRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	return issueRefreshToken(c, r.store, userID, family, r.refreshTTL)
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one of the same family
+// and returns its user. A token can only be used once: presenting a used token
+// again means it was stolen, and revokes its whole family.
+func (r UserRepository) RotateRefreshToken(c context.Context, token string) (model.User, string, error) {
+	var user model.User
+
+	tx, err := r.store.BeginTx(c, nil)
+	if err != nil {
+		return user, "", err
+	}
+	defer tx.Rollback()
+
+	var id, userID int
+	var family string
+	var expired, revoked bool
+
+	query := `SELECT "id", "user_id", "family", "expires_at" < now(), "revoked_at" IS NOT NULL
+		FROM "refresh_tokens" WHERE "token_hash" = $1 FOR UPDATE`
+	err = tx.QueryRowContext(c, query, util.HashToken(token)).Scan(&id, &userID, &family, &expired, &revoked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return user, "", ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return user, "", err
+	}
+
+	if revoked {
+		if err := revokeFamily(c, tx, family); err != nil {
+			return user, "", err
+		}
+		if err := tx.Commit(); err != nil {
+			return user, "", err
+		}
+		return user, "", ErrInvalidRefreshToken
+	}
+
+	if expired {
+		return user, "", ErrInvalidRefreshToken
+	}
+
+	query = `UPDATE "refresh_tokens" SET "revoked_at" = now() WHERE "id" = $1`
+	if _, err := tx.ExecContext(c, query, id); err != nil {
+		return user, "", err
+	}
+
+	next, err := issueRefreshToken(c, tx, userID, family, r.refreshTTL)
+	if err != nil {
+		return user, "", err
+	}
+
+	query = `SELECT ` + userColumns + ` FROM "users" WHERE "id" = $1`
+	if err := scanUser(tx.QueryRowContext(c, query, userID), &user); err != nil {
+		return user, "", err
+	}
+
+	return user, next, tx.Commit()
+}
+
+// RevokeRefreshToken revokes the family of a refresh token, on logout.
+// Unknown tokens are ignored.
+func (r UserRepository) RevokeRefreshToken(c context.Context, token string) error {
+	var family string
+
+	query := `SELECT "family" FROM "refresh_tokens" WHERE "token_hash" = $1`
+	err := r.store.QueryRowContext(c, query, util.HashToken(token)).Scan(&family)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return revokeFamily(c, r.store, family)
+}
+
+func issueRefreshToken(c context.Context, q querier, userID int, family string, ttl time.Duration) (string, error) {
+	token, hash, err := util.NewRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	query := `INSERT INTO "refresh_tokens" ("user_id", "family", "token_hash", "expires_at")
+		VALUES ($1, $2, $3, now() + make_interval(secs => $4))`
+	if _, err := q.ExecContext(c, query, userID, family, hash, ttl.Seconds()); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func revokeFamily(c context.Context, q querier, family string) error {
+	query := `UPDATE "refresh_tokens" SET "revoked_at" = now() WHERE "family" = $1 AND "revoked_at" IS NULL`
+	_, err := q.ExecContext(c, query, family)
+	return err
+}
+
+// ownerID returns the authenticated user of a request, who owns what it creates
+func ownerID(c context.Context) *int {
+	principal, ok := types.PrincipalFrom(c)
+	if !ok {
+		return nil
+	}
+	return &principal.UserID
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
+)
+
+var (
+	ErrWorkspaceNotFound = errors.New("error: workspace not found")
+	ErrMemberNotFound    = errors.New("error: member not found")
+	ErrMemberExists      = errors.New("error: user is already a member of the workspace")
+)
+
+// WorkspaceRepository keeps the workspaces and who is a member of them. The
+// workspaces themselves are not subject to row-level security: they are
+// looked up to pick the workspace of a request, before there is one.
+type WorkspaceRepository struct {
+	store *sql.DB
+}
+
+func NewWorkspaceRepo(store *sql.DB) *WorkspaceRepository {
+	return &WorkspaceRepository{store}
+}
+
+// List returns the workspaces of a user with their role in each, oldest first
+func (r WorkspaceRepository) List(c context.Context, userID int) (model.Workspaces, error) {
+	query := `SELECT "w"."id", "w"."name", "m"."role", "w"."created_at", "w"."updated_at"
+		FROM "workspaces" "w" JOIN "workspace_members" "m" ON "m"."workspace_id" = "w"."id"
+		WHERE "m"."user_id" = $1 ORDER BY "w"."id"`
+	rows, err := r.store.QueryContext(c, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	workspaces := model.Workspaces{}
+	for rows.Next() {
+		var w model.Workspace
+		if err := rows.Scan(&w.ID, &w.Name, &w.Role, &w.CreatedAt, &w.UpdatedAt); err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, w)
+	}
+
+	return workspaces, rows.Err()
+}
+
+// Create creates a workspace owned by a user
+func (r WorkspaceRepository) Create(
+	c context.Context, userID int, payload model.WorkspaceRequestPayload,
+) (model.Workspace, error) {
+	tx, err := r.store.BeginTx(c, nil)
+	if err != nil {
+		return model.Workspace{}, err
+	}
+	defer tx.Rollback()
+
+	workspace, err := createWorkspace(c, tx, userID, payload.Name)
+	if err != nil {
+		return workspace, err
+	}
+
+	return workspace, tx.Commit()
+}
+
+// createWorkspace inserts a workspace with userID as its owner
+func createWorkspace(c context.Context, q querier, userID int, name string) (model.Workspace, error) {
+	workspace := model.Workspace{Role: model.RoleOwner}
+
+	query := `INSERT INTO "workspaces" ("name") VALUES ($1) RETURNING "id", "name", "created_at", "updated_at"`
+	err := q.QueryRowContext(c, query, name).Scan(
+		&workspace.ID, &workspace.Name, &workspace.CreatedAt, &workspace.UpdatedAt,
+	)
+	if err != nil {
+		return workspace, err
+	}
+
+	query = `INSERT INTO "workspace_members" ("workspace_id", "user_id", "role") VALUES ($1, $2, $3)`
+	_, err = q.ExecContext(c, query, workspace.ID, userID, model.RoleOwner)
+	return workspace, err
+}
+
+// Role returns the role of a user in a workspace, ErrWorkspaceNotFound when
+// the user is not a member of it
+func (r WorkspaceRepository) Role(c context.Context, workspaceID, userID int) (string, error) {
+	var role string
+
+	query := `SELECT "role" FROM "workspace_members" WHERE "workspace_id" = $1 AND "user_id" = $2`
+	err := r.store.QueryRowContext(c, query, workspaceID, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return role, fmt.Errorf("%w: \"id\" %d", ErrWorkspaceNotFound, workspaceID)
+	}
+
+	return role, err
+}
+
+// Default returns the workspace access tokens of a user are issued for, the
+// first one they joined; 0 when they are a member of none
+func (r WorkspaceRepository) Default(c context.Context, userID int) (int, error) {
+	var id int
+
+	query := `SELECT "workspace_id" FROM "workspace_members" WHERE "user_id" = $1
+		ORDER BY "created_at", "workspace_id" LIMIT 1`
+	err := r.store.QueryRowContext(c, query, userID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	return id, err
+}
+
+// IDs returns the id of every workspace, for the jobs that go through all of them
+func (r WorkspaceRepository) IDs(c context.Context) ([]int, error) {
+	rows, err := r.store.QueryContext(c, `SELECT "id" FROM "workspaces" ORDER BY "id"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// Members returns the members of a workspace, oldest first
+func (r WorkspaceRepository) Members(c context.Context, workspaceID int) (model.Members, error) {
+	query := `SELECT "u"."id", "u"."email", "u"."name", "m"."role", "m"."created_at"
+		FROM "workspace_members" "m" JOIN "users" "u" ON "u"."id" = "m"."user_id"
+		WHERE "m"."workspace_id" = $1 ORDER BY "m"."created_at", "u"."id"`
+	rows, err := r.store.QueryContext(c, query, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := model.Members{}
+	for rows.Next() {
+		var m model.Member
+		if err := rows.Scan(&m.UserID, &m.Email, &m.Name, &m.Role, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+// AddMember adds a registered user to a workspace, ErrUserNotFound when no
+// user has the email and ErrMemberExists when they are a member already
+func (r WorkspaceRepository) AddMember(
+	c context.Context, workspaceID int, payload model.MemberPayload,
+) (model.Member, error) {
+	member := model.Member{Email: payload.Email, Role: payload.Role}
+
+	query := `INSERT INTO "workspace_members" ("workspace_id", "user_id", "role")
+		SELECT $1, "id", $3 FROM "users" WHERE "email" = $2
+		RETURNING "user_id", (SELECT "name" FROM "users" WHERE "email" = $2), "created_at"`
+	err := r.store.QueryRowContext(c, query, workspaceID, payload.Email, payload.Role).Scan(
+		&member.UserID, &member.Name, &member.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return member, fmt.Errorf("%w: %q", ErrUserNotFound, payload.Email)
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Constraint == "workspace_members_pkey" {
+		return member, fmt.Errorf("%w: %q", ErrMemberExists, payload.Email)
+	}
+
+	return member, err
+}
+
+// RemoveMember takes a user out of a workspace, along with their API keys for
+// it. Owners cannot be removed, model.ErrOwnerRemoval.
+func (r WorkspaceRepository) RemoveMember(c context.Context, workspaceID, userID int) error {
+	tx, err := r.store.BeginTx(c, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var role string
+	query := `SELECT "role" FROM "workspace_members" WHERE "workspace_id" = $1 AND "user_id" = $2 FOR UPDATE`
+	err = tx.QueryRowContext(c, query, workspaceID, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: \"user_id\" %d", ErrMemberNotFound, userID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if role == model.RoleOwner {
+		return model.ErrOwnerRemoval
+	}
+
+	query = `DELETE FROM "workspace_members" WHERE "workspace_id" = $1 AND "user_id" = $2`
+	if _, err := tx.ExecContext(c, query, workspaceID, userID); err != nil {
+		return err
+	}
+
+	query = `UPDATE "api_keys" SET "revoked_at" = now()
+		WHERE "workspace_id" = $1 AND "user_id" = $2 AND "revoked_at" IS NULL`
+	if _, err := tx.ExecContext(c, query, workspaceID, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -8,6 +8,7 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger"
 
 	"github.com/Kbgjtn/notethingness-api.git/api/handler"
+	"github.com/Kbgjtn/notethingness-api.git/api/model"
 	"github.com/Kbgjtn/notethingness-api.git/api/repository"
 	_ "github.com/Kbgjtn/notethingness-api.git/docs"
 )
@@ -22,16 +23,72 @@ func (s *Server) Router() {
 	router.Get("/swagger/*", httpSwagger.WrapHandler)
 	router.Get("/swagger", redirectToSwg)
 
+	workspaces := repository.NewWorkspaceRepo(s.db)
+	auth := handler.NewAuth(repository.NewUserRepo(s.db, s.refreshTTL), workspaces, s.accessTTL)
+	keys := repository.NewAPIKeyRepo(s.db)
+
 	api := chi.NewRouter()
-	api.Route("/", s.InitRoutes)
+	api.Route("/auth", auth.Routes)
+	// calendar apps cannot send an Authorization header, the feed also takes an API key in its URL
+	api.Group(func(router chi.Router) {
+		router.Use(handler.FeedToken)
+		router.Use(handler.Authenticate(keys))
+		router.Use(handler.Audit)
+		router.Use(handler.Workspace(workspaces))
+		router.With(handler.RequireScope(model.ScopeTasksRead, model.ScopeTasksWrite)).Get("/tasks.ics", s.taskHandler().ICalendar)
+	})
+	api.Group(func(router chi.Router) {
+		router.Use(handler.Authenticate(keys))
+		router.Use(handler.Audit)
+		router.Get("/users/me", auth.Me)
+		router.Route("/workspaces", handler.NewWorkspace(workspaces).Routes)
+
+		// everything below sees the data of one workspace only
+		router.Group(func(router chi.Router) {
+			router.Use(handler.Workspace(workspaces))
+			// a created key is shown once in plaintext, so its response must never be saved for replay
+			router.Route("/keys", handler.NewAPIKey(keys).Routes)
+			router.Group(func(router chi.Router) {
+				router.Use(handler.Idempotency(repository.NewIdempotencyRepo(repository.NewStore(s.db), s.idempotencyTTL)))
+				s.InitRoutes(router)
+			})
+		})
+	})
 
 	router.Mount("/api", api)
 	s.router = router
 }
 
+func (s *Server) taskHandler() *handler.TasksResource {
+	store := repository.NewStore(s.db)
+	return handler.NewTask(
+		repository.NewTaskRepo(store),
+		repository.NewAttachmentRepo(store, s.blobs),
+		handler.TaskOptions{BatchLimit: s.batchLimit, RequireIfMatch: s.requireIfMatch},
+	)
+}
+
 func (s *Server) InitRoutes(router chi.Router) {
-	router.Route("/tasks", func(route chi.Router) {
-		handler.NewTask(repository.NewTaskRepo(s.db)).Routes(route)
+	store := repository.NewStore(s.db)
+	tasks := s.taskHandler()
+
+	// API keys need the scope of each route: the read one for GET, the other one otherwise
+	taskScope := handler.RequireScope(model.ScopeTasksRead, model.ScopeTasksWrite)
+	categoryScope := handler.RequireScope(model.ScopeCategoriesRead, model.ScopeCategoriesAdmin)
+	projectScope := handler.RequireScope(model.ScopeProjectsRead, model.ScopeProjectsWrite)
+
+	router.With(taskScope).Route("/tasks", tasks.Routes)
+	router.With(taskScope).Get("/trash", tasks.Trash)
+	// imports create the categories their tasks are tagged with
+	router.With(taskScope, categoryScope).Route("/import", tasks.ImportRoutes)
+	router.With(categoryScope).Route("/categories", func(route chi.Router) {
+		handler.NewCategory(repository.NewCategoryRepo(store)).Routes(route)
+		route.With(taskScope).Get("/{id}/tasks", tasks.ListByCategory)
+	})
+	router.With(projectScope).Route("/projects", func(route chi.Router) {
+		handler.NewProject(repository.NewProjectRepo(store)).Routes(route)
+		route.With(taskScope).Get("/{id}/tasks", tasks.ListByProject)
+		route.With(taskScope).Post("/{id}/tasks", tasks.MoveToProject)
 	})
 
 	router.Get("/openapi", func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,3 @@
+drop table if exists "task_attachments";
+drop function if exists "queue_blob_garbage";
+drop table if exists "blob_garbage";
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS "task_attachments" (
+  "id" bigserial PRIMARY KEY,
+  "task_id" bigint NOT NULL,
+  "filename" varchar NOT NULL,
+  "content_type" varchar NOT NULL,
+  "size" bigint NOT NULL,
+  "sha256" char(64) NOT NULL,
+  "blob_key" varchar UNIQUE NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "task_attachments" ("task_id", "id");
+
+ALTER TABLE "task_attachments" ADD FOREIGN KEY ("task_id") REFERENCES "tasks" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
+
+CREATE TABLE IF NOT EXISTS "blob_garbage" (
+  "blob_key" varchar PRIMARY KEY,
+  "created_at" timestamp NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE "blob_garbage" IS 'Blobs whose attachment row is gone, removed from the blob store by the garbage collector';
+
+-- however an attachment row goes away (directly or through a cascading task delete),
+-- its blob is queued for removal
+CREATE OR REPLACE FUNCTION "queue_blob_garbage"() RETURNS trigger AS $$
+BEGIN
+  INSERT INTO "blob_garbage" ("blob_key") VALUES (OLD."blob_key") ON CONFLICT DO NOTHING;
+  RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "task_attachments_blob_garbage" AFTER DELETE ON "task_attachments"
+  FOR EACH ROW EXECUTE FUNCTION "queue_blob_garbage"();
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS "task_history" (
+  "id" bigserial PRIMARY KEY,
+  "entity" varchar NOT NULL,
+  "entity_id" bigint NOT NULL,
+  "action" varchar NOT NULL,
+  "changes" jsonb NOT NULL,
+  "actor" varchar,
+  "request_id" varchar,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  CHECK ("entity" IN ('task', 'category')),
+  CHECK ("action" IN ('create', 'update', 'delete'))
+);
+
+CREATE INDEX ON "task_history" ("entity", "entity_id", "id");
+
+COMMENT ON TABLE "task_history" IS 'Field-level changes of tasks and categories, kept after they are deleted';
@@ -0,0 +1,10 @@
+DELETE FROM "task_history" WHERE "action" IN ('trash', 'restore');
+
+ALTER TABLE "task_history" DROP CONSTRAINT IF EXISTS "task_history_action_check";
+
+ALTER TABLE "task_history" ADD CONSTRAINT "task_history_action_check"
+  CHECK ("action" IN ('create', 'update', 'delete'));
+
+DROP INDEX IF EXISTS "tasks_deleted_at_idx";
+
+ALTER TABLE "tasks" DROP COLUMN IF EXISTS "deleted_at";
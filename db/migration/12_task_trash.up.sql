@@ -0,0 +1,10 @@
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "deleted_at" timestamp;
+
+CREATE INDEX IF NOT EXISTS "tasks_deleted_at_idx" ON "tasks" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+COMMENT ON COLUMN "tasks"."deleted_at" IS 'Set while the task is in the trash, tasks trashed together share the same value';
+
+ALTER TABLE "task_history" DROP CONSTRAINT IF EXISTS "task_history_action_check";
+
+ALTER TABLE "task_history" ADD CONSTRAINT "task_history_action_check"
+  CHECK ("action" IN ('create', 'update', 'delete', 'trash', 'restore'));
@@ -0,0 +1,3 @@
+drop trigger if exists "tasks_version" on "tasks";
+drop function if exists "bump_task_version";
+alter table "tasks" drop column if exists "version";
@@ -0,0 +1,16 @@
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "version" integer NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN "tasks"."version" IS 'Bumped on every update, the ETag of the task';
+
+-- every write to a task, whichever statement makes it, gives it a new version
+CREATE OR REPLACE FUNCTION "bump_task_version"() RETURNS trigger AS $$
+BEGIN
+  NEW."version" := OLD."version" + 1;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS "tasks_version" ON "tasks";
+
+CREATE TRIGGER "tasks_version" BEFORE UPDATE ON "tasks"
+  FOR EACH ROW EXECUTE FUNCTION "bump_task_version"();
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS "idempotency_keys" (
+  "actor" varchar NOT NULL DEFAULT '',
+  "key" varchar(255) NOT NULL,
+  "fingerprint" varchar(64) NOT NULL,
+  "status" integer,
+  "headers" jsonb,
+  "body" bytea,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "expires_at" timestamp NOT NULL,
+  PRIMARY KEY ("actor", "key")
+);
+
+CREATE INDEX IF NOT EXISTS "idempotency_keys_expires_at_idx" ON "idempotency_keys" ("expires_at");
+
+COMMENT ON TABLE "idempotency_keys" IS 'Responses of POST requests sent with an Idempotency-Key, replayed when the request is retried';
+
+COMMENT ON COLUMN "idempotency_keys"."status" IS 'NULL while the first request is still being handled';
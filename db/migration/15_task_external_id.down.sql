@@ -0,0 +1,2 @@
+drop index if exists "tasks_external_id_key";
+alter table "tasks" drop column if exists "external_id";
@@ -0,0 +1,5 @@
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "external_id" varchar(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "tasks_external_id_key" ON "tasks" ("external_id");
+
+COMMENT ON COLUMN "tasks"."external_id" IS 'Identifier of the task in the tool it was imported from, e.g. an iCalendar UID';
@@ -0,0 +1,3 @@
+drop index if exists "tasks_project_id_idx";
+alter table "tasks" drop column if exists "project_id";
+drop table if exists "projects";
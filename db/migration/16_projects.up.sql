@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS "projects" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar(255) NOT NULL,
+  "description" varchar NOT NULL DEFAULT '',
+  "archived_at" timestamp,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "updated_at" timestamp NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "project_id" bigint
+  REFERENCES "projects" ("id") ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS "tasks_project_id_idx" ON "tasks" ("project_id");
+
+COMMENT ON COLUMN "projects"."archived_at" IS 'Set while the project is archived, no task can be added to it then';
+
+COMMENT ON COLUMN "tasks"."project_id" IS 'Project of the task, shared by its subtasks; NULL for tasks outside of any project';
@@ -0,0 +1,5 @@
+alter table "projects" drop column if exists "owner_id";
+alter table "categories" drop column if exists "owner_id";
+alter table "tasks" drop column if exists "owner_id";
+drop table if exists "refresh_tokens";
+drop table if exists "users";
@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS "users" (
+  "id" bigserial PRIMARY KEY,
+  "email" varchar(255) UNIQUE NOT NULL,
+  "name" varchar(255) NOT NULL DEFAULT '',
+  "password_hash" varchar(255) NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "updated_at" timestamp NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE IF NOT EXISTS "refresh_tokens" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "family" varchar(64) NOT NULL,
+  "token_hash" varchar(64) UNIQUE NOT NULL,
+  "expires_at" timestamp NOT NULL,
+  "revoked_at" timestamp,
+  "created_at" timestamp NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX IF NOT EXISTS "refresh_tokens_family_idx" ON "refresh_tokens" ("family");
+
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "owner_id" bigint
+  REFERENCES "users" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "categories" ADD COLUMN IF NOT EXISTS "owner_id" bigint
+  REFERENCES "users" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "projects" ADD COLUMN IF NOT EXISTS "owner_id" bigint
+  REFERENCES "users" ("id") ON DELETE SET NULL;
+
+COMMENT ON COLUMN "users"."email" IS 'Stored lowercase, the login of the user';
+
+COMMENT ON COLUMN "refresh_tokens"."family" IS 'Shared by the tokens rotated from one login, all revoked when a used token is presented again';
+
+COMMENT ON COLUMN "refresh_tokens"."token_hash" IS 'SHA-256 of the token, the token itself is only known to the client';
+
+COMMENT ON COLUMN "tasks"."owner_id" IS 'User who created the task, NULL for tasks created before accounts existed';
+
+COMMENT ON COLUMN "categories"."owner_id" IS 'User who created the category, NULL for categories created before accounts existed';
+
+COMMENT ON COLUMN "projects"."owner_id" IS 'User who created the project, NULL for projects created before accounts existed';
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS "api_keys" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "name" varchar(255) NOT NULL,
+  "prefix" varchar(16) NOT NULL,
+  "key_hash" varchar(64) UNIQUE NOT NULL,
+  "scopes" varchar[] NOT NULL,
+  "expires_at" timestamp,
+  "last_used_at" timestamp,
+  "revoked_at" timestamp,
+  "created_at" timestamp NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX IF NOT EXISTS "api_keys_user_id_idx" ON "api_keys" ("user_id");
+
+COMMENT ON TABLE "api_keys" IS 'Keys for scripts and bots, they act for their user within their scopes';
+
+COMMENT ON COLUMN "api_keys"."prefix" IS 'Start of the key, shown to tell keys apart';
+
+COMMENT ON COLUMN "api_keys"."key_hash" IS 'SHA-256 of the key, the key itself is only shown once when it is created';
+
+COMMENT ON COLUMN "api_keys"."last_used_at" IS 'Updated at most once a minute';
@@ -0,0 +1,68 @@
+drop policy if exists "idempotency_keys_workspace" on "idempotency_keys";
+alter table "idempotency_keys" no force row level security;
+alter table "idempotency_keys" disable row level security;
+drop policy if exists "task_history_workspace" on "task_history";
+alter table "task_history" no force row level security;
+alter table "task_history" disable row level security;
+drop policy if exists "task_attachments_workspace" on "task_attachments";
+alter table "task_attachments" no force row level security;
+alter table "task_attachments" disable row level security;
+drop policy if exists "task_comments_workspace" on "task_comments";
+alter table "task_comments" no force row level security;
+alter table "task_comments" disable row level security;
+drop policy if exists "task_dependencies_workspace" on "task_dependencies";
+alter table "task_dependencies" no force row level security;
+alter table "task_dependencies" disable row level security;
+drop policy if exists "task_categories_workspace" on "task_categories";
+alter table "task_categories" no force row level security;
+alter table "task_categories" disable row level security;
+drop policy if exists "projects_workspace" on "projects";
+alter table "projects" no force row level security;
+alter table "projects" disable row level security;
+drop policy if exists "categories_workspace" on "categories";
+alter table "categories" no force row level security;
+alter table "categories" disable row level security;
+drop policy if exists "tasks_workspace" on "tasks";
+alter table "tasks" no force row level security;
+alter table "tasks" disable row level security;
+revoke all on all tables in schema public from "notethingness_tenant";
+revoke all on all sequences in schema public from "notethingness_tenant";
+-- roles belong to the whole cluster: other databases may still grant to this one
+alter table "task_attachments" drop constraint if exists "task_attachments_task_id_fkey";
+alter table "task_attachments" add constraint "task_attachments_task_id_fkey" foreign key ("task_id") references "tasks" ("id") on delete cascade;
+alter table "task_comments" drop constraint if exists "task_comments_task_id_fkey";
+alter table "task_comments" add constraint "task_comments_task_id_fkey" foreign key ("task_id") references "tasks" ("id") on delete cascade;
+alter table "task_dependencies" drop constraint if exists "task_dependencies_depends_on_id_fkey";
+alter table "task_dependencies" add constraint "task_dependencies_depends_on_id_fkey" foreign key ("depends_on_id") references "tasks" ("id") on delete cascade;
+alter table "task_dependencies" drop constraint if exists "task_dependencies_task_id_fkey";
+alter table "task_dependencies" add constraint "task_dependencies_task_id_fkey" foreign key ("task_id") references "tasks" ("id") on delete cascade;
+alter table "task_categories" drop constraint if exists "task_categories_category_id_fkey";
+alter table "task_categories" add constraint "task_categories_category_id_fkey" foreign key ("category_id") references "categories" ("id") on delete cascade;
+alter table "task_categories" drop constraint if exists "task_categories_task_id_fkey";
+alter table "task_categories" add constraint "task_categories_task_id_fkey" foreign key ("task_id") references "tasks" ("id") on delete cascade;
+alter table "tasks" drop constraint if exists "tasks_project_id_fkey";
+alter table "tasks" add constraint "tasks_project_id_fkey" foreign key ("project_id") references "projects" ("id") on delete set null;
+alter table "tasks" drop constraint if exists "tasks_parent_id_fkey";
+alter table "tasks" add constraint "tasks_parent_id_fkey" foreign key ("parent_id") references "tasks" ("id") on delete no action;
+alter table "projects" drop constraint if exists "projects_id_workspace_id_key";
+alter table "categories" drop constraint if exists "categories_id_workspace_id_key";
+alter table "tasks" drop constraint if exists "tasks_id_workspace_id_key";
+drop index if exists "tasks_external_id_key";
+create unique index if not exists "tasks_external_id_key" on "tasks" ("external_id");
+alter table "categories" drop constraint if exists "categories_label_key";
+alter table "categories" add constraint "categories_label_key" unique ("label");
+alter table "idempotency_keys" drop constraint if exists "idempotency_keys_pkey";
+alter table "idempotency_keys" add primary key ("actor", "key");
+alter table "api_keys" drop column if exists "workspace_id";
+alter table "idempotency_keys" drop column if exists "workspace_id";
+alter table "task_history" drop column if exists "workspace_id";
+alter table "task_attachments" drop column if exists "workspace_id";
+alter table "task_comments" drop column if exists "workspace_id";
+alter table "task_dependencies" drop column if exists "workspace_id";
+alter table "task_categories" drop column if exists "workspace_id";
+alter table "projects" drop column if exists "workspace_id";
+alter table "categories" drop column if exists "workspace_id";
+alter table "tasks" drop column if exists "workspace_id";
+drop table if exists "workspace_members";
+drop table if exists "workspaces";
+drop function if exists "current_workspace_id"();
@@ -0,0 +1,262 @@
+-- the workspace of the current transaction, set with SET LOCAL app.workspace_id;
+-- NULL when none is set, which no row matches
+CREATE OR REPLACE FUNCTION "current_workspace_id"() RETURNS bigint AS $$
+  SELECT nullif(current_setting('app.workspace_id', true), '')::bigint;
+$$ LANGUAGE sql STABLE;
+
+CREATE TABLE IF NOT EXISTS "workspaces" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar(255) NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "updated_at" timestamp NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE IF NOT EXISTS "workspace_members" (
+  "workspace_id" bigint NOT NULL REFERENCES "workspaces" ("id") ON DELETE CASCADE,
+  "user_id" bigint NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "role" varchar NOT NULL DEFAULT 'member',
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("workspace_id", "user_id"),
+  CHECK ("role" IN ('owner', 'member'))
+);
+
+CREATE INDEX IF NOT EXISTS "workspace_members_user_id_idx" ON "workspace_members" ("user_id");
+
+-- everything created before workspaces existed goes to the first one, shared
+-- by every user as it was until now
+INSERT INTO "workspaces" ("id", "name") VALUES (1, 'Default');
+
+SELECT setval(pg_get_serial_sequence('workspaces', 'id'), 1);
+
+INSERT INTO "workspace_members" ("workspace_id", "user_id", "role")
+  SELECT 1, "id", 'owner' FROM "users";
+
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "workspace_id" bigint NOT NULL DEFAULT 1
+  REFERENCES "workspaces" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "tasks" ALTER COLUMN "workspace_id" SET DEFAULT "current_workspace_id"();
+
+ALTER TABLE "categories" ADD COLUMN IF NOT EXISTS "workspace_id" bigint NOT NULL DEFAULT 1
+  REFERENCES "workspaces" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "categories" ALTER COLUMN "workspace_id" SET DEFAULT "current_workspace_id"();
+
+ALTER TABLE "projects" ADD COLUMN IF NOT EXISTS "workspace_id" bigint NOT NULL DEFAULT 1
+  REFERENCES "workspaces" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "projects" ALTER COLUMN "workspace_id" SET DEFAULT "current_workspace_id"();
+
+ALTER TABLE "task_categories" ADD COLUMN IF NOT EXISTS "workspace_id" bigint NOT NULL DEFAULT 1
+  REFERENCES "workspaces" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "task_categories" ALTER COLUMN "workspace_id" SET DEFAULT "current_workspace_id"();
+
+ALTER TABLE "task_dependencies" ADD COLUMN IF NOT EXISTS "workspace_id" bigint NOT NULL DEFAULT 1
+  REFERENCES "workspaces" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "task_dependencies" ALTER COLUMN "workspace_id" SET DEFAULT "current_workspace_id"();
+
+ALTER TABLE "task_comments" ADD COLUMN IF NOT EXISTS "workspace_id" bigint NOT NULL DEFAULT 1
+  REFERENCES "workspaces" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "task_comments" ALTER COLUMN "workspace_id" SET DEFAULT "current_workspace_id"();
+
+ALTER TABLE "task_attachments" ADD COLUMN IF NOT EXISTS "workspace_id" bigint NOT NULL DEFAULT 1
+  REFERENCES "workspaces" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "task_attachments" ALTER COLUMN "workspace_id" SET DEFAULT "current_workspace_id"();
+
+ALTER TABLE "task_history" ADD COLUMN IF NOT EXISTS "workspace_id" bigint NOT NULL DEFAULT 1
+  REFERENCES "workspaces" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "task_history" ALTER COLUMN "workspace_id" SET DEFAULT "current_workspace_id"();
+
+ALTER TABLE "idempotency_keys" ADD COLUMN IF NOT EXISTS "workspace_id" bigint NOT NULL DEFAULT 1
+  REFERENCES "workspaces" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "idempotency_keys" ALTER COLUMN "workspace_id" SET DEFAULT "current_workspace_id"();
+
+ALTER TABLE "api_keys" ADD COLUMN IF NOT EXISTS "workspace_id" bigint NOT NULL DEFAULT 1
+  REFERENCES "workspaces" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "api_keys" ALTER COLUMN "workspace_id" DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS "tasks_workspace_id_idx" ON "tasks" ("workspace_id");
+
+CREATE INDEX IF NOT EXISTS "categories_workspace_id_idx" ON "categories" ("workspace_id");
+
+CREATE INDEX IF NOT EXISTS "projects_workspace_id_idx" ON "projects" ("workspace_id");
+
+CREATE INDEX IF NOT EXISTS "task_history_workspace_id_idx" ON "task_history" ("workspace_id");
+
+CREATE INDEX IF NOT EXISTS "task_comments_workspace_id_idx" ON "task_comments" ("workspace_id");
+
+CREATE INDEX IF NOT EXISTS "task_attachments_workspace_id_idx" ON "task_attachments" ("workspace_id");
+
+ALTER TABLE "idempotency_keys" DROP CONSTRAINT IF EXISTS "idempotency_keys_pkey";
+
+ALTER TABLE "idempotency_keys" ADD PRIMARY KEY ("workspace_id", "actor", "key");
+
+-- labels and external ids only have to be unique within a workspace
+ALTER TABLE "categories" DROP CONSTRAINT IF EXISTS "categories_label_key";
+
+ALTER TABLE "categories" ADD CONSTRAINT "categories_label_key" UNIQUE ("workspace_id", "label");
+
+DROP INDEX IF EXISTS "tasks_external_id_key";
+
+CREATE UNIQUE INDEX IF NOT EXISTS "tasks_external_id_key" ON "tasks" ("workspace_id", "external_id");
+
+-- references between rows include the workspace, so that no row can point to
+-- a row of another workspace: foreign keys are checked regardless of the policies
+ALTER TABLE "tasks" ADD CONSTRAINT "tasks_id_workspace_id_key" UNIQUE ("id", "workspace_id");
+
+ALTER TABLE "categories" ADD CONSTRAINT "categories_id_workspace_id_key" UNIQUE ("id", "workspace_id");
+
+ALTER TABLE "projects" ADD CONSTRAINT "projects_id_workspace_id_key" UNIQUE ("id", "workspace_id");
+
+ALTER TABLE "tasks" DROP CONSTRAINT IF EXISTS "tasks_parent_id_fkey";
+
+ALTER TABLE "tasks" ADD CONSTRAINT "tasks_parent_id_fkey" FOREIGN KEY ("parent_id", "workspace_id")
+  REFERENCES "tasks" ("id", "workspace_id") ON DELETE NO ACTION ON UPDATE NO ACTION;
+
+ALTER TABLE "tasks" DROP CONSTRAINT IF EXISTS "tasks_project_id_fkey";
+
+ALTER TABLE "tasks" ADD CONSTRAINT "tasks_project_id_fkey" FOREIGN KEY ("project_id", "workspace_id")
+  REFERENCES "projects" ("id", "workspace_id") ON DELETE SET NULL ("project_id") ON UPDATE NO ACTION;
+
+ALTER TABLE "task_categories" DROP CONSTRAINT IF EXISTS "task_categories_task_id_fkey";
+
+ALTER TABLE "task_categories" ADD CONSTRAINT "task_categories_task_id_fkey" FOREIGN KEY ("task_id", "workspace_id")
+  REFERENCES "tasks" ("id", "workspace_id") ON DELETE CASCADE ON UPDATE NO ACTION;
+
+ALTER TABLE "task_categories" DROP CONSTRAINT IF EXISTS "task_categories_category_id_fkey";
+
+ALTER TABLE "task_categories" ADD CONSTRAINT "task_categories_category_id_fkey" FOREIGN KEY ("category_id", "workspace_id")
+  REFERENCES "categories" ("id", "workspace_id") ON DELETE CASCADE ON UPDATE NO ACTION;
+
+ALTER TABLE "task_dependencies" DROP CONSTRAINT IF EXISTS "task_dependencies_task_id_fkey";
+
+ALTER TABLE "task_dependencies" ADD CONSTRAINT "task_dependencies_task_id_fkey" FOREIGN KEY ("task_id", "workspace_id")
+  REFERENCES "tasks" ("id", "workspace_id") ON DELETE CASCADE ON UPDATE NO ACTION;
+
+ALTER TABLE "task_dependencies" DROP CONSTRAINT IF EXISTS "task_dependencies_depends_on_id_fkey";
+
+ALTER TABLE "task_dependencies" ADD CONSTRAINT "task_dependencies_depends_on_id_fkey" FOREIGN KEY ("depends_on_id", "workspace_id")
+  REFERENCES "tasks" ("id", "workspace_id") ON DELETE CASCADE ON UPDATE NO ACTION;
+
+ALTER TABLE "task_comments" DROP CONSTRAINT IF EXISTS "task_comments_task_id_fkey";
+
+ALTER TABLE "task_comments" ADD CONSTRAINT "task_comments_task_id_fkey" FOREIGN KEY ("task_id", "workspace_id")
+  REFERENCES "tasks" ("id", "workspace_id") ON DELETE CASCADE ON UPDATE NO ACTION;
+
+ALTER TABLE "task_attachments" DROP CONSTRAINT IF EXISTS "task_attachments_task_id_fkey";
+
+ALTER TABLE "task_attachments" ADD CONSTRAINT "task_attachments_task_id_fkey" FOREIGN KEY ("task_id", "workspace_id")
+  REFERENCES "tasks" ("id", "workspace_id") ON DELETE CASCADE ON UPDATE NO ACTION;
+
+-- the role tenant transactions switch to: it is neither the owner of the
+-- tables nor a superuser, so the policies apply to it whatever role the server
+-- connects with
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'notethingness_tenant') THEN
+    CREATE ROLE "notethingness_tenant" NOLOGIN;
+  END IF;
+END
+$$;
+
+GRANT "notethingness_tenant" TO current_user;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON
+  "tasks", "categories", "projects", "task_categories", "task_dependencies",
+  "task_comments", "task_attachments", "task_history", "idempotency_keys"
+  TO "notethingness_tenant";
+
+GRANT SELECT ON "authors" TO "notethingness_tenant";
+
+-- deleted attachments queue their blob from a trigger
+GRANT INSERT ON "blob_garbage" TO "notethingness_tenant";
+
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO "notethingness_tenant";
+
+-- Every table holding the data of a workspace is under row-level security
+-- below. These tables stay global, without policies, and are only read by
+-- the server's own role outside of tenant transactions:
+--   users, refresh_tokens: people sign in before any workspace is known, and
+--     a user belongs to several workspaces
+--   workspaces, workspace_members: they decide which workspaces a request may
+--     be scoped to, so they are read before it is scoped
+--   api_keys: a key is looked up by its hash before its workspace is known,
+--     and users list their keys across workspaces; its workspace_id binds the
+--     key to one workspace, which the server checks on every request
+--   blob_garbage: a queue of blob keys drained by a maintenance job for every
+--     workspace at once; it holds no data of theirs, and tenants may only add
+--     to it
+ALTER TABLE "tasks" ENABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "tasks" FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY "tasks_workspace" ON "tasks"
+  USING ("workspace_id" = "current_workspace_id"());
+
+ALTER TABLE "categories" ENABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "categories" FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY "categories_workspace" ON "categories"
+  USING ("workspace_id" = "current_workspace_id"());
+
+ALTER TABLE "projects" ENABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "projects" FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY "projects_workspace" ON "projects"
+  USING ("workspace_id" = "current_workspace_id"());
+
+ALTER TABLE "task_categories" ENABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "task_categories" FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY "task_categories_workspace" ON "task_categories"
+  USING ("workspace_id" = "current_workspace_id"());
+
+ALTER TABLE "task_dependencies" ENABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "task_dependencies" FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY "task_dependencies_workspace" ON "task_dependencies"
+  USING ("workspace_id" = "current_workspace_id"());
+
+ALTER TABLE "task_comments" ENABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "task_comments" FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY "task_comments_workspace" ON "task_comments"
+  USING ("workspace_id" = "current_workspace_id"());
+
+ALTER TABLE "task_attachments" ENABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "task_attachments" FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY "task_attachments_workspace" ON "task_attachments"
+  USING ("workspace_id" = "current_workspace_id"());
+
+ALTER TABLE "task_history" ENABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "task_history" FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY "task_history_workspace" ON "task_history"
+  USING ("workspace_id" = "current_workspace_id"());
+
+ALTER TABLE "idempotency_keys" ENABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "idempotency_keys" FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY "idempotency_keys_workspace" ON "idempotency_keys"
+  USING ("workspace_id" = "current_workspace_id"());
+
+COMMENT ON TABLE "workspaces" IS 'Tenants: the tasks, categories and projects of a workspace are only seen by its members';
+
+COMMENT ON COLUMN "workspace_members"."role" IS 'Owners manage the members of the workspace';
+
+COMMENT ON COLUMN "api_keys"."workspace_id" IS 'Workspace the key acts in, the one it was created in';
@@ -0,0 +1,4 @@
+revoke select ("id", "name") on "users" from "notethingness_tenant";
+delete from "task_comments" where "author_id" is null;
+alter table "task_comments" alter column "author_id" set not null;
+alter table "task_comments" drop column if exists "user_id";
@@ -0,0 +1,9 @@
+ALTER TABLE "task_comments" ADD COLUMN IF NOT EXISTS "user_id" bigint
+  REFERENCES "users" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "task_comments" ALTER COLUMN "author_id" DROP NOT NULL;
+
+-- comments are shown with the name of the user who posted them
+GRANT SELECT ("id", "name") ON "users" TO "notethingness_tenant";
+
+COMMENT ON COLUMN "task_comments"."user_id" IS 'The user who posted the comment, comments from before user accounts have an author_id instead';
@@ -0,0 +1,5 @@
+ALTER TABLE "tasks" DROP CONSTRAINT IF EXISTS "tasks_status_check";
+
+ALTER TABLE "tasks" DROP COLUMN IF EXISTS "completed_at";
+
+ALTER TABLE "tasks" DROP COLUMN IF EXISTS "status";
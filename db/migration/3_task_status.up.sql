@@ -0,0 +1,7 @@
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "status" varchar NOT NULL DEFAULT 'todo';
+
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "completed_at" timestamp;
+
+ALTER TABLE "tasks" ADD CONSTRAINT "tasks_status_check" CHECK ("status" IN ('todo', 'in_progress', 'blocked', 'done', 'cancelled'));
+
+CREATE INDEX ON "tasks" ("status");
@@ -0,0 +1,3 @@
+ALTER TABLE "tasks" DROP CONSTRAINT IF EXISTS "tasks_parent_id_fkey";
+
+ALTER TABLE "tasks" DROP COLUMN IF EXISTS "parent_id";
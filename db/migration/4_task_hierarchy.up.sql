@@ -0,0 +1,7 @@
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "parent_id" bigint;
+
+ALTER TABLE "tasks" ADD CONSTRAINT "tasks_parent_id_fkey" FOREIGN KEY ("parent_id") REFERENCES "tasks" ("id") ON DELETE NO ACTION ON UPDATE NO ACTION;
+
+CREATE INDEX ON "tasks" ("parent_id");
+
+COMMENT ON COLUMN "tasks"."parent_id" IS 'Subtasks point to their parent task, the cascade policy is applied by the API';
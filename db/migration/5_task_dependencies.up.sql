@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS "task_dependencies" (
+  "task_id" bigint NOT NULL,
+  "depends_on_id" bigint NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("task_id", "depends_on_id"),
+  CHECK ("task_id" <> "depends_on_id")
+);
+
+CREATE INDEX ON "task_dependencies" ("depends_on_id");
+
+COMMENT ON TABLE "task_dependencies" IS 'A task is blocked by every task it depends on';
+
+ALTER TABLE "task_dependencies" ADD FOREIGN KEY ("task_id") REFERENCES "tasks" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
+
+ALTER TABLE "task_dependencies" ADD FOREIGN KEY ("depends_on_id") REFERENCES "tasks" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
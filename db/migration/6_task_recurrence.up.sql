@@ -0,0 +1,7 @@
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "recurrence" varchar;
+
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "recurrence_start" timestamp;
+
+COMMENT ON COLUMN "tasks"."recurrence" IS 'RFC 5545 RRULE, the next occurrence is created when the task is done';
+
+COMMENT ON COLUMN "tasks"."recurrence_start" IS 'DTSTART of the series the task belongs to';
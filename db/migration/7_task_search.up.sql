@@ -0,0 +1,4 @@
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "search" tsvector
+  GENERATED ALWAYS AS (to_tsvector('english', coalesce("title", ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS "tasks_search_idx" ON "tasks" USING GIN ("search");
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS "task_categories" (
+  "task_id" bigint NOT NULL,
+  "category_id" bigint NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("task_id", "category_id")
+);
+
+CREATE INDEX ON "task_categories" ("category_id");
+
+COMMENT ON TABLE "task_categories" IS 'A task can be tagged with many categories';
+
+ALTER TABLE "task_categories" ADD FOREIGN KEY ("task_id") REFERENCES "tasks" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
+
+ALTER TABLE "task_categories" ADD FOREIGN KEY ("category_id") REFERENCES "categories" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS "task_comments" (
+  "id" bigserial PRIMARY KEY,
+  "task_id" bigint NOT NULL,
+  "author_id" bigint NOT NULL,
+  "body" text NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "edited_at" timestamp,
+  "deleted_at" timestamp
+);
+
+CREATE INDEX ON "task_comments" ("task_id", "id");
+
+COMMENT ON TABLE "task_comments" IS 'Deleted comments keep their row and are rendered as [deleted]';
+
+ALTER TABLE "task_comments" ADD FOREIGN KEY ("task_id") REFERENCES "tasks" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
+
+ALTER TABLE "task_comments" ADD FOREIGN KEY ("author_id") REFERENCES "authors" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
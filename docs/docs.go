@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/categories": {
-            "get": {
-                "description": "Get List of categories",
+        "/auth/login": {
+            "post": {
+                "description": "Get an access token, sent as Authorization: Bearer \u003ctoken\u003e to the rest of the API, and a refresh token to get the next one.\nThe access token is for the first workspace the user joined, requests can pick another one with the X-Workspace-ID header.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,25 +25,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "category"
+                    "auth"
                 ],
-                "summary": "Get list",
+                "summary": "Login",
                 "parameters": [
                     {
-                        "type": "string",
-                        "default": "0",
-                        "example": "1",
-                        "description": "string default example",
-                        "name": "offset",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "default": "10",
-                        "example": "20",
-                        "description": "string default example",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "default",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.LoginPayload"
+                        }
                     }
                 ],
                 "responses": {
@@ -58,10 +51,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/model.Category"
-                                            }
+                                            "$ref": "#/definitions/model.TokenPair"
                                         }
                                     }
                                 }
@@ -69,15 +59,23 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request: error message",
+                        "description": "Bad Request: email or password is missing",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: email or password is incorrect",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/logout": {
             "post": {
-                "description": "Create a new category",
+                "description": "Revoke a refresh token and the ones rotated from the same login. Access tokens stay valid until they expire.",
                 "consumes": [
                     "application/json"
                 ],
@@ -85,9 +83,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "category"
+                    "auth"
                 ],
-                "summary": "Create a new category",
+                "summary": "Logout",
                 "parameters": [
                     {
                         "description": "default",
@@ -95,41 +93,29 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.CategoryRequestPayload"
+                            "$ref": "#/definitions/model.RefreshPayload"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "Success",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/types.JSONResult"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/model.Category"
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "string"
                         }
                     },
                     "400": {
-                        "description": "Bad Request: label is invalid or missing",
+                        "description": "Bad Request: refresh_token is missing",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/types.JSONError"
                         }
                     }
                 }
             }
         },
-        "/categories/{id}": {
-            "get": {
-                "description": "Get a category by id",
+        "/auth/refresh": {
+            "post": {
+                "description": "Get a new access token and a new refresh token. A refresh token can be used once; using it again logs out every session of the login it came from.",
                 "consumes": [
                     "application/json"
                 ],
@@ -137,35 +123,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "category"
+                    "auth"
                 ],
-                "summary": "Get By ID",
+                "summary": "Refresh tokens",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Quote ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "default",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RefreshPayload"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Category"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.TokenPair"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Bad Request: id is invalid or missing",
+                        "description": "Bad Request: refresh_token is missing",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: refresh token is invalid, expired or already used",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update a category",
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Create a user account with a personal workspace and log it in. Passwords are stored as bcrypt hashes.",
                 "consumes": [
                     "application/json"
                 ],
@@ -173,30 +181,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "category"
+                    "auth"
                 ],
-                "summary": "Update a category",
+                "summary": "Register",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Category ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "description": "default",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.CategoryRequestPayload"
+                            "$ref": "#/definitions/model.RegisterPayload"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -206,7 +207,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Category"
+                                            "$ref": "#/definitions/model.TokenPair"
                                         }
                                     }
                                 }
@@ -214,53 +215,28 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request: id is invalid or missing",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            },
-            "delete": {
-                "description": "Delete a category",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "category"
-                ],
-                "summary": "Delete a category",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Category ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Success",
+                        "description": "Bad Request: email, password or name is invalid",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/types.JSONError"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request: id is invalid or missing",
+                    "409": {
+                        "description": "Conflict: a user with this email already exists",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/types.JSONError"
                         }
                     }
                 }
             }
         },
-        "/quotes": {
+        "/categories": {
             "get": {
-                "description": "Get List quotes",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get List of categories",
                 "consumes": [
                     "application/json"
                 ],
@@ -268,8 +244,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "quote"
+                    "category"
                 ],
+                "summary": "Get list",
                 "parameters": [
                     {
                         "type": "string",
@@ -286,6 +263,39 @@ const docTemplate = `{
                         "description": "string default example",
                         "name": "limit",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from next_cursor of a previous page",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from prev_cursor of a previous page",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "exact",
+                        "description": "how to compute paginate.total: exact, estimate or none",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "label~\"in\"",
+                        "description": "filter expression over id, label and owner_id",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-label",
+                        "description": "comma separated fields, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -302,14 +312,8 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/model.Quote"
+                                                "$ref": "#/definitions/model.Category"
                                             }
-                                        },
-                                        "length": {
-                                            "type": "integer"
-                                        },
-                                        "paginate": {
-                                            "$ref": "#/definitions/types.Pageable"
                                         }
                                     }
                                 }
@@ -317,15 +321,20 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "error: offset or limit is invalid",
+                        "description": "Bad Request: invalid filter or sort",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/types.JSONError"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a quote",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new category",
                 "consumes": [
                     "application/json"
                 ],
@@ -333,9 +342,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "quote"
+                    "category"
                 ],
-                "summary": "Create a quote",
+                "summary": "Create a new category",
                 "parameters": [
                     {
                         "description": "default",
@@ -343,7 +352,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.QuoteRequestPayload"
+                            "$ref": "#/definitions/model.CategoryRequestPayload"
                         }
                     }
                 ],
@@ -359,7 +368,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Quote"
+                                            "$ref": "#/definitions/model.Category"
                                         }
                                     }
                                 }
@@ -367,17 +376,28 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request: Invalid payload",
+                        "description": "Bad Request: label is invalid or missing",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict: label already exists",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
                         }
                     }
                 }
             }
         },
-        "/quotes/{id}": {
+        "/categories/{id}": {
             "get": {
-                "description": "Get a quote by id",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a category by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -385,13 +405,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "quote"
+                    "category"
                 ],
-                "summary": "Get a quote",
+                "summary": "Get By ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Quote ID",
+                        "description": "Category ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -409,7 +429,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Quote"
+                                            "$ref": "#/definitions/model.Category"
                                         }
                                     }
                                 }
@@ -417,21 +437,26 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "error: id is invalid",
+                        "description": "Bad Request: id is invalid or missing",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/types.JSONError"
                         }
                     },
                     "404": {
-                        "description": "error: quote not found",
+                        "description": "Not Found: category not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/types.JSONError"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Create a quote",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a category",
                 "consumes": [
                     "application/json"
                 ],
@@ -439,17 +464,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "quote"
+                    "category"
                 ],
-                "summary": "Create a quote",
+                "summary": "Update a category",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "description": "default",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.QuoteRequestPayload"
+                            "$ref": "#/definitions/model.CategoryRequestPayload"
                         }
                     }
                 ],
@@ -465,7 +497,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Quote"
+                                            "$ref": "#/definitions/model.Category"
                                         }
                                     }
                                 }
@@ -473,15 +505,32 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request: Invalid payload",
+                        "description": "Bad Request: id is invalid or missing",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found: category not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict: label already exists",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Delete a quote by id",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a category, untagging every task tagged with it",
                 "consumes": [
                     "application/json"
                 ],
@@ -489,13 +538,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "quote"
+                    "category"
                 ],
-                "summary": "Delete a quote",
+                "summary": "Delete a category",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Quote ID",
+                        "description": "Category ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -503,93 +552,5189 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "ok",
+                        "description": "Success",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "400": {
-                        "description": "error: id is invalid",
+                        "description": "Bad Request: id is invalid or missing",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/types.JSONError"
                         }
                     },
                     "404": {
-                        "description": "error: quote not found",
+                        "description": "Not Found: category not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the fields named in a JSON Merge Patch (application/merge-patch+json) or a JSON Patch (application/json-patch+json). The patched category is validated before it is saved.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "category"
+                ],
+                "summary": "Patch a category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "fields to change, or a list of JSON Patch operations",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CategoryRequestPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Category"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request: id or patch is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found: category not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict: label already exists or the patch does not apply",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type: not a patch document",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity: patched category is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the changes of a category, newest first, with who made them and in which request",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "category"
+                ],
+                "summary": "Category history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "0",
+                        "example": "1",
+                        "description": "string default example",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "10",
+                        "example": "20",
+                        "description": "string default example",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from next_cursor of a previous page",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from prev_cursor of a previous page",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "exact",
+                        "description": "how to compute paginate.total: exact, estimate or none",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.HistoryEntry"
+                                            }
+                                        },
+                                        "length": {
+                                            "type": "integer"
+                                        },
+                                        "paginate": {
+                                            "$ref": "#/definitions/types.Pageable"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request: id is invalid or missing",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/{id}/tasks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the tasks tagged with a category",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "category"
+                ],
+                "summary": "List tasks of a category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "0",
+                        "example": "1",
+                        "description": "string default example",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "10",
+                        "example": "20",
+                        "description": "string default example",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from next_cursor of a previous page",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from prev_cursor of a previous page",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "exact",
+                        "description": "how to compute paginate.total: exact, estimate or none",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "open",
+                        "description": "comma separated statuses, or open for unfinished tasks",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "priority\u003e=2",
+                        "description": "filter expression, e.g. priority\u003e=2",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-priority,date",
+                        "description": "comma separated fields, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Task"
+                                            }
+                                        },
+                                        "length": {
+                                            "type": "integer"
+                                        },
+                                        "paginate": {
+                                            "$ref": "#/definitions/types.Pageable"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: id is invalid",
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "404": {
+                        "description": "error: category not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/import/{format}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Read the export of another tool, sent as the \"file\" field of a multipart/form-data body or as the whole body, and upsert its tasks. Formats: ics (iCalendar VTODO and VEVENT), todoist (JSON backup), trello (board JSON export) and todotxt (todo.txt). Tasks are matched to the tasks they were imported as before and created when new; ics tasks exported by /tasks.ics update their task. Labels, projects and lists become categories, created when no category has that label. Items that cannot be mapped are skipped and listed with the reason.",
+                "consumes": [
+                    "multipart/form-data",
+                    "text/calendar",
+                    "application/json",
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Import tasks from another tool",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ics, todoist, trello or todotxt",
+                        "name": "format",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "the file",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "report what the import would do without saving anything",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.ImportReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: unknown format",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "error: file is too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "error: Unsupported Content-Type",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/keys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the API keys of the user that are not revoked, in every workspace. Keys are never shown again after they are created.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "key"
+                ],
+                "summary": "List API keys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.APIKey"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: API keys cannot manage API keys",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mint an API key acting for the user within its scopes in the workspace of the request, sent as Authorization: Bearer \u003ckey\u003e.\nThe key is only shown in this response. Scopes: tasks:read, tasks:write, categories:read, categories:admin, projects:read, projects:write; write and admin scopes include reading.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "key"
+                ],
+                "summary": "Create an API key",
+                "parameters": [
+                    {
+                        "description": "default",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.APIKeyRequestPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.CreatedAPIKey"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request: name, scopes or expires_at is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: API keys cannot manage API keys",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke an API key of the user, it is rejected from then on",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "key"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request: id is invalid or missing",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: API keys cannot manage API keys",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found: API key not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get List of projects, the archived ones only with archived=true",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "summary": "Get list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "0",
+                        "example": "1",
+                        "description": "string default example",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "10",
+                        "example": "20",
+                        "description": "string default example",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from next_cursor of a previous page",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from prev_cursor of a previous page",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "exact",
+                        "description": "how to compute paginate.total: exact, estimate or none",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "list the archived projects too",
+                        "name": "archived",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "name~\"web\"",
+                        "description": "filter expression over id, name, owner_id and dates",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-created_at",
+                        "description": "comma separated fields, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Project"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request: invalid filter or sort",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new project",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "summary": "Create a new project",
+                "parameters": [
+                    {
+                        "description": "default",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ProjectRequestPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Project"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request: name is invalid or missing",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a project by id, archived or not",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "summary": "Get By ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Project"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request: id is invalid or missing",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found: project not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update the name and description of a project",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "summary": "Update a project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "default",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ProjectRequestPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Project"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request: id or name is invalid or missing",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found: project not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a project, its tasks are kept outside of any project",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "summary": "Delete a project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request: id is invalid or missing",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found: project not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{id}/archive": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Archive a project: it is hidden from the list and takes no new tasks, its tasks are kept",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "summary": "Archive a project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Project"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request: id is invalid or missing",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found: project not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{id}/tasks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the tasks of a project, archived or not",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "summary": "List tasks of a project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "0",
+                        "example": "1",
+                        "description": "string default example",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "10",
+                        "example": "20",
+                        "description": "string default example",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from next_cursor of a previous page",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from prev_cursor of a previous page",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "exact",
+                        "description": "how to compute paginate.total: exact, estimate or none",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "open",
+                        "description": "comma separated statuses, or open for unfinished tasks",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "1,2",
+                        "description": "comma separated category ids, keeps tasks tagged with any of them",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "priority\u003e=2",
+                        "description": "filter expression, e.g. priority\u003e=2",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-priority,date",
+                        "description": "comma separated fields, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Task"
+                                            }
+                                        },
+                                        "length": {
+                                            "type": "integer"
+                                        },
+                                        "paginate": {
+                                            "$ref": "#/definitions/types.Pageable"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: id is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: project not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move tasks into a project along with their subtasks, in one transaction.\nA moved subtask whose parent stays in another project is detached from it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "summary": "Move tasks into a project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "tasks to move",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MoveTasksPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Task"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: id, task_ids or project is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: task not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "error: project is archived",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{id}/unarchive": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Bring an archived project back",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "summary": "Unarchive a project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Project"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request: id is invalid or missing",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found: project not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/quotes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get List quotes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quote"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "0",
+                        "example": "1",
+                        "description": "string default example",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "10",
+                        "example": "20",
+                        "description": "string default example",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from next_cursor of a previous page",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from prev_cursor of a previous page",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "exact",
+                        "description": "how to compute paginate.total: exact, estimate or none",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "open",
+                        "description": "comma separated statuses, or open for unfinished tasks",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "1,2",
+                        "description": "comma separated category ids, keeps tasks tagged with any of them",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "1",
+                        "description": "project id, keeps the tasks of the project",
+                        "name": "project",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "return root tasks with their subtasks nested under children",
+                        "name": "tree",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "priority\u003e=2 and date\u003c2024-04-01",
+                        "description": "filter expression, e.g. priority\u003e=2 and date\u003c2024-04-01; operators = != \u003e \u003e= \u003c \u003c= ~, combined with and, or, not and parentheses",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-priority,date",
+                        "description": "comma separated fields, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Task"
+                                            }
+                                        },
+                                        "length": {
+                                            "type": "integer"
+                                        },
+                                        "paginate": {
+                                            "$ref": "#/definitions/types.Pageable"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid filter or sort",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a quote",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quote"
+                ],
+                "summary": "Create a quote",
+                "parameters": [
+                    {
+                        "description": "default",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TaskRequestPayload"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "unique key of the request, a retry with the same key replays the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Task"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request: Invalid payload",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/quotes/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a quote by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quote"
+                ],
+                "summary": "Get a quote",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous read, answered with 304 while the task is unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Task"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "304": {
+                        "description": "not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "error: id is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: quote not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a quote",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quote"
+                ],
+                "summary": "Create a quote",
+                "parameters": [
+                    {
+                        "description": "default",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TaskRequestPayload"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the task, the update fails with 412 when it is stale",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Task"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request: Invalid payload",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "412": {
+                        "description": "the task has changed, data is its current version",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Task"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "428": {
+                        "description": "error: the If-Match header is required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move a task to the trash, or delete it for good with permanent=true",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quote"
+                ],
+                "summary": "Delete a quote",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "reject",
+                        "description": "what to do with subtasks: reject, cascade or reparent",
+                        "name": "children",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "delete for good instead of moving to the trash, also for tasks in the trash",
+                        "name": "permanent",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the task, the delete fails with 412 when it is stale",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "error: id is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: quote not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "error: task has subtasks",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "412": {
+                        "description": "the task has changed, data is its current version",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Task"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "428": {
+                        "description": "error: the If-Match header is required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks.ics": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Render the tasks as an RFC 5545 calendar of VTODO components, to subscribe to from calendar apps. Takes the filters of the task list, without pagination. PRIORITY is 10 - priority, clamped to 1 (highest) to 9, and 0 for priorities below 1. Calendar apps that cannot send an Authorization header can subscribe with an API key in the token query parameter; give that key only the tasks:read scope, as URLs end up in logs.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Export tasks as iCalendar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "open",
+                        "description": "comma separated statuses, or open for unfinished tasks",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "1,2",
+                        "description": "comma separated category ids, keeps tasks tagged with any of them",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "1",
+                        "description": "project id, keeps the tasks of the project",
+                        "name": "project",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "priority\u003e=2 and date\u003c2024-04-01",
+                        "description": "filter expression, e.g. priority\u003e=2 and date\u003c2024-04-01",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "date",
+                        "description": "comma separated fields, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "API key with the tasks:read scope, instead of the Authorization header",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the calendar",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid filter or sort",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "401": {
+                        "description": "error: \\\"token\\\" must be an API key",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/batch": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Run create, update and delete operations in order in a single transaction. By default the batch is all-or-nothing: the first failing operation rolls back the others, which report 424. Updates and deletes send the ETag of their task as if_match, which is checked like an If-Match header and required when the server requires If-Match. With atomic=false every operation stands on its own and the response is 207 when some of them failed. Each result carries the status code the operation would have had as a request of its own.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Create, update and delete tasks in bulk",
+                "parameters": [
+                    {
+                        "description": "default",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.BatchPayload"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "unique key of the request, a retry with the same key replays the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "roll back the whole batch when an operation fails",
+                        "name": "atomic",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.BatchResult"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.BatchResult"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: payload is invalid or missing",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "error: too many operations in the batch",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stream the tasks matching the filters of the task list, without pagination. CSV has a header row and lists the labels of the categories separated by commas; JSON Lines has one task object per line. Both can be imported back with POST /tasks/import.",
+                "produces": [
+                    "text/csv",
+                    "application/jsonl"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Export tasks as CSV or JSON Lines",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "csv",
+                        "description": "csv or jsonl",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "open",
+                        "description": "comma separated statuses, or open for unfinished tasks",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "1,2",
+                        "description": "comma separated category ids, keeps tasks tagged with any of them",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "1",
+                        "description": "project id, keeps the tasks of the project",
+                        "name": "project",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "priority\u003e=2 and date\u003c2024-04-01",
+                        "description": "filter expression, e.g. priority\u003e=2 and date\u003c2024-04-01",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "date",
+                        "description": "comma separated fields, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid format, filter or sort",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upsert the rows of a CSV file with a header row, or the objects of a JSON Lines file, sent as the \"file\" field of a multipart/form-data body or as the whole body. Rows are read by column name: id (updates that task), external_id (matches the task imported with it, creates it otherwise), title, priority, date, status, completed_at, parent_id, parent_external_id, project_id (keeps the current project when empty), recurrence and categories (labels separated by commas, created when no category has them); other columns are ignored. title and date are required; dates are RFC 3339 timestamps or plain dates. Rows that do not validate are skipped and reported with their line and reason. New tasks are created in bulk.",
+                "consumes": [
+                    "multipart/form-data",
+                    "text/csv",
+                    "application/jsonl"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Import tasks from CSV or JSON Lines",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "the file",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "csv",
+                        "description": "csv or jsonl, by default that of the Content-Type, else csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "title=Task name,date=Due date",
+                        "description": "comma separated field=column pairs, for files whose columns are named differently",
+                        "name": "mapping",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "report what the import would do without saving anything",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.ImportReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid file or mapping",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "error: file is too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "error: Unsupported Content-Type",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/order": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get tasks in topological order: every task comes after the tasks it depends on. The open tasks blocking the listed ones are listed too, even when they do not match status. At most 1000 tasks are ordered.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Tasks in executable order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "open",
+                        "description": "comma separated statuses, or open for unfinished tasks",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Task"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: unknown status",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "error: dependency would create a cycle",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "error: too many tasks to order",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Full-text search over task titles, ranked by relevance with highlighted snippets.\nWords are combined with AND; use \"quotes\" for phrases, word* for prefixes, \"or\" between words and -word to exclude.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Search tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"call john\" email*",
+                        "description": "search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "open",
+                        "description": "comma separated statuses, or open for unfinished tasks",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "1",
+                        "description": "project id, keeps the tasks of the project",
+                        "name": "project",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "0",
+                        "example": "1",
+                        "description": "string default example",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "10",
+                        "example": "20",
+                        "description": "string default example",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from next_cursor of a previous page",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from prev_cursor of a previous page",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "exact",
+                        "description": "how to compute paginate.total: exact, estimate or none",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResultWithPaginate"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.TaskSearchResult"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: search query \\\"q\\\" is required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change only the fields named in the patch: title, priority, date, parent_id, recurrence or category_ids. Send a JSON Merge Patch (application/merge-patch+json) or a JSON Patch (application/json-patch+json); the patched task is validated before it is saved. Use the transitions endpoint to change the status.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Patch a task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "fields to change, or a list of JSON Patch operations",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TaskRequestPayload"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the task, the patch fails with 412 when it is stale",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Task"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: patch document is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: task not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "error: patch cannot be applied to the current resource",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "412": {
+                        "description": "the task has changed, data is its current version",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Task"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "415": {
+                        "description": "error: Content-Type must be application/merge-patch+json or application/json-patch+json",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "error: patched resource is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "428": {
+                        "description": "error: the If-Match header is required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/attachments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the metadata of the files attached to a task, oldest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "List attachments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "0",
+                        "example": "1",
+                        "description": "string default example",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "10",
+                        "example": "20",
+                        "description": "string default example",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from next_cursor of a previous page",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from prev_cursor of a previous page",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "exact",
+                        "description": "how to compute paginate.total: exact, estimate or none",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.TaskAttachment"
+                                            }
+                                        },
+                                        "length": {
+                                            "type": "integer"
+                                        },
+                                        "paginate": {
+                                            "$ref": "#/definitions/types.Pageable"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: id is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: task not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Attach a file (at most 25 MB) to a task, sent as multipart/form-data in the \"file\" field",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Upload an attachment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "the file to attach",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.TaskAttachment"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: payload is invalid or missing",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: task not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/attachments/{attachmentId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the metadata of a file attached to a task",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Get an attachment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.TaskAttachment"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: id is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: attachment not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a file attached to a task",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Delete an attachment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "error: id is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: attachment not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/attachments/{attachmentId}/content": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the content of a file attached to a task. Supports Range requests and If-None-Match with the sha256 ETag",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Download an attachment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "byte range, e.g. bytes=0-1023",
+                        "name": "Range",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "the requested range",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "error: id is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: attachment not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/children": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the direct subtasks of a task",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "List subtasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "0",
+                        "example": "1",
+                        "description": "string default example",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "10",
+                        "example": "20",
+                        "description": "string default example",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from next_cursor of a previous page",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from prev_cursor of a previous page",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "exact",
+                        "description": "how to compute paginate.total: exact, estimate or none",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Task"
+                                            }
+                                        },
+                                        "length": {
+                                            "type": "integer"
+                                        },
+                                        "paginate": {
+                                            "$ref": "#/definitions/types.Pageable"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: id is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/comments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the comments of a task, oldest first. Deleted comments keep their place with the body \"[deleted]\"",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "List comments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "0",
+                        "example": "1",
+                        "description": "string default example",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "10",
+                        "example": "20",
+                        "description": "string default example",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from next_cursor of a previous page",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from prev_cursor of a previous page",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "exact",
+                        "description": "how to compute paginate.total: exact, estimate or none",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.TaskComment"
+                                            }
+                                        },
+                                        "length": {
+                                            "type": "integer"
+                                        },
+                                        "paginate": {
+                                            "$ref": "#/definitions/types.Pageable"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: id is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: task not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Post a comment on a task as the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Add a comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "default",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TaskCommentPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.TaskComment"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: payload is invalid or missing",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: task not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/comments/{commentId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a comment of a task",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Get a comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "commentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.TaskComment"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: id is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: comment not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the body of a comment, recording when it was edited. Only its author or an owner of the workspace can edit it. Deleted comments cannot be edited",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Edit a comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "commentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "default",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TaskCommentUpdatePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.TaskComment"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: payload is invalid or missing",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "error: only the author of a comment or an owner of the workspace can change it",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: comment not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a comment; it stays in the thread with the body \"[deleted]\". Only its author or an owner of the workspace can delete it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Delete a comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "commentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "error: id is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "error: only the author of a comment or an owner of the workspace can change it",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: comment not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/dependencies": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the tasks a task is blocked by",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "List dependencies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Task"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: id is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mark a task as blocked by another task, rejecting edges that would create a cycle",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Add a dependency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "default",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TaskDependencyPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.TaskDependency"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: payload is invalid or missing",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: task not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "error: dependency would create a cycle",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/dependencies/{dependsOnId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stop a task from being blocked by another task",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Remove a dependency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the blocking task",
+                        "name": "dependsOnId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "error: id is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: dependency not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the field-level changes of a task, newest first, with who made them and in which request. The history of a deleted task stays available",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Task history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "0",
+                        "example": "1",
+                        "description": "string default example",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "10",
+                        "example": "20",
+                        "description": "string default example",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from next_cursor of a previous page",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from prev_cursor of a previous page",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "exact",
+                        "description": "how to compute paginate.total: exact, estimate or none",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.HistoryEntry"
+                                            }
+                                        },
+                                        "length": {
+                                            "type": "integer"
+                                        },
+                                        "paginate": {
+                                            "$ref": "#/definitions/types.Pageable"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: id is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/occurrences": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Expand the RRULE of a recurring task between from and to without creating tasks",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "List occurrences",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-03-01",
+                        "description": "start of the range (date or RFC 3339), defaults to now",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-06-01",
+                        "description": "end of the range (date or RFC 3339), defaults to one year after from",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: task is not recurring",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: task not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "error: the series has too many occurrences before the requested range",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Take a task out of the trash, with the subtasks that were deleted along with it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Restore a task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Task"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: id is invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: task not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "error: the parent task is in the trash, restore it first",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/transitions": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move a task through its lifecycle (todo, in_progress, blocked, done, cancelled)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Transition a task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "default",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TaskTransitionPayload"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "reject",
+                        "description": "what to do with open subtasks when finishing: reject, cascade or reparent",
+                        "name": "children",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Task"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: payload is invalid or missing",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error: task not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "error: invalid status transition",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the tasks in the trash, most recently deleted first. They are purged once the retention period has passed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "List the trash",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "0",
+                        "example": "1",
+                        "description": "string default example",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "10",
+                        "example": "20",
+                        "description": "string default example",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from next_cursor of a previous page",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from prev_cursor of a previous page",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "exact",
+                        "description": "how to compute paginate.total: exact, estimate or none",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "deleted_at\u003e=2024-03-01",
+                        "description": "filter expression, e.g. deleted_at\u003e=2024-03-01",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-deleted_at",
+                        "description": "comma separated fields, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Task"
+                                            }
+                                        },
+                                        "length": {
+                                            "type": "integer"
+                                        },
+                                        "paginate": {
+                                            "$ref": "#/definitions/types.Pageable"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid filter or sort",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the user the access token was issued to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Current user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: access token is missing, invalid or expired",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the workspaces the user is a member of, with their role in each. Requests pick one with the X-Workspace-ID header.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspace"
+                ],
+                "summary": "List workspaces",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Workspace"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: API keys cannot manage workspaces",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a workspace owned by the user. Its tasks, categories and projects are only seen by its members.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspace"
+                ],
+                "summary": "Create a workspace",
+                "parameters": [
+                    {
+                        "description": "default",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.WorkspaceRequestPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Workspace"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request: name is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: API keys cannot manage workspaces",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces/{id}/members": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the members of a workspace the user is a member of",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspace"
+                ],
+                "summary": "List members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Member"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request: id is invalid or missing",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found: workspace not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a registered user to a workspace, as a member unless role is owner. Only owners can add members.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspace"
+                ],
+                "summary": "Add a member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "default",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MemberPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Member"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request: id, email or role is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: only owners can manage members",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found: workspace or user not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict: the user is already a member",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces/{id}/members/{user_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a member from a workspace and revoke their API keys for it. Only owners can remove members, owners cannot be removed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspace"
+                ],
+                "summary": "Remove a member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request: id or user_id is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: only owners can manage members",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found: workspace or member not found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict: owners cannot be removed",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "model.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2025-03-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "CI"
+                },
+                "prefix": {
+                    "description": "Prefix is the start of the key, to tell keys apart",
+                    "type": "string",
+                    "example": "nt_Xk3vQ9"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "tasks:read"
+                    ]
+                },
+                "workspace_id": {
+                    "description": "WorkspaceID is the workspace the key acts in, the one it was created in",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "model.APIKeyRequestPayload": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2025-03-01T00:00:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "CI"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "tasks:read",
+                        "tasks:write"
+                    ]
+                }
+            }
+        },
+        "model.Author": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.BatchOp": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "BatchCreate",
+                "BatchUpdate",
+                "BatchDelete"
+            ]
+        },
+        "model.BatchOperation": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.CascadePolicy"
+                        }
+                    ],
+                    "example": "reject"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "if_match": {
+                    "type": "string",
+                    "example": "\"3\""
+                },
+                "op": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.BatchOp"
+                        }
+                    ],
+                    "example": "create"
+                },
+                "permanent": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "task": {
+                    "$ref": "#/definitions/model.TaskRequestPayload"
+                }
+            }
+        },
+        "model.BatchPayload": {
+            "type": "object",
+            "properties": {
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.BatchOperation"
+                    }
+                }
+            }
+        },
+        "model.BatchResult": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.Task"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "op": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.BatchOp"
+                        }
+                    ],
+                    "example": "create"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 201
+                }
+            }
+        },
+        "model.CascadePolicy": {
+            "type": "string",
+            "enum": [
+                "reject",
+                "cascade",
+                "reparent"
+            ],
+            "x-enum-varnames": [
+                "CascadeReject",
+                "CascadeAll",
+                "CascadeReparent"
+            ]
+        },
+        "model.Category": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "label": {
+                    "type": "string",
+                    "example": "My Category"
+                },
+                "owner_id": {
+                    "description": "OwnerID is only listed with the categories themselves, not with their tasks",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "model.CategoryRequestPayload": {
+            "type": "object",
+            "properties": {
+                "label": {
+                    "type": "string",
+                    "example": "My Category"
+                }
+            }
+        },
+        "model.Changes": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/model.FieldChange"
+            }
+        },
+        "model.CreatedAPIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2025-03-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "key": {
+                    "type": "string",
+                    "example": "nt_Xk3vQ9..."
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "CI"
+                },
+                "prefix": {
+                    "description": "Prefix is the start of the key, to tell keys apart",
+                    "type": "string",
+                    "example": "nt_Xk3vQ9"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "tasks:read"
+                    ]
+                },
+                "workspace_id": {
+                    "description": "WorkspaceID is the workspace the key acts in, the one it was created in",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "model.FieldChange": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "object"
+                },
+                "to": {
+                    "type": "object"
+                }
+            }
+        },
+        "model.HistoryAction": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete",
+                "trash",
+                "restore"
+            ],
+            "x-enum-varnames": [
+                "HistoryCreate",
+                "HistoryUpdate",
+                "HistoryDelete",
+                "HistoryTrash",
+                "HistoryRestore"
+            ]
+        },
+        "model.HistoryEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.HistoryAction"
+                        }
+                    ],
+                    "example": "update"
+                },
+                "actor": {
+                    "type": "string",
+                    "example": "jane@example.com"
+                },
+                "changes": {
+                    "$ref": "#/definitions/model.Changes"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "entity": {
+                    "type": "string",
+                    "example": "task"
+                },
+                "entity_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "host/abcdef-000001"
+                }
+            }
+        },
+        "model.ImportAction": {
+            "type": "string",
+            "enum": [
+                "created",
+                "updated",
+                "skipped"
+            ],
+            "x-enum-varnames": [
+                "ImportCreated",
+                "ImportUpdated",
+                "ImportSkipped"
+            ]
+        },
+        "model.ImportItem": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ImportAction"
+                        }
+                    ],
+                    "example": "created"
+                },
+                "external_id": {
+                    "type": "string",
+                    "example": "0b6c4f2e@example.com"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "line": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "error: SUMMARY is missing"
+                },
+                "task_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Call John"
+                }
+            }
+        },
+        "model.ImportReport": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "description": "Categories are the categories created for labels that did not exist yet",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Category"
+                    }
+                },
+                "created": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "dry_run": {
+                    "description": "DryRun reports what would have happened, nothing was saved",
+                    "type": "boolean",
+                    "example": false
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ImportItem"
+                    }
+                },
+                "skipped": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "updated": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "model.LoginPayload": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "jane@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "correct horse battery"
+                }
+            }
+        },
+        "model.Member": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "member"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "model.MemberPayload": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "member"
+                }
+            }
+        },
+        "model.MoveTasksPayload": {
+            "type": "object",
+            "properties": {
+                "task_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
+                }
+            }
+        },
+        "model.Project": {
+            "type": "object",
+            "properties": {
+                "archived_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Everything for the new website"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Website relaunch"
+                },
+                "owner_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                }
+            }
+        },
+        "model.ProjectRequestPayload": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Everything for the new website"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Website relaunch"
+                }
+            }
+        },
+        "model.RefreshPayload": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string",
+                    "example": "p2K1c0bXh3..."
+                }
+            }
+        },
+        "model.RegisterPayload": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "jane@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Jane"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "correct horse battery"
+                }
+            }
+        },
+        "model.Task": {
+            "type": "object",
+            "properties": {
+                "blocked": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Category"
+                    }
+                },
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Task"
                     }
+                },
+                "comment_count": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "completed_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "deleted_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "external_id": {
+                    "description": "ExternalID identifies an imported task in the tool it came from",
+                    "type": "string",
+                    "example": "0b6c4f2e@example.com"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "owner_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "project_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "recurrence": {
+                    "type": "string",
+                    "example": "FREQ=WEEKLY;BYDAY=MO"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.TaskStatus"
+                        }
+                    ],
+                    "example": "todo"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Call John"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
-        }
-    },
-    "definitions": {
-        "model.Category": {
+        },
+        "model.TaskAttachment": {
             "type": "object",
             "properties": {
+                "content_type": {
+                    "type": "string",
+                    "example": "image/png"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "filename": {
+                    "type": "string",
+                    "example": "screenshot.png"
+                },
                 "id": {
                     "type": "integer",
                     "example": 1
                 },
-                "label": {
+                "sha256": {
                     "type": "string",
-                    "example": "My Category"
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 52311
+                },
+                "task_id": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "model.CategoryRequestPayload": {
+        "model.TaskComment": {
             "type": "object",
             "properties": {
-                "label": {
+                "author": {
+                    "$ref": "#/definitions/model.Author"
+                },
+                "body": {
                     "type": "string",
-                    "example": "My Category"
+                    "example": "Called, waiting for an answer"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "deleted_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "edited_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "task_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "model.TaskCommentPayload": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "example": "Called, waiting for an answer"
                 }
             }
         },
-        "model.Quote": {
+        "model.TaskCommentUpdatePayload": {
             "type": "object",
             "properties": {
-                "author_id": {
+                "body": {
+                    "type": "string",
+                    "example": "Called twice, waiting for an answer"
+                }
+            }
+        },
+        "model.TaskDependency": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "depends_on_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "task_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "model.TaskDependencyPayload": {
+            "type": "object",
+            "properties": {
+                "depends_on_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "model.TaskRequestPayload": {
+            "type": "object",
+            "properties": {
+                "category_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1
+                    ]
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "priority": {
                     "type": "integer",
                     "example": 1
                 },
-                "category_id": {
+                "project_id": {
                     "type": "integer",
                     "example": 1
                 },
-                "content": {
+                "recurrence": {
                     "type": "string",
-                    "example": "I am a quote"
+                    "example": "FREQ=WEEKLY;BYDAY=MO"
+                },
+                "title": {
+                    "description": "ID       string    ` + "`" + `json:\"content\"     example:\"I am a quote\"` + "`" + `",
+                    "type": "string",
+                    "example": "Call John"
+                }
+            }
+        },
+        "model.TaskSearchResult": {
+            "type": "object",
+            "properties": {
+                "blocked": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Category"
+                    }
+                },
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Task"
+                    }
+                },
+                "comment_count": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "completed_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
                 },
                 "created_at": {
                     "type": "string",
-                    "example": "2021-01-01T00:00:00Z"
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "deleted_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "external_id": {
+                    "description": "ExternalID identifies an imported task in the tool it came from",
+                    "type": "string",
+                    "example": "0b6c4f2e@example.com"
                 },
                 "id": {
                     "type": "integer",
                     "example": 1
                 },
+                "owner_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "project_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "rank": {
+                    "type": "number",
+                    "example": 0.1
+                },
+                "recurrence": {
+                    "type": "string",
+                    "example": "FREQ=WEEKLY;BYDAY=MO"
+                },
+                "snippet": {
+                    "description": "Snippet is the title as HTML, escaped, with the matching words in \u003cmark\u003e tags",
+                    "type": "string",
+                    "example": "\u003cmark\u003eCall\u003c/mark\u003e John"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.TaskStatus"
+                        }
+                    ],
+                    "example": "todo"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Call John"
+                },
                 "updated_at": {
                     "type": "string",
-                    "example": "2021-01-01T00:00:00Z"
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "model.TaskStatus": {
+            "type": "string",
+            "enum": [
+                "todo",
+                "in_progress",
+                "blocked",
+                "done",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "StatusTodo",
+                "StatusInProgress",
+                "StatusBlocked",
+                "StatusDone",
+                "StatusCancelled"
+            ]
+        },
+        "model.TaskTransitionPayload": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.TaskStatus"
+                        }
+                    ],
+                    "example": "done"
+                }
+            }
+        },
+        "model.TokenPair": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIs..."
+                },
+                "expires_in": {
+                    "type": "integer",
+                    "example": 900
+                },
+                "refresh_token": {
+                    "type": "string",
+                    "example": "p2K1c0bXh3..."
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                },
+                "user": {
+                    "$ref": "#/definitions/model.User"
+                },
+                "workspace_id": {
+                    "description": "WorkspaceID is the workspace of the access token, 0 when the user has none",
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "model.QuoteRequestPayload": {
+        "model.User": {
             "type": "object",
             "properties": {
-                "author_id": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "jane@example.com"
+                },
+                "id": {
                     "type": "integer",
                     "example": 1
                 },
-                "category_id": {
+                "name": {
+                    "type": "string",
+                    "example": "Jane"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                }
+            }
+        },
+        "model.Workspace": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "id": {
                     "type": "integer",
                     "example": 1
                 },
-                "content": {
+                "name": {
+                    "type": "string",
+                    "example": "Acme"
+                },
+                "role": {
+                    "description": "Role is the role of the user in the workspace",
+                    "type": "string",
+                    "example": "owner"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                }
+            }
+        },
+        "model.WorkspaceRequestPayload": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Acme"
+                }
+            }
+        },
+        "types.JSONError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "pointer": {
                     "type": "string",
-                    "example": "I am a quote"
+                    "example": "'field_name'"
                 }
             }
         },
@@ -607,6 +5752,32 @@ const docTemplate = `{
                 }
             }
         },
+        "types.JSONResultWithPaginate": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {},
+                "length": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJzIjoiaWQiLCJ2IjpbMTBdfQ.c2lnbmF0dXJl"
+                },
+                "paginate": {
+                    "$ref": "#/definitions/types.Pageable"
+                },
+                "prev_cursor": {
+                    "type": "string",
+                    "example": "eyJzIjoiaWQiLCJ2IjpbMV19.c2lnbmF0dXJl"
+                }
+            }
+        },
         "types.Pageable": {
             "type": "object",
             "properties": {
@@ -633,6 +5804,14 @@ const docTemplate = `{
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Access token from /auth/login or API key from /keys, sent as \"Bearer \u003ctoken\u003e\". Requests go to the workspace of the token unless the X-Workspace-ID header picks another one.",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -4,12 +4,15 @@ go 1.21.5
 
 require (
 	github.com/go-chi/chi/v5 v5.0.11
+	github.com/golang-jwt/jwt/v5 v5.2.0
 	github.com/joho/godotenv v1.5.1
 	github.com/lib/pq v1.10.9
 	github.com/mattes/migrate v3.0.1+incompatible
 	github.com/stretchr/testify v1.8.4
 	github.com/swaggo/http-swagger v1.3.4
 	github.com/swaggo/swag v1.16.2
+	github.com/teambition/rrule-go v1.8.2
+	golang.org/x/crypto v0.17.0
 )
 
 require (
@@ -0,0 +1,197 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCalendar = errors.New("error: invalid iCalendar data")
+
+// Decode reads the top-level components of an iCalendar stream, usually a
+// single VCALENDAR. Folded lines are joined and property names upper-cased.
+func Decode(r io.Reader) ([]Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []Component
+	var stack []*Component
+	for i, line := range lines {
+		property, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %s", ErrInvalidCalendar, i+1, err)
+		}
+
+		switch property.Name {
+		case "BEGIN":
+			stack = append(stack, &Component{Name: strings.ToUpper(property.Value)})
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(property.Value) {
+				return nil, fmt.Errorf("%w: line %d: unexpected END:%s", ErrInvalidCalendar, i+1, property.Value)
+			}
+			done := *stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				roots = append(roots, done)
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, done)
+			}
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("%w: line %d: %s outside of a component", ErrInvalidCalendar, i+1, property.Name)
+			}
+			current := stack[len(stack)-1]
+			current.Properties = append(current.Properties, property)
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("%w: %s is not closed", ErrInvalidCalendar, stack[len(stack)-1].Name)
+	}
+
+	return roots, nil
+}
+
+// unfold joins continuation lines, which start with a space or a tab, to the line before them
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+// parseLine splits a content line into its name, parameters and value
+func parseLine(line string) (Property, error) {
+	var property Property
+
+	end := strings.IndexAny(line, ";:")
+	if end <= 0 {
+		return property, fmt.Errorf("missing property name")
+	}
+	property.Name = strings.ToUpper(line[:end])
+	line = line[end:]
+
+	for strings.HasPrefix(line, ";") {
+		line = line[1:]
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 {
+			return property, fmt.Errorf("parameter of %s has no value", property.Name)
+		}
+		name := strings.ToUpper(line[:eq])
+		line = line[eq+1:]
+
+		var value string
+		if strings.HasPrefix(line, `"`) {
+			closing := strings.IndexByte(line[1:], '"')
+			if closing < 0 {
+				return property, fmt.Errorf("unterminated quote in %s", property.Name)
+			}
+			value, line = line[1:closing+1], line[closing+2:]
+		} else {
+			end := strings.IndexAny(line, ";:")
+			if end < 0 {
+				return property, fmt.Errorf("%s has no value", property.Name)
+			}
+			value, line = line[:end], line[end:]
+		}
+
+		if property.Params == nil {
+			property.Params = map[string]string{}
+		}
+		property.Params[name] = value
+	}
+
+	if !strings.HasPrefix(line, ":") {
+		return property, fmt.Errorf("%s has no value", property.Name)
+	}
+	property.Value = line[1:]
+
+	return property, nil
+}
+
+// Get returns the first property called name
+func (c Component) Get(name string) (Property, bool) {
+	for _, property := range c.Properties {
+		if property.Name == name {
+			return property, true
+		}
+	}
+	return Property{}, false
+}
+
+// Text returns the unescaped value of the first property called name, "" when there is none
+func (c Component) Text(name string) string {
+	property, _ := c.Get(name)
+	return UnescapeText(property.Value)
+}
+
+// UnescapeText reverses EscapeText
+func UnescapeText(s string) string {
+	return textUnescaper.Replace(s)
+}
+
+var textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+// SplitText splits a list of TEXT values such as CATEGORIES on unescaped commas and unescapes them
+func SplitText(s string) []string {
+	var values []string
+	var current strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			current.WriteString(s[i : i+2])
+			i++
+		case s[i] == ',':
+			values = append(values, UnescapeText(current.String()))
+			current.Reset()
+		default:
+			current.WriteByte(s[i])
+		}
+	}
+	return append(values, UnescapeText(current.String()))
+}
+
+// Time parses a DATE or DATE-TIME property. UTC times end with Z, local times
+// use the zone named by TZID, and floating times without either are taken as UTC.
+func (p Property) Time() (time.Time, error) {
+	location := time.UTC
+	if tzid := p.Params["TZID"]; tzid != "" {
+		loaded, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown time zone %q in %s", tzid, p.Name)
+		}
+		location = loaded
+	}
+
+	value := p.Value
+	layouts := []string{"20060102T150405Z", "20060102T150405", "20060102"}
+	if p.Params["VALUE"] == "DATE" {
+		layouts = layouts[2:]
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%s %q is not a date", p.Name, value)
+}
@@ -0,0 +1,85 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	data := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:abc@example.com\r\n" +
+		"SUMMARY:Call John\\, then\r\n" +
+		"  Jane\r\n" +
+		"DUE;TZID=\"Asia/Jakarta\":20240301T090000\r\n" +
+		"CATEGORIES:work,calls\\, phone\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	roots, err := Decode(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, roots, 1)
+	require.Len(t, roots[0].Components, 1)
+
+	todo := roots[0].Components[0]
+	assert.Equal(t, "VTODO", todo.Name)
+	assert.Equal(t, "Call John, then Jane", todo.Text("SUMMARY"))
+	assert.Equal(t, "", todo.Text("DESCRIPTION"))
+
+	due, ok := todo.Get("DUE")
+	require.True(t, ok)
+	at, err := due.Time()
+	assert.NoError(t, err)
+	assert.True(t, at.Equal(time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC)))
+
+	categories, _ := todo.Get("CATEGORIES")
+	assert.Equal(t, []string{"work", "calls, phone"}, SplitText(categories.Value))
+}
+
+func TestDecodeRoundTrip(t *testing.T) {
+	todo := Component{Name: "VTODO"}
+	todo.AddText("SUMMARY", strings.Repeat("Call John; ", 20))
+	todo.Add("RELATED-TO", "task-2@example.com", "RELTYPE", "PARENT")
+
+	var out bytes.Buffer
+	require.NoError(t, Encode(&out, todo))
+
+	roots, err := Decode(&out)
+	require.NoError(t, err)
+	assert.Equal(t, []Component{todo}, roots)
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, data := range []string{
+		"BEGIN:VCALENDAR\nBEGIN:VTODO\nEND:VCALENDAR\n",
+		"BEGIN:VCALENDAR\n",
+		"SUMMARY:outside\n",
+		"BEGIN:VCALENDAR\nSUMMARY\nEND:VCALENDAR\n",
+	} {
+		_, err := Decode(strings.NewReader(data))
+		assert.ErrorIs(t, err, ErrInvalidCalendar, data)
+	}
+}
+
+func TestPropertyTime(t *testing.T) {
+	for value, want := range map[string]time.Time{
+		"20240301T090000Z": time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+		"20240301T090000":  time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+		"20240301":         time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		at, err := Property{Name: "DUE", Value: value}.Time()
+		assert.NoError(t, err)
+		assert.True(t, want.Equal(at), value)
+	}
+
+	_, err := Property{Name: "DUE", Value: "tomorrow"}.Time()
+	assert.Error(t, err)
+	_, err = Property{Name: "DUE", Value: "20240301T090000", Params: map[string]string{"TZID": "Mars/Olympus"}}.Time()
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+// Package ical reads and writes iCalendar (RFC 5545) objects
+package ical
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineLength is the longest content line in octets, without the CRLF
+const maxLineLength = 75
+
+// Property is a content line such as DUE;VALUE=DATE:20240301.
+// Value is written as is: text values must be escaped with EscapeText.
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Component is a BEGIN/END block such as VCALENDAR or VTODO
+type Component struct {
+	Name       string
+	Properties []Property
+	Components []Component
+}
+
+// Add appends a property, params are given as name, value pairs
+func (c *Component) Add(name, value string, params ...string) {
+	property := Property{Name: name, Value: value}
+	if len(params) > 1 {
+		property.Params = make(map[string]string, len(params)/2)
+		for i := 0; i+1 < len(params); i += 2 {
+			property.Params[params[i]] = params[i+1]
+		}
+	}
+	c.Properties = append(c.Properties, property)
+}
+
+// AddText appends a property whose value is escaped text
+func (c *Component) AddText(name, value string, params ...string) {
+	c.Add(name, EscapeText(value), params...)
+}
+
+// Encode writes the component with CRLF line endings and long lines folded
+func Encode(w io.Writer, c Component) error {
+	buf := bufio.NewWriter(w)
+	encode(buf, c)
+	return buf.Flush()
+}
+
+func encode(w *bufio.Writer, c Component) {
+	writeLine(w, "BEGIN:"+c.Name)
+	for _, property := range c.Properties {
+		writeLine(w, property.String())
+	}
+	for _, child := range c.Components {
+		encode(w, child)
+	}
+	writeLine(w, "END:"+c.Name)
+}
+
+// String returns the unfolded content line of the property
+func (p Property) String() string {
+	var line strings.Builder
+	line.WriteString(p.Name)
+
+	names := make([]string, 0, len(p.Params))
+	for name := range p.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		line.WriteString(";" + name + "=" + paramValue(p.Params[name]))
+	}
+
+	line.WriteString(":" + p.Value)
+	return line.String()
+}
+
+// paramValue quotes a parameter value that contains a separator
+func paramValue(value string) string {
+	if strings.ContainsAny(value, ";:,") {
+		return `"` + strings.ReplaceAll(value, `"`, "") + `"`
+	}
+	return value
+}
+
+// writeLine folds a content line into lines of at most maxLineLength octets,
+// continuation lines starting with a space, without splitting a UTF-8 sequence
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// the leading space of a continuation line counts towards its length
+		limit = maxLineLength - 1
+	}
+	w.WriteString(line + "\r\n")
+}
+
+// EscapeText escapes a TEXT value: backslashes, semicolons, commas and newlines
+func EscapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// FormatDateTime formats a DATE-TIME value in UTC
+func FormatDateTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
@@ -0,0 +1,60 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	todo := Component{Name: "VTODO"}
+	todo.Add("UID", "task-1@example.com")
+	todo.AddText("SUMMARY", "Call John; then, email\nhim")
+	todo.Add("RELATED-TO", "task-2@example.com", "RELTYPE", "PARENT")
+	todo.Add("X-NOTE", "x", "X-LABEL", "a:b")
+
+	calendar := Component{Name: "VCALENDAR", Components: []Component{todo}}
+	calendar.Add("VERSION", "2.0")
+
+	var out bytes.Buffer
+	assert.NoError(t, Encode(&out, calendar))
+	assert.Equal(t, "BEGIN:VCALENDAR\r\n"+
+		"VERSION:2.0\r\n"+
+		"BEGIN:VTODO\r\n"+
+		"UID:task-1@example.com\r\n"+
+		`SUMMARY:Call John\; then\, email\nhim`+"\r\n"+
+		"RELATED-TO;RELTYPE=PARENT:task-2@example.com\r\n"+
+		"X-NOTE;X-LABEL=\"a:b\":x\r\n"+
+		"END:VTODO\r\n"+
+		"END:VCALENDAR\r\n", out.String())
+}
+
+func TestEncodeFolding(t *testing.T) {
+	todo := Component{Name: "VTODO"}
+	todo.AddText("SUMMARY", strings.Repeat("é", 100))
+
+	var out bytes.Buffer
+	assert.NoError(t, Encode(&out, todo))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\r\n"), "\r\n")
+	var unfolded string
+	for i, line := range lines {
+		assert.LessOrEqual(t, len(line), 75, line)
+		assert.True(t, utf8.ValidString(line), line)
+		if i > 1 && i < len(lines)-1 {
+			assert.True(t, strings.HasPrefix(line, " "))
+			line = line[1:]
+		}
+		unfolded += line
+	}
+	assert.Equal(t, "BEGIN:VTODOSUMMARY:"+strings.Repeat("é", 100)+"END:VTODO", unfolded)
+}
+
+func TestFormatDateTime(t *testing.T) {
+	at := time.Date(2024, 3, 1, 9, 30, 0, 0, time.FixedZone("WIB", 7*3600))
+	assert.Equal(t, "20240301T023000Z", FormatDateTime(at))
+}
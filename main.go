@@ -21,6 +21,11 @@ func initServer(ctx context.Context) error {
 
 // @host localhost:3000
 // @BasePath /api
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Access token from /auth/login or API key from /keys, sent as "Bearer <token>". Requests go to the workspace of the token unless the X-Workspace-ID header picks another one.
 func main() {
 	serverCtx, stopCtx := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stopCtx()
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/Kbgjtn/notethingness-api.git/types"
+)
+
+var (
+	ErrBlobNotFound = errors.New("error: blob not found")
+	ErrInvalidKey   = errors.New("error: blob key is invalid")
+)
+
+// BlobStore keeps the content of uploaded files. Keys are slash separated
+// paths such as "tasks/1/3f2a"; metadata lives in Postgres.
+type BlobStore interface {
+	// Put stores size bytes read from r under key, replacing any previous content
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns the content of a blob, ErrBlobNotFound when it does not exist.
+	// The reader can seek, which lets handlers serve byte ranges.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	// Delete removes a blob; deleting a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// NewBlobStore returns the blob store configured by BLOB_STORE: "local" (default) or "s3"
+func NewBlobStore(env types.Env) (BlobStore, error) {
+	switch env.BlobStore {
+	case "", "local":
+		dir := env.BlobDir
+		if dir == "" {
+			dir = "data/blobs"
+		}
+		return NewLocalStore(dir)
+	case "s3":
+		return NewS3Store(S3Config{
+			Endpoint:  env.S3Endpoint,
+			Region:    env.S3Region,
+			Bucket:    env.S3Bucket,
+			AccessKey: env.S3AccessKey,
+			SecretKey: env.S3SecretKey,
+		})
+	default:
+		return nil, fmt.Errorf("error: unknown BLOB_STORE %q, expected local or s3", env.BlobStore)
+	}
+}
+
+// cleanKey rejects keys that are empty, absolute or escape their root with ".."
+func cleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || path.Clean(key) != key ||
+		key == ".." || strings.HasPrefix(key, "../") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return key, nil
+}
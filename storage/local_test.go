@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(ctx, "tasks/1/a", strings.NewReader("hello world"), 11, "text/plain"))
+
+	blob, err := store.Open(ctx, "tasks/1/a")
+	require.NoError(t, err)
+	_, err = blob.Seek(6, io.SeekStart)
+	require.NoError(t, err)
+	content, err := io.ReadAll(blob)
+	require.NoError(t, err)
+	assert.Equal(t, "world", string(content))
+	blob.Close()
+
+	assert.Error(t, store.Put(ctx, "tasks/1/b", strings.NewReader("short"), 11, ""), "size mismatch")
+	_, err = store.Open(ctx, "tasks/1/b")
+	assert.True(t, errors.Is(err, ErrBlobNotFound), "partial uploads are discarded")
+
+	require.NoError(t, store.Delete(ctx, "tasks/1/a"))
+	require.NoError(t, store.Delete(ctx, "tasks/1/a"), "deleting twice is not an error")
+	_, err = store.Open(ctx, "tasks/1/a")
+	assert.True(t, errors.Is(err, ErrBlobNotFound))
+}
+
+func TestCleanKey(t *testing.T) {
+	for _, key := range []string{"", "/etc/passwd", "../x", "a/../../x", "a//b", `a\b`} {
+		_, err := cleanKey(key)
+		assert.True(t, errors.Is(err, ErrInvalidKey), key)
+	}
+
+	_, err := cleanKey("tasks/1/a")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is an in-memory stand-in for an S3-compatible service with path-style buckets
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	ranges  []string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") ||
+		r.Header.Get("X-Amz-Content-Sha256") == "" {
+		http.Error(w, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = body
+	case http.MethodGet, http.MethodHead:
+		object, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		f.ranges = append(f.ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(object))
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeS3{objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{
+		Endpoint: server.URL, Bucket: "attachments", AccessKey: "key", SecretKey: "secret",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(ctx, "tasks/1/a", strings.NewReader("hello world"), 11, "text/plain"))
+	assert.Equal(t, []byte("hello world"), fake.objects["/attachments/tasks/1/a"])
+
+	blob, err := store.Open(ctx, "tasks/1/a")
+	require.NoError(t, err)
+	size, err := blob.Seek(0, io.SeekEnd)
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), size)
+
+	_, err = blob.Seek(6, io.SeekStart)
+	require.NoError(t, err)
+	content, err := io.ReadAll(blob)
+	require.NoError(t, err)
+	assert.Equal(t, "world", string(content))
+	assert.Equal(t, "bytes=6-", fake.ranges[len(fake.ranges)-1])
+	blob.Close()
+
+	require.NoError(t, store.Delete(ctx, "tasks/1/a"))
+	_, err = store.Open(ctx, "tasks/1/a")
+	assert.True(t, errors.Is(err, ErrBlobNotFound))
+}
+
+func TestS3StoreSignature(t *testing.T) {
+	store, err := NewS3Store(S3Config{
+		Endpoint: "http://localhost:9000", Region: "us-east-1", Bucket: "b",
+		AccessKey: "AKIDEXAMPLE", SecretKey: "secret",
+	})
+	require.NoError(t, err)
+	store.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	req, err := store.request(context.Background(), http.MethodGet, "tasks/1/a b", nil)
+	require.NoError(t, err)
+	store.sign(req, emptyPayloadHash)
+
+	assert.Equal(t, "/b/tasks/1/a%20b", req.URL.EscapedPath())
+	assert.Equal(t, "20240301T120000Z", req.Header.Get("X-Amz-Date"))
+	assert.Contains(t, req.Header.Get("Authorization"),
+		"Credential=AKIDEXAMPLE/20240301/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=")
+}
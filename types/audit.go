@@ -0,0 +1,22 @@
+package types
+
+import "context"
+
+// Audit identifies who made a change and in which request, for the history of changes
+type Audit struct {
+	Actor     string
+	RequestID string
+}
+
+type auditKey struct{}
+
+// WithAudit returns a copy of ctx carrying audit
+func WithAudit(ctx context.Context, audit Audit) context.Context {
+	return context.WithValue(ctx, auditKey{}, audit)
+}
+
+// AuditFrom returns the audit carried by ctx, empty when there is none
+func AuditFrom(ctx context.Context) Audit {
+	audit, _ := ctx.Value(auditKey{}).(Audit)
+	return audit
+}
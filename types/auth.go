@@ -0,0 +1,57 @@
+package types
+
+import "context"
+
+// Principal is the authenticated user a request is made for
+type Principal struct {
+	UserID int
+	Email  string
+	// KeyID is the API key the request was authenticated with, 0 for an access token
+	KeyID int
+	// Scopes restrict what an API key can do, nil for an access token
+	Scopes []string
+	// WorkspaceID is the workspace an API key is bound to, or the one an
+	// access token was issued for; 0 when the token has none
+	WorkspaceID int
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying principal
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the principal carried by ctx and whether there is one
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+type workspaceKey struct{}
+
+// WithWorkspace returns a copy of ctx scoped to a workspace, the tenant whose
+// data the repositories may see
+func WithWorkspace(ctx context.Context, workspaceID int) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, workspaceID)
+}
+
+// WorkspaceFrom returns the workspace ctx is scoped to, 0 when there is none
+func WorkspaceFrom(ctx context.Context) int {
+	workspaceID, _ := ctx.Value(workspaceKey{}).(int)
+	return workspaceID
+}
+
+type workspaceRoleKey struct{}
+
+// WithWorkspaceRole returns a copy of ctx carrying the role of the principal
+// in the workspace ctx is scoped to
+func WithWorkspaceRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, workspaceRoleKey{}, role)
+}
+
+// WorkspaceRoleFrom returns the role of the principal in the workspace, "" when unknown
+func WorkspaceRoleFrom(ctx context.Context) string {
+	role, _ := ctx.Value(workspaceRoleKey{}).(string)
+	return role
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"errors"
 	"strconv"
 )
 
@@ -17,13 +18,27 @@ type JSONError struct {
 }
 
 type JSONResultWithPaginate struct {
-	Code     int         `json:"code"`
-	Message  string      `json:"message"`
-	Data     interface{} `json:"data"`
-	Length   int         `json:"length"`
-	Paginate *Pageable   `json:"paginate"`
+	Code       int         `json:"code"`
+	Message    string      `json:"message"`
+	Data       interface{} `json:"data"`
+	Length     int         `json:"length"`
+	Paginate   *Pageable   `json:"paginate"`
+	NextCursor string      `json:"next_cursor,omitempty" example:"eyJzIjoiaWQiLCJ2IjpbMTBdfQ.c2lnbmF0dXJl"`
+	PrevCursor string      `json:"prev_cursor,omitempty" example:"eyJzIjoiaWQiLCJ2IjpbMV19.c2lnbmF0dXJl"`
 }
 
+// CountMode selects how the total number of rows of a list is computed
+type CountMode string
+
+const (
+	// CountExact counts every matching row
+	CountExact CountMode = "exact"
+	// CountEstimate uses the planner statistics of the table, ignoring filters
+	CountEstimate CountMode = "estimate"
+	// CountNone skips counting, has_next then tells whether another page exists
+	CountNone CountMode = "none"
+)
+
 type Pageable struct {
 	Offset  uint64 `json:"offset"`
 	Limit   uint64 `json:"limit"`
@@ -32,6 +47,15 @@ type Pageable struct {
 	Next    int64  `json:"next"`
 	HasNext bool   `json:"has_next"`
 	HasPrev bool   `json:"has_prev"`
+
+	// After and Before are keyset cursors taken from a previous page
+	After  string    `json:"-"`
+	Before string    `json:"-"`
+	Count  CountMode `json:"-"`
+	// More is set by the repository when rows exist past the end of the page
+	More       bool   `json:"-"`
+	NextCursor string `json:"-"`
+	PrevCursor string `json:"-"`
 }
 
 func (p Pageable) Validate() (uint64, uint64) {
@@ -66,11 +90,55 @@ func (p Pageable) Parse(limit, offset string) Pageable {
 	return p
 }
 
+// ParseCursor parses the after, before and count query parameters
+func (p Pageable) ParseCursor(after, before, count string) (Pageable, error) {
+	if after != "" && before != "" {
+		return p, errors.New("error: after and before cannot be used together")
+	}
+	p.After, p.Before = after, before
+
+	switch mode := CountMode(count); mode {
+	case "":
+		p.Count = CountExact
+	case CountExact, CountEstimate, CountNone:
+		p.Count = mode
+	default:
+		return p, errors.New("error: count must be one of exact, estimate or none")
+	}
+
+	return p, nil
+}
+
+// Keyset reports whether the page is selected by a cursor rather than an offset
+func (p Pageable) Keyset() bool {
+	return p.After != "" || p.Before != ""
+}
+
 func (p *Pageable) Calc() {
 	p.HasNextPage()
 	p.HasPrevPage()
 	p.NextPage()
 	p.PrevPage()
+
+	// offsets and totals do not describe cursor pages or uncounted lists
+	if p.Keyset() || (p.Count != "" && p.Count != CountExact) {
+		p.HasNext = p.More
+		p.HasPrev = p.PrevCursor != ""
+	}
+}
+
+// Result wraps a page of data together with its pagination and cursors
+func (p Pageable) Result(data interface{}, length int) JSONResultWithPaginate {
+	p.Calc()
+	return JSONResultWithPaginate{
+		Code:       200,
+		Message:    "success",
+		Data:       data,
+		Length:     length,
+		Paginate:   &p,
+		NextCursor: p.NextCursor,
+		PrevCursor: p.PrevCursor,
+	}
 }
 
 func (p *Pageable) NextPage() {
@@ -13,8 +13,30 @@ type Repository interface {
 }
 
 type Env struct {
-	Port    string
-	Host    string
-	DBUrl   string
-	SSLMode string
+	Port         string
+	Host         string
+	DBUrl        string
+	SSLMode      string
+	CursorSecret string
+	// TrashRetention is how long deleted tasks stay in the trash, e.g. "720h"
+	TrashRetention string
+	// BatchMaxSize caps the number of operations in POST /tasks/batch
+	BatchMaxSize string
+	// IfMatch is "required" or "optional", whether task writes must send If-Match
+	IfMatch string
+	// IdempotencyTTL is how long a response is replayed for its Idempotency-Key, e.g. "24h"
+	IdempotencyTTL string
+	// BlobStore selects where attachments are kept: "local" or "s3"
+	BlobStore   string
+	BlobDir     string
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	// JWTSecret signs access tokens, they only survive restarts when it is set
+	JWTSecret string
+	// AccessTokenTTL and RefreshTokenTTL are how long tokens are valid, e.g. "15m" and "720h"
+	AccessTokenTTL  string
+	RefreshTokenTTL string
 }
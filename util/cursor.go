@@ -0,0 +1,158 @@
+package util
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("error: cursor is invalid or does not match the sort order")
+
+// cursorKey signs cursors so clients cannot forge sort keys. It is random
+// until SetCursorKey is called, which makes cursors die with the process.
+var cursorKey = func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}()
+
+// SetCursorKey sets the secret used to sign and verify cursors. An empty key
+// keeps the random one; a key anyone could guess is rejected, since it would
+// let clients forge the sort keys of cursors.
+func SetCursorKey(key []byte) error {
+	if len(key) == 0 {
+		return nil
+	}
+	if string(key) == exampleSecret {
+		return errors.New("error: CURSOR_SECRET is the example value, generate a secret of your own")
+	}
+	if len(key) < MinTokenKeyLength {
+		return fmt.Errorf("error: CURSOR_SECRET must be at least %d bytes, got %d", MinTokenKeyLength, len(key))
+	}
+
+	cursorKey = key
+	return nil
+}
+
+type cursorPayload struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+}
+
+// SortSignature renders sort keys back into their query form, e.g. "-priority,date,id"
+func SortSignature(keys []SortField) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key.Field
+		if key.Desc {
+			parts[i] = "-" + key.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// EncodeCursor returns an opaque, signed token holding the sort key values of a row
+func EncodeCursor(keys []SortField, values []interface{}) string {
+	normalized := make([]interface{}, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case time.Time:
+			normalized[i] = v.Format(time.RFC3339Nano)
+		case []byte:
+			normalized[i] = string(v)
+		default:
+			normalized[i] = v
+		}
+	}
+
+	payload, _ := json.Marshal(cursorPayload{SortSignature(keys), normalized})
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(signCursor(encoded))
+}
+
+// DecodeCursor verifies a token and returns its sort key values, typed according
+// to schema. The token must have been created for the same sort keys.
+func DecodeCursor(token string, keys []SortField, schema FilterSchema) ([]interface{}, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, signCursor(encoded)) {
+		return nil, ErrInvalidCursor
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var payload cursorPayload
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if payload.Sort != SortSignature(keys) || len(payload.Values) != len(keys) {
+		return nil, ErrInvalidCursor
+	}
+
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		if payload.Values[i] == nil {
+			continue
+		}
+
+		if values[i], err = decodeCursorValue(payload.Values[i], schema[key.Field]); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return values, nil
+}
+
+func decodeCursorValue(value interface{}, fieldType FieldType) (interface{}, error) {
+	switch fieldType {
+	case FieldInt:
+		number, ok := value.(json.Number)
+		if !ok {
+			return nil, ErrInvalidCursor
+		}
+		return number.Int64()
+	case FieldFloat:
+		number, ok := value.(json.Number)
+		if !ok {
+			return nil, ErrInvalidCursor
+		}
+		return number.Float64()
+	case FieldTime:
+		s, ok := value.(string)
+		if !ok {
+			return nil, ErrInvalidCursor
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	default:
+		s, ok := value.(string)
+		if !ok {
+			return nil, ErrInvalidCursor
+		}
+		return s, nil
+	}
+}
+
+func signCursor(payload string) []byte {
+	mac := hmac.New(sha256.New, cursorKey)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,62 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	keys := []SortField{{Field: "priority", Desc: true}, {Field: "date"}, {Field: "title"}, {Field: "id"}}
+	schema := FilterSchema{"priority": FieldInt, "date": FieldTime, "title": FieldString, "id": FieldInt}
+	date := time.Date(2024, 3, 1, 9, 30, 0, 123456000, time.UTC)
+
+	token := EncodeCursor(keys, []interface{}{int64(2), date, []byte("Call John"), int64(42)})
+
+	values, err := DecodeCursor(token, keys, schema)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{int64(2), date, "Call John", int64(42)}, values)
+
+	token = EncodeCursor(keys, []interface{}{int64(2), nil, "x", int64(1)})
+	values, err = DecodeCursor(token, keys, schema)
+	assert.NoError(t, err)
+	assert.Nil(t, values[1])
+
+	keys = []SortField{{Field: "rank", Desc: true}, {Field: "id"}}
+	schema = FilterSchema{"rank": FieldFloat, "id": FieldInt}
+	token = EncodeCursor(keys, []interface{}{0.0607927106320858, int64(7)})
+	values, err = DecodeCursor(token, keys, schema)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{0.0607927106320858, int64(7)}, values)
+}
+
+func TestCursorRejected(t *testing.T) {
+	keys := []SortField{{Field: "id"}}
+	schema := FilterSchema{"id": FieldInt}
+	token := EncodeCursor(keys, []interface{}{int64(10)})
+
+	_, err := DecodeCursor(token, []SortField{{Field: "id", Desc: true}}, schema)
+	assert.ErrorIs(t, err, ErrInvalidCursor, "cursor of another sort order")
+
+	forged := EncodeCursor(keys, []interface{}{int64(99)})
+	_, err = DecodeCursor(forged[:len(forged)-4]+token[len(token)-4:], keys, schema)
+	assert.ErrorIs(t, err, ErrInvalidCursor, "tampered signature")
+
+	for _, bad := range []string{"", "abc", "abc.def", token + "x"} {
+		_, err = DecodeCursor(bad, keys, schema)
+		assert.ErrorIs(t, err, ErrInvalidCursor, bad)
+	}
+}
+
+func TestSetCursorKey(t *testing.T) {
+	defer func(key []byte) { cursorKey = key }(cursorKey)
+
+	assert.NoError(t, SetCursorKey(nil))
+	assert.Error(t, SetCursorKey([]byte("change-me")))
+	assert.Error(t, SetCursorKey([]byte("too-short-to-be-safe")))
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	assert.NoError(t, SetCursorKey(key))
+	assert.Equal(t, key, cursorKey)
+}
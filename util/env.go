@@ -15,9 +15,25 @@ func GetEnv() types.Env {
 	}
 
 	return types.Env{
-		Host:    os.Getenv("HOST"),
-		Port:    os.Getenv("PORT"),
-		DBUrl:   os.Getenv("DB_URL"),
-		SSLMode: os.Getenv("SSL_MODE"),
+		Host:           os.Getenv("HOST"),
+		Port:           os.Getenv("PORT"),
+		DBUrl:          os.Getenv("DB_URL"),
+		SSLMode:        os.Getenv("SSL_MODE"),
+		CursorSecret:   os.Getenv("CURSOR_SECRET"),
+		TrashRetention: os.Getenv("TRASH_RETENTION"),
+		BatchMaxSize:   os.Getenv("BATCH_MAX_SIZE"),
+		IfMatch:        os.Getenv("IF_MATCH"),
+		IdempotencyTTL: os.Getenv("IDEMPOTENCY_TTL"),
+		BlobStore:      os.Getenv("BLOB_STORE"),
+		BlobDir:        os.Getenv("BLOB_DIR"),
+		S3Endpoint:     os.Getenv("S3_ENDPOINT"),
+		S3Region:       os.Getenv("S3_REGION"),
+		S3Bucket:       os.Getenv("S3_BUCKET"),
+		S3AccessKey:    os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:    os.Getenv("S3_SECRET_KEY"),
+
+		JWTSecret:       os.Getenv("JWT_SECRET"),
+		AccessTokenTTL:  os.Getenv("ACCESS_TOKEN_TTL"),
+		RefreshTokenTTL: os.Getenv("REFRESH_TOKEN_TTL"),
 	}
 }
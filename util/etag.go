@@ -0,0 +1,75 @@
+package util
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ETags is the list of entity tags of an If-Match or If-None-Match header.
+// The zero value stands for a missing header.
+type ETags struct {
+	present bool
+	any     bool
+	tags    []string
+}
+
+// ParseETags parses a comma separated list of entity tags, or "*".
+// Weak tags keep their W/ prefix.
+func ParseETags(header string) ETags {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return ETags{}
+	}
+	if header == "*" {
+		return ETags{present: true, any: true}
+	}
+
+	etags := ETags{present: true}
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			etags.tags = append(etags.tags, tag)
+		}
+	}
+	return etags
+}
+
+// IfMatch returns the entity tags of the request's If-Match header
+func IfMatch(r *http.Request) ETags {
+	return ParseETags(r.Header.Get("If-Match"))
+}
+
+// IfNoneMatch returns the entity tags of the request's If-None-Match header
+func IfNoneMatch(r *http.Request) ETags {
+	return ParseETags(r.Header.Get("If-None-Match"))
+}
+
+// Present reports whether the header was sent
+func (e ETags) Present() bool {
+	return e.present
+}
+
+// Match uses the strong comparison of If-Match: weak tags never match
+func (e ETags) Match(etag string) bool {
+	if e.any {
+		return true
+	}
+	for _, tag := range e.tags {
+		if tag == etag && !strings.HasPrefix(tag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchWeak uses the weak comparison of If-None-Match, ignoring W/ prefixes
+func (e ETags) MatchWeak(etag string) bool {
+	if e.any {
+		return true
+	}
+	for _, tag := range e.tags {
+		if strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETags(t *testing.T) {
+	none := ParseETags("")
+	assert.False(t, none.Present())
+	assert.False(t, none.Match(`"1"`))
+
+	any := ParseETags("*")
+	assert.True(t, any.Present())
+	assert.True(t, any.Match(`"1"`))
+
+	list := ParseETags(`"1", W/"2" ,"3"`)
+	assert.True(t, list.Present())
+	assert.True(t, list.Match(`"1"`))
+	assert.True(t, list.Match(`"3"`))
+	assert.False(t, list.Match(`"2"`))
+	assert.True(t, list.MatchWeak(`"2"`))
+	assert.False(t, list.MatchWeak(`"4"`))
+}
@@ -0,0 +1,351 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FieldType is the type of a filterable field, used to validate filter values
+type FieldType int
+
+const (
+	FieldString FieldType = iota
+	FieldInt
+	FieldTime
+	FieldFloat
+)
+
+// FilterSchema whitelists the fields a list endpoint can be filtered and sorted by
+type FilterSchema map[string]FieldType
+
+// FilterNode is a node of a parsed filter expression:
+// FilterLogical, FilterNot or FilterComparison
+type FilterNode interface {
+	filterNode()
+}
+
+// FilterLogical combines two expressions with "and" or "or"
+type FilterLogical struct {
+	Op          string
+	Left, Right FilterNode
+}
+
+// FilterNot negates an expression
+type FilterNot struct {
+	Expr FilterNode
+}
+
+// FilterComparison compares a field with a value. Op is one of
+// = != > >= < <= and ~ (case-insensitive contains). Value is nil for null,
+// otherwise an int64, float64, time.Time or string depending on the field type.
+type FilterComparison struct {
+	Field string
+	Op    string
+	Value interface{}
+}
+
+func (FilterLogical) filterNode()    {}
+func (FilterNot) filterNode()        {}
+func (FilterComparison) filterNode() {}
+
+// SortField is one key of a sort expression such as "-priority,date"
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// FilterError points at the token of a filter or sort expression that could not be parsed
+type FilterError struct {
+	Pos     int
+	Token   string
+	Message string
+}
+
+func (e *FilterError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("error: invalid expression at position %d: %s", e.Pos, e.Message)
+	}
+	return fmt.Sprintf("error: invalid expression at position %d near %q: %s", e.Pos, e.Token, e.Message)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOperator
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// ParseFilter parses a filter expression such as
+//
+//	priority>=2 and (date<2024-04-01 or status=blocked) and not title~"call"
+//
+// into an AST, accepting only the fields of schema
+func ParseFilter(input string, schema FilterSchema) (FilterNode, error) {
+	tokens, err := lexFilter(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens, schema: schema}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, &FilterError{t.pos, t.text, "expected \"and\", \"or\" or end of expression"}
+	}
+
+	return node, nil
+}
+
+// ParseSort parses a comma separated list of fields, each optionally
+// prefixed with "-" for descending order, accepting only the fields of schema
+func ParseSort(input string, schema FilterSchema) ([]SortField, error) {
+	var fields []SortField
+	pos := 0
+
+	for _, part := range strings.Split(input, ",") {
+		field := SortField{Field: strings.TrimSpace(part)}
+		if strings.HasPrefix(field.Field, "-") {
+			field.Desc = true
+			field.Field = field.Field[1:]
+		} else {
+			field.Field = strings.TrimPrefix(field.Field, "+")
+		}
+
+		if _, ok := schema[field.Field]; !ok {
+			return nil, &FilterError{pos, strings.TrimSpace(part), "unknown sort field"}
+		}
+
+		fields = append(fields, field)
+		pos += len(part) + 1
+	}
+
+	return fields, nil
+}
+
+func lexFilter(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokenLParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenRParen, ")", i})
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			var value strings.Builder
+			for end < len(runes) && runes[end] != r {
+				if runes[end] == '\\' && end+1 < len(runes) {
+					end++
+				}
+				value.WriteRune(runes[end])
+				end++
+			}
+			if end >= len(runes) {
+				return nil, &FilterError{i, string(runes[i:]), "unterminated string"}
+			}
+			tokens = append(tokens, token{tokenString, value.String(), i})
+			i = end + 1
+		case strings.ContainsRune("=!<>~", r):
+			end := i + 1
+			if end < len(runes) && runes[end] == '=' && r != '=' && r != '~' {
+				end++
+			}
+			op := string(runes[i:end])
+			if op == "!" {
+				return nil, &FilterError{i, op, "unknown operator"}
+			}
+			tokens = append(tokens, token{tokenOperator, op, i})
+			i = end
+		case isWordRune(r):
+			end := i
+			for end < len(runes) && isWordRune(runes[end]) {
+				end++
+			}
+			tokens = append(tokens, token{tokenWord, string(runes[i:end]), i})
+			i = end
+		default:
+			return nil, &FilterError{i, string(r), "unexpected character"}
+		}
+	}
+
+	return append(tokens, token{tokenEOF, "", len(runes)}), nil
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-.:+", r)
+}
+
+type filterParser struct {
+	tokens []token
+	pos    int
+	schema FilterSchema
+}
+
+func (p *filterParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *filterParser) keyword(word string) bool {
+	t := p.peek()
+	if t.kind == tokenWord && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (FilterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = FilterLogical{"or", left, right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (FilterNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = FilterLogical{"and", left, right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseNot() (FilterNode, error) {
+	if p.keyword("not") {
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return FilterNot{expr}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *filterParser) parsePrimary() (FilterNode, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, &FilterError{closing.pos, closing.text, "expected \")\""}
+		}
+		return expr, nil
+	case tokenWord:
+		return p.parseComparison(t)
+	case tokenEOF:
+		return nil, &FilterError{t.pos, "", "unexpected end of expression"}
+	default:
+		return nil, &FilterError{t.pos, t.text, "expected a field name"}
+	}
+}
+
+func (p *filterParser) parseComparison(field token) (FilterNode, error) {
+	fieldType, ok := p.schema[field.text]
+	if !ok {
+		return nil, &FilterError{field.pos, field.text, "unknown field"}
+	}
+
+	op := p.next()
+	if op.kind != tokenOperator {
+		return nil, &FilterError{op.pos, op.text, "expected an operator (= != > >= < <= ~)"}
+	}
+
+	value := p.next()
+	if value.kind != tokenWord && value.kind != tokenString {
+		return nil, &FilterError{value.pos, value.text, "expected a value"}
+	}
+
+	comparison := FilterComparison{Field: field.text, Op: op.text}
+
+	if value.kind == tokenWord && strings.EqualFold(value.text, "null") {
+		if op.text != "=" && op.text != "!=" {
+			return nil, &FilterError{op.pos, op.text, "null can only be compared with = or !="}
+		}
+		return comparison, nil
+	}
+
+	if op.text == "~" && fieldType != FieldString {
+		return nil, &FilterError{op.pos, op.text, "~ can only be used on text fields"}
+	}
+
+	var err error
+	switch fieldType {
+	case FieldInt:
+		comparison.Value, err = strconv.ParseInt(value.text, 10, 64)
+		if err != nil {
+			return nil, &FilterError{value.pos, value.text, "expected an integer"}
+		}
+	case FieldFloat:
+		comparison.Value, err = strconv.ParseFloat(value.text, 64)
+		if err != nil {
+			return nil, &FilterError{value.pos, value.text, "expected a number"}
+		}
+	case FieldTime:
+		comparison.Value, err = time.Parse(time.RFC3339, value.text)
+		if err != nil {
+			comparison.Value, err = time.Parse(time.DateOnly, value.text)
+		}
+		if err != nil {
+			return nil, &FilterError{value.pos, value.text, "expected a date (2006-01-02) or RFC 3339 timestamp"}
+		}
+	default:
+		comparison.Value = value.text
+	}
+
+	return comparison, nil
+}
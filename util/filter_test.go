@@ -0,0 +1,68 @@
+package util
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testSchema = FilterSchema{
+	"title":    FieldString,
+	"priority": FieldInt,
+	"date":     FieldTime,
+}
+
+func TestParseFilter(t *testing.T) {
+	node, err := ParseFilter(`priority>=2 and date<2024-04-01 or not title~"call john"`, testSchema)
+	assert.NoError(t, err)
+
+	assert.Equal(t, FilterLogical{
+		Op: "or",
+		Left: FilterLogical{
+			Op:    "and",
+			Left:  FilterComparison{Field: "priority", Op: ">=", Value: int64(2)},
+			Right: FilterComparison{Field: "date", Op: "<", Value: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		Right: FilterNot{FilterComparison{Field: "title", Op: "~", Value: "call john"}},
+	}, node)
+
+	node, err = ParseFilter(`priority=1 AND (title='a' OR date = null)`, testSchema)
+	assert.NoError(t, err)
+	assert.Equal(t, FilterComparison{Field: "date", Op: "="}, node.(FilterLogical).Right.(FilterLogical).Right)
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	cases := map[string]FilterError{
+		"owner=1":                {Pos: 0, Token: "owner"},
+		"priority>=high":         {Pos: 10, Token: "high"},
+		"priority>=2 and":        {Pos: 15, Token: ""},
+		"priority>=2 date<2024":  {Pos: 12, Token: "date"},
+		"(priority=1":            {Pos: 11, Token: ""},
+		"priority~1":             {Pos: 8, Token: "~"},
+		`title="open`:            {Pos: 6, Token: `"open`},
+		"priority=1; drop table": {Pos: 10, Token: ";"},
+		"date<yesterday":         {Pos: 5, Token: "yesterday"},
+		"priority>null":          {Pos: 8, Token: ">"},
+	}
+
+	for input, expected := range cases {
+		_, err := ParseFilter(input, testSchema)
+
+		var filterErr *FilterError
+		if assert.True(t, errors.As(err, &filterErr), input) {
+			assert.Equal(t, expected.Pos, filterErr.Pos, input)
+			assert.Equal(t, expected.Token, filterErr.Token, input)
+		}
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	fields, err := ParseSort("-priority,date", testSchema)
+	assert.NoError(t, err)
+	assert.Equal(t, []SortField{{Field: "priority", Desc: true}, {Field: "date"}}, fields)
+
+	_, err = ParseSort("date,-owner", testSchema)
+	assert.EqualError(t, err, `error: invalid expression at position 5 near "-owner": unknown sort field`)
+}
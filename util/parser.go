@@ -3,18 +3,44 @@ package util
 import (
 	"encoding/json"
 	"fmt"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"unicode"
 )
 
-// parseRequestBody parses request body to given interface
+// MaxMultipartMemory is how much of a multipart body is kept in memory,
+// larger files are spooled to temporary files
+const MaxMultipartMemory = 8 << 20
+
+// MultipartDecoder is implemented by payloads that can be sent as multipart/form-data
+type MultipartDecoder interface {
+	DecodeMultipart(form *multipart.Form) error
+}
+
+// parseRequestBody parses request body to given interface.
+// A multipart body stays available in r.MultipartForm, which the caller
+// should release with r.MultipartForm.RemoveAll.
 func ParseRequestBody(r *http.Request, v interface{}) error {
 	defer r.Body.Close()
 	contentType := r.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("error: Unsupported Content-Type: %s", contentType)
+	}
 
-	switch contentType {
+	switch mediaType {
 	case "application/json":
 		return json.NewDecoder(r.Body).Decode(v)
+	case "multipart/form-data":
+		decoder, ok := v.(MultipartDecoder)
+		if !ok {
+			return fmt.Errorf("error: Unsupported Content-Type: %s", mediaType)
+		}
+		if err := r.ParseMultipartForm(MaxMultipartMemory); err != nil {
+			return fmt.Errorf("error: invalid multipart body: %w", err)
+		}
+		return decoder.DecodeMultipart(r.MultipartForm)
 	default:
 		return fmt.Errorf("error: Unsupported Content-Type: %s", contentType)
 	}
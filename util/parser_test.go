@@ -1,5 +1,14 @@
 package util
 
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
 type ExampleStruct struct {
 	Name  string `json:"name"`
 	Value int    `json:"value"`
@@ -18,3 +27,49 @@ type ExampleStruct struct {
 	expectedData := ExampleStruct{Name: "example", Value: 42}
 	assert.Equal(t, expectedData, data)
 } */
+
+type exampleUpload struct {
+	Name string
+	File *multipart.FileHeader
+}
+
+func (u *exampleUpload) DecodeMultipart(form *multipart.Form) error {
+	u.Name = form.Value["name"][0]
+	u.File = form.File["file"][0]
+	return nil
+}
+
+func TestParseRequestBodyMultipart(t *testing.T) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	assert.NoError(t, writer.WriteField("name", "example"))
+	part, err := writer.CreateFormFile("file", "notes.txt")
+	assert.NoError(t, err)
+	part.Write([]byte("hello"))
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest("POST", "/test", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	var upload exampleUpload
+	assert.NoError(t, ParseRequestBody(req, &upload))
+	defer req.MultipartForm.RemoveAll()
+
+	assert.Equal(t, "example", upload.Name)
+	assert.Equal(t, "notes.txt", upload.File.Filename)
+	assert.Equal(t, int64(5), upload.File.Size)
+
+	var data ExampleStruct
+	req = httptest.NewRequest("POST", "/test", bytes.NewBufferString("--x--"))
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+	assert.Error(t, ParseRequestBody(req, &data), "payload does not decode multipart")
+}
+
+func TestParseRequestBodyJSONWithCharset(t *testing.T) {
+	req := httptest.NewRequest("POST", "/test", bytes.NewBufferString(`{"name": "example", "value": 42}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	var data ExampleStruct
+	assert.NoError(t, ParseRequestBody(req, &data))
+	assert.Equal(t, ExampleStruct{Name: "example", Value: 42}, data)
+}
@@ -0,0 +1,402 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MergePatchType is the media type of a JSON Merge Patch (RFC 7396)
+	MergePatchType = "application/merge-patch+json"
+	// JSONPatchType is the media type of a JSON Patch (RFC 6902)
+	JSONPatchType = "application/json-patch+json"
+)
+
+var (
+	ErrUnsupportedPatch = errors.New("error: Content-Type must be " + MergePatchType + " or " + JSONPatchType)
+	ErrInvalidPatch     = errors.New("error: patch document is invalid")
+	ErrPatchConflict    = errors.New("error: patch cannot be applied to the current resource")
+	ErrPatchResult      = errors.New("error: patched resource is invalid")
+)
+
+// Patch changes a JSON document
+type Patch interface {
+	Apply(doc []byte) ([]byte, error)
+}
+
+// ParsePatch reads a merge patch or a JSON patch from the request body,
+// depending on its Content-Type
+func ParsePatch(r *http.Request) (Patch, error) {
+	defer r.Body.Close()
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, ErrUnsupportedPatch
+	}
+
+	switch mediaType {
+	case MergePatchType:
+		var patch MergePatch
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err)
+		}
+		return patch, nil
+	case JSONPatchType:
+		var patch JSONPatch
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err)
+		}
+		if err := patch.Validate(); err != nil {
+			return nil, err
+		}
+		return patch, nil
+	default:
+		return nil, ErrUnsupportedPatch
+	}
+}
+
+// PatchValue applies a patch to the JSON form of current and decodes the
+// result into a new value. Fields that T does not have are rejected.
+func PatchValue[T any](patch Patch, current T) (T, error) {
+	var patched T
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return patched, err
+	}
+
+	if doc, err = patch.Apply(doc); err != nil {
+		return patched, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patched); err != nil {
+		return patched, fmt.Errorf("%w: %s", ErrPatchResult, err)
+	}
+
+	return patched, nil
+}
+
+// MergePatch is a JSON Merge Patch: objects are merged recursively,
+// null removes a member and any other value replaces the target
+type MergePatch json.RawMessage
+
+func (p *MergePatch) UnmarshalJSON(data []byte) error {
+	*p = append((*p)[:0], data...)
+	return nil
+}
+
+func (p MergePatch) Apply(doc []byte) ([]byte, error) {
+	var target, patch interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(p, &patch); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err)
+	}
+
+	return json.Marshal(mergePatch(target, patch))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	members, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	object, ok := target.(map[string]interface{})
+	if !ok {
+		object = map[string]interface{}{}
+	}
+
+	for key, value := range members {
+		if value == nil {
+			delete(object, key)
+		} else {
+			object[key] = mergePatch(object[key], value)
+		}
+	}
+
+	return object
+}
+
+// PatchOperation is one step of a JSON Patch. Value is empty when the
+// operation has no value member, and holds null when the value is null.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch is a list of operations applied in order, all or none
+type JSONPatch []PatchOperation
+
+// Validate checks that every operation is well formed before any is applied
+func (p JSONPatch) Validate() error {
+	for i, op := range p {
+		if err := op.validate(); err != nil {
+			return fmt.Errorf("%w: operation %d: %s", ErrInvalidPatch, i, err)
+		}
+	}
+	return nil
+}
+
+func (op PatchOperation) validate() error {
+	if _, err := parsePointer(op.Path); err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if len(op.Value) == 0 {
+			return fmt.Errorf("%q requires a value", op.Op)
+		}
+	case "move", "copy":
+		if _, err := parsePointer(op.From); err != nil {
+			return err
+		}
+		if op.Op == "move" && strings.HasPrefix(op.Path, op.From+"/") {
+			return fmt.Errorf("cannot move %q into one of its children", op.From)
+		}
+	case "remove":
+	default:
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+
+	return nil
+}
+
+func (p JSONPatch) Apply(doc []byte) ([]byte, error) {
+	var root interface{}
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, err
+	}
+
+	for i, op := range p {
+		var err error
+		if root, err = op.apply(root); err != nil {
+			return nil, fmt.Errorf("%w: operation %d: %s", ErrPatchConflict, i, err)
+		}
+	}
+
+	return json.Marshal(root)
+}
+
+func (op PatchOperation) apply(root interface{}) (interface{}, error) {
+	path, _ := parsePointer(op.Path)
+
+	var value interface{}
+	if len(op.Value) > 0 {
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, err
+		}
+	}
+
+	switch op.Op {
+	case "add":
+		return pointerUpdate(root, path, addMember(value))
+	case "remove":
+		return pointerUpdate(root, path, removeMember)
+	case "replace":
+		return pointerUpdate(root, path, replaceMember(value))
+	case "move", "copy":
+		from, _ := parsePointer(op.From)
+		value, err := pointerGet(root, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if len(from) > 0 {
+				if root, err = pointerUpdate(root, from, removeMember); err != nil {
+					return nil, err
+				}
+			}
+		} else if value, err = deepCopy(value); err != nil {
+			return nil, err
+		}
+		return pointerUpdate(root, path, addMember(value))
+	default:
+		actual, err := pointerGet(root, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(actual, value) {
+			return nil, fmt.Errorf("test failed at %q", op.Path)
+		}
+		return root, nil
+	}
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into its unescaped tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path %q must start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func pointerGet(node interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("%q does not exist", token)
+			}
+			node = value
+		case []interface{}:
+			i, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = container[i]
+		default:
+			return nil, fmt.Errorf("%q does not exist", token)
+		}
+	}
+	return node, nil
+}
+
+// memberUpdate changes the member key of container and returns the container,
+// which is a new slice when an array grows or shrinks
+type memberUpdate func(container interface{}, key string) (interface{}, error)
+
+// wholeDocument is the parent of the document root
+type wholeDocument struct{}
+
+// pointerUpdate applies update to the parent of the last token of path.
+// The empty path stands for the whole document, which update receives as
+// a wholeDocument container.
+func pointerUpdate(node interface{}, path []string, update memberUpdate) (interface{}, error) {
+	if len(path) == 0 {
+		return update(wholeDocument{}, "")
+	}
+	if len(path) == 1 {
+		return update(node, path[0])
+	}
+
+	child, err := pointerGet(node, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	if child, err = pointerUpdate(child, path[1:], update); err != nil {
+		return nil, err
+	}
+
+	switch container := node.(type) {
+	case map[string]interface{}:
+		container[path[0]] = child
+	case []interface{}:
+		i, _ := arrayIndex(path[0], len(container)-1)
+		container[i] = child
+	}
+	return node, nil
+}
+
+func addMember(value interface{}) memberUpdate {
+	return func(container interface{}, key string) (interface{}, error) {
+		switch c := container.(type) {
+		case wholeDocument:
+			return value, nil
+		case map[string]interface{}:
+			c[key] = value
+			return c, nil
+		case []interface{}:
+			i := len(c)
+			if key != "-" {
+				var err error
+				if i, err = arrayIndex(key, len(c)); err != nil {
+					return nil, err
+				}
+			}
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+			c[i] = value
+			return c, nil
+		default:
+			return nil, fmt.Errorf("%q does not exist", key)
+		}
+	}
+}
+
+func removeMember(container interface{}, key string) (interface{}, error) {
+	switch c := container.(type) {
+	case wholeDocument:
+		return nil, fmt.Errorf("cannot remove the whole document")
+	case map[string]interface{}:
+		if _, ok := c[key]; !ok {
+			return nil, fmt.Errorf("%q does not exist", key)
+		}
+		delete(c, key)
+		return c, nil
+	case []interface{}:
+		i, err := arrayIndex(key, len(c)-1)
+		if err != nil {
+			return nil, err
+		}
+		return append(c[:i], c[i+1:]...), nil
+	default:
+		return nil, fmt.Errorf("%q does not exist", key)
+	}
+}
+
+func replaceMember(value interface{}) memberUpdate {
+	return func(container interface{}, key string) (interface{}, error) {
+		switch c := container.(type) {
+		case wholeDocument:
+			return value, nil
+		case map[string]interface{}:
+			if _, ok := c[key]; !ok {
+				return nil, fmt.Errorf("%q does not exist", key)
+			}
+			c[key] = value
+			return c, nil
+		case []interface{}:
+			i, err := arrayIndex(key, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			c[i] = value
+			return c, nil
+		default:
+			return nil, fmt.Errorf("%q does not exist", key)
+		}
+	}
+}
+
+// arrayIndex parses an array index token that must not be greater than max
+func arrayIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%q is not an array index", token)
+	}
+	if i > max {
+		return 0, fmt.Errorf("index %d is out of range", i)
+	}
+	return i, nil
+}
+
+func deepCopy(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var copied interface{}
+	return copied, json.Unmarshal(data, &copied)
+}
@@ -0,0 +1,114 @@
+package util
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parsePatch(t *testing.T, contentType, body string) Patch {
+	r := httptest.NewRequest("PATCH", "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+	patch, err := ParsePatch(r)
+	require.NoError(t, err)
+	return patch
+}
+
+func TestMergePatch(t *testing.T) {
+	patch := parsePatch(t, MergePatchType, `{"a":"z","c":{"f":null},"g":[1]}`)
+
+	doc, err := patch.Apply([]byte(`{"a":"b","c":{"d":"e","f":"g"},"g":[2,3]}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a":"z","c":{"d":"e"},"g":[1]}`, string(doc))
+}
+
+func TestJSONPatch(t *testing.T) {
+	patch := parsePatch(t, JSONPatchType+"; charset=utf-8", `[
+		{"op":"test","path":"/a/b/c","value":"foo"},
+		{"op":"remove","path":"/a/b/c"},
+		{"op":"add","path":"/a/b/c","value":["foo","bar"]},
+		{"op":"replace","path":"/a/b/c","value":42},
+		{"op":"move","from":"/a/b/c","path":"/a/b/d"},
+		{"op":"copy","from":"/a/b/d","path":"/a/b/e"},
+		{"op":"add","path":"/list/1","value":"x"},
+		{"op":"add","path":"/list/-","value":"z"},
+		{"op":"remove","path":"/list/0"},
+		{"op":"add","path":"/m~1n","value":true}
+	]`)
+
+	doc, err := patch.Apply([]byte(`{"a":{"b":{"c":"foo"}},"list":["w","y"]}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a":{"b":{"d":42,"e":42}},"list":["x","y","z"],"m/n":true}`, string(doc))
+
+	doc, err = parsePatch(t, JSONPatchType, `[{"op":"replace","path":"","value":{"x":1}}]`).Apply([]byte(`{"a":1}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"x":1}`, string(doc))
+}
+
+func TestJSONPatchNull(t *testing.T) {
+	patch := parsePatch(t, JSONPatchType, `[
+		{"op":"test","path":"/b","value":null},
+		{"op":"replace","path":"/a","value":null},
+		{"op":"add","path":"/c","value":null}
+	]`)
+
+	doc, err := patch.Apply([]byte(`{"a":1,"b":null}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a":null,"b":null,"c":null}`, string(doc))
+}
+
+func TestJSONPatchConflict(t *testing.T) {
+	doc := []byte(`{"a":1,"list":[1]}`)
+
+	for _, body := range []string{
+		`[{"op":"test","path":"/a","value":2}]`,
+		`[{"op":"replace","path":"/b","value":2}]`,
+		`[{"op":"remove","path":"/list/1"}]`,
+		`[{"op":"add","path":"/list/01","value":2}]`,
+		`[{"op":"add","path":"/missing/key","value":2}]`,
+		`[{"op":"remove","path":""}]`,
+	} {
+		_, err := parsePatch(t, JSONPatchType, body).Apply(doc)
+		assert.ErrorIs(t, err, ErrPatchConflict, body)
+	}
+}
+
+func TestParsePatchInvalid(t *testing.T) {
+	for contentType, body := range map[string]string{
+		JSONPatchType:                  `[{"op":"add","path":"/a"}]`,
+		JSONPatchType + " ":            `[{"op":"move","from":"/a","path":"/a/b"}]`,
+		"application/json-patch+json;": `[{"op":"upsert","path":"/a","value":1}]`,
+		MergePatchType:                 `{"a":`,
+	} {
+		r := httptest.NewRequest("PATCH", "/", strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		_, err := ParsePatch(r)
+		assert.ErrorIs(t, err, ErrInvalidPatch, body)
+	}
+
+	r := httptest.NewRequest("PATCH", "/", strings.NewReader(`{}`))
+	r.Header.Set("Content-Type", "application/json")
+	_, err := ParsePatch(r)
+	assert.ErrorIs(t, err, ErrUnsupportedPatch)
+}
+
+func TestPatchValue(t *testing.T) {
+	type payload struct {
+		Title    string `json:"title"`
+		Priority int    `json:"priority"`
+	}
+	current := payload{Title: "Call John", Priority: 1}
+
+	patched, err := PatchValue(parsePatch(t, MergePatchType, `{"priority":3}`), current)
+	assert.NoError(t, err)
+	assert.Equal(t, payload{Title: "Call John", Priority: 3}, patched)
+
+	_, err = PatchValue(parsePatch(t, MergePatchType, `{"status":"done"}`), current)
+	assert.ErrorIs(t, err, ErrPatchResult)
+
+	_, err = PatchValue(parsePatch(t, MergePatchType, `{"priority":"high"}`), current)
+	assert.ErrorIs(t, err, ErrPatchResult)
+}
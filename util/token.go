@@ -0,0 +1,129 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("error: access token is invalid or expired")
+
+// tokenIssuer is the "iss" claim of the access tokens
+const tokenIssuer = "notethingness-api"
+
+// MinTokenKeyLength is the shortest JWT_SECRET or CURSOR_SECRET accepted, that
+// of the random keys
+const MinTokenKeyLength = 32
+
+// exampleSecret is the placeholder secret of the docs, which is public
+const exampleSecret = "change-me"
+
+// tokenKey signs access tokens. It is random until SetTokenKey is called,
+// which logs everybody out when the process restarts.
+var tokenKey = func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}()
+
+// SetTokenKey sets the secret used to sign and verify access tokens. An empty
+// key keeps the random one; a key anyone could guess is rejected.
+func SetTokenKey(key []byte) error {
+	if len(key) == 0 {
+		return nil
+	}
+	if string(key) == exampleSecret {
+		return errors.New("error: JWT_SECRET is the example value, generate a secret of your own")
+	}
+	if len(key) < MinTokenKeyLength {
+		return fmt.Errorf("error: JWT_SECRET must be at least %d bytes, got %d", MinTokenKeyLength, len(key))
+	}
+
+	tokenKey = key
+	return nil
+}
+
+// AccessClaims are the claims of an access token, the user id is the subject
+type AccessClaims struct {
+	Email string `json:"email"`
+	// WorkspaceID is the workspace requests go to unless they pick another one
+	WorkspaceID int `json:"workspace_id,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// UserID returns the user the token was issued to
+func (c AccessClaims) UserID() (int, error) {
+	id, err := strconv.Atoi(c.Subject)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidToken
+	}
+	return id, nil
+}
+
+// IssueAccessToken returns a signed JWT (HS256) for a user and their default
+// workspace, valid for ttl
+func IssueAccessToken(userID int, email string, workspaceID int, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := AccessClaims{
+		Email:       email,
+		WorkspaceID: workspaceID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Subject:   strconv.Itoa(userID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(tokenKey)
+}
+
+// ParseAccessToken verifies the signature, issuer and expiry of an access token
+func ParseAccessToken(token string) (AccessClaims, error) {
+	var claims AccessClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return tokenKey, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return claims, ErrInvalidToken
+	}
+
+	if _, err := claims.UserID(); err != nil {
+		return claims, err
+	}
+	return claims, nil
+}
+
+// NewRefreshToken returns a random opaque refresh token and the hash it is stored under
+func NewRefreshToken() (token, hash string, err error) {
+	return NewOpaqueToken("")
+}
+
+// NewOpaqueToken returns a random token starting with prefix and the hash it
+// is stored under, for secrets that are looked up rather than verified
+func NewOpaqueToken(prefix string) (token, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	token = prefix + base64.RawURLEncoding.EncodeToString(raw)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the hash an opaque token is stored and looked up under
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
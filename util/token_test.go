@@ -0,0 +1,88 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessTokenRoundTrip(t *testing.T) {
+	token, err := IssueAccessToken(42, "jane@example.com", 7, time.Minute)
+	assert.NoError(t, err)
+
+	claims, err := ParseAccessToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "jane@example.com", claims.Email)
+	assert.Equal(t, 7, claims.WorkspaceID)
+
+	id, err := claims.UserID()
+	assert.NoError(t, err)
+	assert.Equal(t, 42, id)
+}
+
+func TestSetTokenKey(t *testing.T) {
+	defer func(key []byte) { tokenKey = key }(tokenKey)
+
+	assert.NoError(t, SetTokenKey(nil))
+	assert.Error(t, SetTokenKey([]byte("change-me")))
+	assert.Error(t, SetTokenKey([]byte("too-short-to-be-safe")))
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	assert.NoError(t, SetTokenKey(key))
+	assert.Equal(t, key, tokenKey)
+}
+
+func TestAccessTokenRejected(t *testing.T) {
+	expired, err := IssueAccessToken(1, "jane@example.com", 1, -time.Minute)
+	assert.NoError(t, err)
+	_, err = ParseAccessToken(expired)
+	assert.ErrorIs(t, err, ErrInvalidToken, "expired")
+
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, AccessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Subject:   "1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}).SignedString([]byte("another key"))
+	assert.NoError(t, err)
+	_, err = ParseAccessToken(forged)
+	assert.ErrorIs(t, err, ErrInvalidToken, "signed with another key")
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, AccessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Issuer: tokenIssuer, Subject: "1"},
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	assert.NoError(t, err)
+	_, err = ParseAccessToken(unsigned)
+	assert.ErrorIs(t, err, ErrInvalidToken, "alg none")
+
+	for _, bad := range []string{"", "abc", "a.b.c"} {
+		_, err = ParseAccessToken(bad)
+		assert.ErrorIs(t, err, ErrInvalidToken, bad)
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	token, hash, err := NewRefreshToken()
+	assert.NoError(t, err)
+	assert.Equal(t, hash, HashToken(token))
+
+	other, _, err := NewRefreshToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse battery")
+	assert.NoError(t, err)
+
+	ok, err := CheckPassword(hash, "correct horse battery")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = CheckPassword(hash, "wrong horse battery")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}